@@ -2,7 +2,9 @@ package common
 
 import (
 	"fmt"
+	"math/rand"
 	"os"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -12,19 +14,6 @@ func CheckPassword(password, hash string) bool {
 	return err == nil
 }
 
-func PublicObjectURL(objectName string) string {
-	scheme := "http"
-	if os.Getenv("MINIO_USE_SSL") == "true" {
-		scheme = "https"
-	}
-	return fmt.Sprintf("%s://%s/%s/%s",
-		scheme,
-		os.Getenv("MINIO_ENDPOINT"),
-		os.Getenv("MINIO_BUCKET"),
-		objectName,
-	)
-}
-
 func GetJWTSecret() []byte {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
@@ -32,3 +21,11 @@ func GetJWTSecret() []byte {
 	}
 	return []byte(secret)
 }
+
+// GenerateOrderNumber gera o número do pedido (loja ou orçamento convertido).
+func GenerateOrderNumber() string {
+	// Formato: ORD + timestamp + 3 dígitos aleatórios
+	timestamp := time.Now().Format("20060102150405")
+	randomNum := rand.Intn(900) + 100 // 100-999
+	return fmt.Sprintf("ORD%s%d", timestamp, randomNum)
+}
@@ -2,9 +2,13 @@ package config
 
 import (
 	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/catalog"
+	"backend_camisaria_store/service/search"
 	whatsapp "backend_camisaria_store/service/whatsapp/config"
 	"fmt"
 	"log"
+	"os"
+	"strconv"
 
 	"gorm.io/gorm"
 )
@@ -22,10 +26,40 @@ func Init() error {
 		return fmt.Errorf("error initialize mysql %v", err)
 	}
 
-	// Inicializar MinIO
-	err = InitMinio()
+	// Categorias raiz padrão
+	err = InitDefaultCategories()
 	if err != nil {
-		return fmt.Errorf("error initialize minio %v", err)
+		return fmt.Errorf("error initialize categories %v", err)
+	}
+
+	// Slugs de produtos antigos
+	err = catalog.BackfillProductSlugs(DB)
+	if err != nil {
+		return fmt.Errorf("error backfill product slugs %v", err)
+	}
+
+	// Preço vigente dos produtos ainda sem histórico de preços
+	err = catalog.BackfillPriceHistory(DB)
+	if err != nil {
+		return fmt.Errorf("error backfill price history %v", err)
+	}
+
+	// Galeria (product_images) dos produtos cadastrados só com a lista de URLs
+	err = catalog.BackfillProductImages(DB)
+	if err != nil {
+		return fmt.Errorf("error backfill product images %v", err)
+	}
+
+	// Indexar para busca produtos ainda sem search_text
+	err = search.ReindexProducts(DB)
+	if err != nil {
+		return fmt.Errorf("error reindex products %v", err)
+	}
+
+	// Inicializar armazenamento de objetos (MinIO, disco local ou memória)
+	err = InitStorage()
+	if err != nil {
+		return fmt.Errorf("error initialize storage %v", err)
 	}
 
 	// Inicializar instância default
@@ -37,6 +71,15 @@ func Init() error {
 	return nil
 }
 
+// StoreID identifica a loja nos cadastros de clientes criados pelo backend (STORE_ID, padrão 1).
+func StoreID() uint64 {
+	id, err := strconv.ParseUint(os.Getenv("STORE_ID"), 10, 64)
+	if err != nil || id == 0 {
+		return 1
+	}
+	return id
+}
+
 // InitInstanceDbDefault cria a instância "default" somente quando a tabela instances está vazia.
 func InitInstanceDbDefault() error {
 	var count int64
@@ -61,3 +104,36 @@ func InitInstanceDbDefault() error {
 	log.Println("instância WhatsApp padrão criada (name=default)")
 	return nil
 }
+
+// InitDefaultCategories cria as categorias raiz (masculino, feminino, fardamentos) quando a tabela
+// categories está vazia e associa os produtos existentes pelo slug gravado em categorys.
+func InitDefaultCategories() error {
+	var count int64
+	if err := DB.Model(&schemas.Categories{}).Count(&count).Error; err != nil {
+		return fmt.Errorf("error count categories: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	defaults := []schemas.Categories{
+		{Name: "Masculino", Slug: string(schemas.Masculino), Gender: "M", Position: 1, IsActive: true},
+		{Name: "Feminino", Slug: string(schemas.Feminino), Gender: "F", Position: 2, IsActive: true},
+		{Name: "Fardamentos", Slug: string(schemas.Fardamentos), Gender: "U", Position: 3, IsActive: true},
+	}
+	if err := DB.Create(&defaults).Error; err != nil {
+		return fmt.Errorf("error create default categories: %w", err)
+	}
+
+	if err := DB.Exec(`
+		UPDATE products p
+		JOIN categories c ON c.slug = LOWER(TRIM(p.categorys))
+		SET p.category_id = c.id
+		WHERE p.category_id IS NULL
+	`).Error; err != nil {
+		return fmt.Errorf("error link products to categories: %w", err)
+	}
+
+	log.Println("categorias padrão criadas (masculino, feminino, fardamentos)")
+	return nil
+}
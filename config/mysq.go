@@ -68,6 +68,27 @@ func InitializeMysql() (*gorm.DB, error) {
 		&schemas.OrderItems{},
 		&schemas.Address{},
 		&schemas.Instance{},
+		&schemas.Categories{},
+		&schemas.Collections{},
+		&schemas.CollectionProducts{},
+		&schemas.ProductSlugHistory{},
+		&schemas.ProductRevisions{},
+		&schemas.BundleItems{},
+		&schemas.Quotes{},
+		&schemas.QuoteItems{},
+		&schemas.QuoteArtworks{},
+		&schemas.SizeCharts{},
+		&schemas.ProductReviews{},
+		&schemas.WishlistItems{},
+		&schemas.StockSubscriptions{},
+		&schemas.ProductRelations{},
+		&schemas.ProductPriceHistory{},
+		&schemas.ProductImages{},
+		&schemas.PendingUploads{},
+		&schemas.StoredObjects{},
+		&schemas.RefreshTokens{},
+		&schemas.PasswordResets{},
+		&schemas.UserVerifications{},
 	); err != nil {
 		return nil, err
 	}
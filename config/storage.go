@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"backend_camisaria_store/service/storage"
+)
+
+var (
+	Storage storage.Storage
+)
+
+// InitStorage configura o armazenamento de objetos pelo STORAGE_DRIVER:
+// minio (padrão), local (LOCAL_STORAGE_PATH, servido em /media) ou memory.
+// MEDIA_PUBLIC_URL troca a base das URLs públicas (CDN).
+func InitStorage() error {
+	switch driver := strings.ToLower(strings.TrimSpace(os.Getenv("STORAGE_DRIVER"))); driver {
+	case "", "minio":
+		if err := InitMinio(); err != nil {
+			return err
+		}
+		scheme := "http"
+		if os.Getenv("MINIO_USE_SSL") == "true" {
+			scheme = "https"
+		}
+		publicBase := fmt.Sprintf("%s://%s/%s", scheme, os.Getenv("MINIO_ENDPOINT"), BunkedName)
+		Storage = storage.NewMinio(MinioClient, BunkedName, publicBase)
+	case "local":
+		root := os.Getenv("LOCAL_STORAGE_PATH")
+		if root == "" {
+			root = "./storage"
+		}
+		baseURL := os.Getenv("LOCAL_STORAGE_URL")
+		if baseURL == "" {
+			baseURL = "http://localhost:4041/media"
+		}
+		local, err := storage.NewLocal(root, baseURL)
+		if err != nil {
+			return err
+		}
+		Storage = local
+	case "memory":
+		Storage = storage.NewMemory("")
+	default:
+		return fmt.Errorf("STORAGE_DRIVER inválido: %s (use minio, local ou memory)", driver)
+	}
+
+	// URL pública das mídias: CDN ou a rota /public/media da própria API
+	if base := strings.TrimSpace(os.Getenv("MEDIA_PUBLIC_URL")); base != "" {
+		Storage = storage.WithPublicBase(Storage, base)
+	}
+	return nil
+}
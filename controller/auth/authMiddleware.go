@@ -4,6 +4,7 @@ import (
 	"backend_camisaria_store/common"
 	"backend_camisaria_store/config"
 	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/session"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -122,12 +123,42 @@ func AuthMiddleware(c *fiber.Ctx) error {
 		})
 	}
 
+	// Tokens emitidos antes de um logout geral / troca de senha não valem mais
+	issuedAt, _ := claims["iat"].(float64)
+	if session.IssuedBeforeRevocation(&dbUser, int64(issuedAt)) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "Sessão encerrada",
+			"message": "Faça login novamente",
+		})
+	}
+
+	// Access tokens do login encerrado (logout ou reuso do refresh token) não valem mais
+	sid, _ := claims["sid"].(string)
+	if sid != "" {
+		revoked, err := session.FamilyRevoked(config.DB, sid)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Erro interno do servidor",
+				"message": "Erro ao validar sessão",
+			})
+		}
+		if revoked {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error":   "Sessão encerrada",
+				"message": "Faça login novamente",
+			})
+		}
+	}
+
 	// Usar dados atualizados do banco (não do token)
 	c.Locals("user_id", dbUser.ID)
 	c.Locals("user_type", string(dbUser.Role))
 	c.Locals("user_role", string(dbUser.Role))
 	c.Locals("user_name", dbUser.Name)
 	c.Locals("user_email", dbUser.Email)
+	if sid != "" {
+		c.Locals("session_id", sid)
+	}
 
 	// Usuário autenticado com sucesso
 
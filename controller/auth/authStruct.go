@@ -1,7 +1,10 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -29,3 +32,80 @@ func (req *LoginStruct) Validate() error {
 
 	return nil // Retorna nil se todas as validações passarem
 }
+
+// RefreshRequest troca o refresh token por um novo par de tokens.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// LogoutRequest encerra o login do refresh token (ou o da sessão atual, sem ele);
+// all_devices encerra todas as sessões da conta.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+	AllDevices   bool   `json:"all_devices,omitempty"`
+}
+
+// ForgotPasswordRequest pede o código de redefinição de senha.
+type ForgotPasswordRequest struct {
+	Email   string `json:"email"`
+	Channel string `json:"channel,omitempty"` // email (padrão) ou whatsapp
+}
+
+// ResetPasswordRequest troca a senha usando o código recebido.
+type ResetPasswordRequest struct {
+	Email       string `json:"email"`
+	Code        string `json:"code"`
+	NewPassword string `json:"new_password"`
+}
+
+var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+
+func (req *ForgotPasswordRequest) Validate() error {
+	var errs []string
+	req.Email = strings.TrimSpace(req.Email)
+	req.Channel = strings.ToLower(strings.TrimSpace(req.Channel))
+
+	if !emailRegex.MatchString(req.Email) {
+		errs = append(errs, "e-mail deve ter um formato válido")
+	}
+	if req.Channel != "" && req.Channel != resetChannelEmail && req.Channel != resetChannelWhatsApp {
+		errs = append(errs, "channel deve ser 'email' ou 'whatsapp'")
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (req *ResetPasswordRequest) Validate() error {
+	var errs []string
+	req.Email = strings.TrimSpace(req.Email)
+	req.Code = strings.TrimSpace(req.Code)
+
+	if !emailRegex.MatchString(req.Email) {
+		errs = append(errs, "e-mail deve ter um formato válido")
+	}
+	if len(req.Code) != resetCodeDigits {
+		errs = append(errs, fmt.Sprintf("código deve ter %d dígitos", resetCodeDigits))
+	}
+	// Mesmo limite do login (LoginStruct)
+	if len(req.NewPassword) < 6 || len(req.NewPassword) > 20 {
+		errs = append(errs, "nova senha deve ter entre 6 e 20 caracteres")
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// VerifyEmailRequest confirma o e-mail com o token do link.
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// VerifyPhoneRequest confirma o telefone com o código recebido por WhatsApp.
+type VerifyPhoneRequest struct {
+	Code string `json:"code"`
+}
@@ -4,10 +4,9 @@ import (
 	"backend_camisaria_store/common"
 	"backend_camisaria_store/config"
 	"backend_camisaria_store/schemas"
-	"time"
+	"backend_camisaria_store/service/session"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 func LoginUser(c *fiber.Ctx) error {
@@ -44,23 +43,13 @@ func LoginUser(c *fiber.Ctx) error {
 		})
 	}
 
-	// Role-based claims - admin, user, or client
-
-	claims := jwt.MapClaims{
-		"user_id":   user.ID,
-		"user_type": string(user.Role), // "admin", "user", or "client"
-		"name":      user.Name,
-		"email":     user.Email,
-		"role":      string(user.Role),
-		"exp":       time.Now().Add(time.Hour * 72).Unix(),
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	t, err := token.SignedString(common.GetJWTSecret())
+	// Access token curto (claims por role: admin, user ou client) + refresh token rotativo
+	tokens, err := session.Start(db, &user, clientInfo(c))
 	if err != nil {
-		return c.SendStatus(fiber.StatusInternalServerError)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao iniciar sessão",
+		})
 	}
 
-	return c.JSON(fiber.Map{"access_token": t})
+	return c.JSON(tokens)
 }
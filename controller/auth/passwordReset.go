@@ -0,0 +1,264 @@
+package controller
+
+import (
+	"backend_camisaria_store/common"
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/notify"
+	"backend_camisaria_store/service/session"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	resetChannelEmail    = "email"
+	resetChannelWhatsApp = "whatsapp"
+
+	resetCodeDigits      = 6
+	resetCodeTTL         = 15 * time.Minute
+	resetMaxAttempts     = 5 // tentativas erradas por código
+	resetPerAccountLimit = 3 // códigos por conta por hora
+	resetPerIPLimit      = 10
+	resetCooldown        = time.Minute // intervalo mínimo entre códigos da mesma conta
+)
+
+// Resposta igual para e-mail com ou sem conta, para não revelar quem é cliente.
+const forgotPasswordMessage = "Se houver uma conta com este e-mail, enviaremos um código para redefinir a senha"
+
+// ForgotPassword — POST /public/password/forgot — gera um código de uso único e envia por
+// e-mail ou WhatsApp (Users.Contact). Um código novo invalida os anteriores.
+func ForgotPassword(c *fiber.Ctx) error {
+	var req ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	now := time.Now()
+	ip := c.IP()
+	var fromIP int64
+	if err := config.DB.Model(&schemas.PasswordResets{}).
+		Where("ip = ? AND created_at > ?", ip, now.Add(-time.Hour)).Count(&fromIP).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao processar solicitação"})
+	}
+	if fromIP >= resetPerIPLimit {
+		c.Set(fiber.HeaderRetryAfter, "3600")
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error":   "Muitas solicitações",
+			"message": "Tente novamente mais tarde",
+		})
+	}
+
+	accepted := func() error {
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": forgotPasswordMessage})
+	}
+
+	var user schemas.Users
+	err := config.DB.Where("email = ?", req.Email).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Sem conta: registra só para o limite por IP
+		config.DB.Create(&schemas.PasswordResets{IP: ip, ExpiresAt: now})
+		return accepted()
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao processar solicitação"})
+	}
+
+	// Limite por conta: excedido, responde igual mas não envia nada
+	var recent []schemas.PasswordResets
+	if err := config.DB.Select("id", "created_at").
+		Where("user_id = ? AND created_at > ?", user.ID, now.Add(-time.Hour)).
+		Order("created_at DESC").Find(&recent).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao processar solicitação"})
+	}
+	if len(recent) >= resetPerAccountLimit || (len(recent) > 0 && now.Sub(recent[0].CreatedAt) < resetCooldown) {
+		return accepted()
+	}
+
+	channel := req.Channel
+	if channel == resetChannelWhatsApp && notify.NormalizePhone(user.Contact) == "" {
+		channel = resetChannelEmail
+	}
+	if channel == "" {
+		channel = resetChannelEmail
+	}
+
+	code, err := randomCode()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao gerar código"})
+	}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&schemas.PasswordResets{}).
+			Where("user_id = ? AND used_at IS NULL", user.ID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&schemas.PasswordResets{
+			UserID:    &user.ID,
+			Channel:   channel,
+			CodeHash:  hashUserCode(user.ID, code),
+			IP:        ip,
+			ExpiresAt: now.Add(resetCodeTTL),
+		}).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao processar solicitação",
+			"details": err.Error(),
+		})
+	}
+
+	// Envio em background: o tempo de resposta não denuncia se a conta existe
+	go deliverResetCode(user, channel, code)
+
+	return accepted()
+}
+
+// ResetPassword — POST /public/password/reset — confere o código, troca a senha e encerra
+// todas as sessões da conta (refresh tokens e access tokens já emitidos).
+func ResetPassword(c *fiber.Ctx) error {
+	var req ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	invalid := fiber.Map{"error": "Código inválido ou expirado"}
+	now := time.Now()
+
+	var user schemas.Users
+	if err := config.DB.Where("email = ?", req.Email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusBadRequest).JSON(invalid)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao buscar usuário"})
+	}
+
+	var reset schemas.PasswordResets
+	err := config.DB.Where("user_id = ? AND used_at IS NULL AND expires_at > ?", user.ID, now).
+		Order("id DESC").First(&reset).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusBadRequest).JSON(invalid)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao validar código"})
+	}
+
+	// Conta a tentativa antes de comparar; esgotadas, o código deixa de valer
+	result := config.DB.Model(&schemas.PasswordResets{}).
+		Where("id = ? AND attempts < ?", reset.ID, resetMaxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao validar código"})
+	}
+	if result.RowsAffected == 0 {
+		config.DB.Model(&schemas.PasswordResets{}).Where("id = ?", reset.ID).Update("used_at", now)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Código bloqueado por excesso de tentativas; solicite um novo",
+		})
+	}
+	if !hmac.Equal([]byte(hashUserCode(user.ID, req.Code)), []byte(reset.CodeHash)) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":         "Código inválido ou expirado",
+			"attempts_left": resetMaxAttempts - reset.Attempts - 1,
+		})
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao processar senha"})
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		used := tx.Model(&schemas.PasswordResets{}).
+			Where("id = ? AND used_at IS NULL", reset.ID).Update("used_at", now)
+		if used.Error != nil {
+			return used.Error
+		}
+		if used.RowsAffected == 0 {
+			return errResetCodeUsed
+		}
+		if err := tx.Model(&schemas.Users{}).Where("id = ?", user.ID).
+			Update("password", string(hashedPassword)).Error; err != nil {
+			return err
+		}
+		return session.RevokeUser(tx, user.ID, now)
+	})
+	if errors.Is(err, errResetCodeUsed) {
+		return c.Status(fiber.StatusBadRequest).JSON(invalid)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao redefinir senha",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Senha redefinida. Faça login novamente",
+	})
+}
+
+var errResetCodeUsed = errors.New("código já utilizado")
+
+func deliverResetCode(user schemas.Users, channel, code string) {
+	text := fmt.Sprintf("Olá, %s!\n\nSeu código para redefinir a senha é %s. Ele vale por %d minutos e só pode ser usado uma vez.\n\nSe você não pediu a redefinição, ignore esta mensagem.",
+		user.Name, code, int(resetCodeTTL/time.Minute))
+
+	var err error
+	if channel == resetChannelWhatsApp {
+		err = notify.SendWhatsApp(config.DB, user.Contact, text)
+	} else {
+		err = notify.SendEmail(user.Email, "Código para redefinir sua senha", text)
+	}
+	if err != nil {
+		log.Printf("redefinição de senha: falha ao enviar código (usuário %d, %s): %v", user.ID, channel, err)
+	}
+}
+
+func randomCode() (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < resetCodeDigits; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", resetCodeDigits, n), nil
+}
+
+// hashUserCode amarra o código ao usuário: o mesmo código de outra conta não confere.
+func hashUserCode(userID uint64, code string) string {
+	mac := hmac.New(sha256.New, common.GetJWTSecret())
+	mac.Write([]byte(strconv.FormatUint(userID, 10) + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/service/session"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RefreshSession — POST /public/refresh — troca o refresh token por um novo par. Cada
+// refresh token vale uma vez; reapresentar um já usado encerra o login inteiro.
+func RefreshSession(c *fiber.Ctx) error {
+	var req RefreshRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+	req.RefreshToken = strings.TrimSpace(req.RefreshToken)
+	if req.RefreshToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "refresh_token é obrigatório"})
+	}
+
+	tokens, _, err := session.Rotate(config.DB, req.RefreshToken, clientInfo(c))
+	if errors.Is(err, session.ErrInvalidRefresh) || errors.Is(err, session.ErrRefreshReused) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   "Sessão inválida ou expirada",
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao renovar sessão",
+			"details": err.Error(),
+		})
+	}
+	return c.JSON(tokens)
+}
+
+// Logout — POST /api/logout — revoga o refresh token informado (ou o login do access token
+// atual) e, com all_devices, todas as sessões da conta. O AuthMiddleware recusa os access
+// tokens cujo sid pertence a uma sessão revogada, então eles deixam de valer na hora.
+func Logout(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint64)
+
+	var req LogoutRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Dados inválidos",
+				"details": err.Error(),
+			})
+		}
+	}
+	req.RefreshToken = strings.TrimSpace(req.RefreshToken)
+
+	var err error
+	switch {
+	case req.AllDevices:
+		err = session.RevokeUser(config.DB, userID, time.Now())
+	case req.RefreshToken != "":
+		err = session.RevokeRefreshToken(config.DB, userID, req.RefreshToken)
+	default:
+		sid, _ := c.Locals("session_id").(string)
+		if sid == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Informe o refresh_token da sessão a encerrar",
+			})
+		}
+		err = session.RevokeFamily(config.DB, sid, time.Now())
+	}
+	if errors.Is(err, session.ErrInvalidRefresh) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Refresh token inválido"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao encerrar sessão",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":     "Sessão encerrada",
+		"all_devices": req.AllDevices,
+	})
+}
+
+func clientInfo(c *fiber.Ctx) session.ClientInfo {
+	return session.ClientInfo{UserAgent: c.Get(fiber.HeaderUserAgent), IP: c.IP()}
+}
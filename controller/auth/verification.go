@@ -0,0 +1,350 @@
+package controller
+
+import (
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/notify"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	emailVerificationTTL = 24 * time.Hour
+	phoneVerificationTTL = 10 * time.Minute
+	verifyMaxAttempts    = 5 // tentativas erradas por código de telefone
+	verifyPerHourLimit   = 5 // envios por canal por hora
+	verifyCooldown       = time.Minute
+
+	// Página do front que recebe o token e chama POST /public/verify-email
+	verifyEmailPath = "/verificar-email"
+)
+
+// GetMyVerification — GET /api/users/me/verification — situação da verificação e o que a
+// loja exige para fechar pedidos.
+func GetMyVerification(c *fiber.Ctx) error {
+	user, ferr := currentUser(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+	return c.JSON(fiber.Map{
+		"email":             user.Email,
+		"email_verified_at": user.EmailVerifiedAt,
+		"contact":           user.Contact,
+		"phone_verified_at": user.PhoneVerifiedAt,
+		"missing":           missingVerifications(&user), // pendências para fazer pedidos
+	})
+}
+
+// SendEmailVerification — POST /api/users/me/verification/email — envia o link de
+// verificação (STORE_PUBLIC_URL + /verificar-email?token=...).
+func SendEmailVerification(c *fiber.Ctx) error {
+	user, ferr := currentUser(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+	if user.EmailVerifiedAt != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "E-mail já verificado"})
+	}
+	base := strings.TrimSuffix(strings.TrimSpace(os.Getenv("STORE_PUBLIC_URL")), "/")
+	if base == "" {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "STORE_PUBLIC_URL não configurada",
+		})
+	}
+	if ferr := checkVerificationLimit(user.ID, schemas.VerifyEmail); ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao gerar link"})
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	if err := storeVerification(user.ID, schemas.VerifyEmail, user.Email, hashToken(token), emailVerificationTTL); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao registrar verificação",
+			"details": err.Error(),
+		})
+	}
+
+	link := base + verifyEmailPath + "?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Olá, %s!\n\nConfirme seu e-mail pelo link abaixo (válido por %d horas):\n\n%s\n\nSe você não criou uma conta na loja, ignore esta mensagem.",
+		user.Name, int(emailVerificationTTL/time.Hour), link)
+	if err := notify.SendEmail(user.Email, "Confirme seu e-mail", body); err != nil {
+		return deliveryError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Link de verificação enviado para " + user.Email})
+}
+
+// VerifyEmail — POST /public/verify-email — confirma o e-mail pelo token do link. Não exige
+// login: o link pode ser aberto em outro dispositivo.
+func VerifyEmail(c *fiber.Ctx) error {
+	var req VerifyEmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+	req.Token = strings.TrimSpace(req.Token)
+	if req.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "token é obrigatório"})
+	}
+
+	invalid := fiber.Map{"error": "Link inválido ou expirado"}
+	now := time.Now()
+
+	var v schemas.UserVerifications
+	err := config.DB.Where("token_hash = ? AND channel = ? AND used_at IS NULL AND expires_at > ?",
+		hashToken(req.Token), schemas.VerifyEmail, now).First(&v).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusBadRequest).JSON(invalid)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao validar link"})
+	}
+
+	var user schemas.Users
+	if err := config.DB.First(&user, v.UserID).Error; err != nil || user.Email != v.Target {
+		// Conta removida ou e-mail alterado depois do envio
+		return c.Status(fiber.StatusBadRequest).JSON(invalid)
+	}
+
+	if err := confirmVerification(&v, "email_verified_at", now); err != nil {
+		if errors.Is(err, errVerificationUsed) {
+			return c.Status(fiber.StatusBadRequest).JSON(invalid)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao verificar e-mail",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":           "E-mail verificado",
+		"email":             user.Email,
+		"email_verified_at": now,
+	})
+}
+
+// SendPhoneVerification — POST /api/users/me/verification/phone — envia por WhatsApp um
+// código de 6 dígitos para o telefone do cadastro (Users.Contact).
+func SendPhoneVerification(c *fiber.Ctx) error {
+	user, ferr := currentUser(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+	phone := notify.NormalizePhone(user.Contact)
+	if phone == "" {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": "Cadastre um telefone válido para verificar por WhatsApp",
+		})
+	}
+	if user.PhoneVerifiedAt != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Telefone já verificado"})
+	}
+	if ferr := checkVerificationLimit(user.ID, schemas.VerifyPhone); ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	code, err := randomCode()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao gerar código"})
+	}
+	if err := storeVerification(user.ID, schemas.VerifyPhone, phone, hashUserCode(user.ID, code), phoneVerificationTTL); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao registrar verificação",
+			"details": err.Error(),
+		})
+	}
+
+	text := fmt.Sprintf("Seu código de verificação da loja é %s. Ele vale por %d minutos.",
+		code, int(phoneVerificationTTL/time.Minute))
+	if err := notify.SendWhatsApp(config.DB, user.Contact, text); err != nil {
+		return deliveryError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Código enviado por WhatsApp"})
+}
+
+// ConfirmPhoneVerification — POST /api/users/me/verification/phone/confirm — confere o
+// código; após verifyMaxAttempts erros é preciso pedir outro.
+func ConfirmPhoneVerification(c *fiber.Ctx) error {
+	var req VerifyPhoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+	req.Code = strings.TrimSpace(req.Code)
+	if len(req.Code) != resetCodeDigits {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("código deve ter %d dígitos", resetCodeDigits),
+		})
+	}
+
+	user, ferr := currentUser(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	invalid := fiber.Map{"error": "Código inválido ou expirado"}
+	now := time.Now()
+
+	var v schemas.UserVerifications
+	err := config.DB.Where("user_id = ? AND channel = ? AND used_at IS NULL AND expires_at > ?",
+		user.ID, schemas.VerifyPhone, now).Order("id DESC").First(&v).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && v.Target != notify.NormalizePhone(user.Contact)) {
+		return c.Status(fiber.StatusBadRequest).JSON(invalid)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao validar código"})
+	}
+
+	result := config.DB.Model(&schemas.UserVerifications{}).
+		Where("id = ? AND attempts < ?", v.ID, verifyMaxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao validar código"})
+	}
+	if result.RowsAffected == 0 {
+		config.DB.Model(&schemas.UserVerifications{}).Where("id = ?", v.ID).Update("used_at", now)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Código bloqueado por excesso de tentativas; solicite um novo",
+		})
+	}
+	if !hmac.Equal([]byte(hashUserCode(user.ID, req.Code)), []byte(v.TokenHash)) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":         "Código inválido ou expirado",
+			"attempts_left": verifyMaxAttempts - v.Attempts - 1,
+		})
+	}
+
+	if err := confirmVerification(&v, "phone_verified_at", now); err != nil {
+		if errors.Is(err, errVerificationUsed) {
+			return c.Status(fiber.StatusBadRequest).JSON(invalid)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao verificar telefone",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":           "Telefone verificado",
+		"phone_verified_at": now,
+	})
+}
+
+var errVerificationUsed = errors.New("verificação já utilizada")
+
+// missingVerifications lista o que falta entre o exigido por REQUIRE_EMAIL_VERIFICATION e
+// REQUIRE_PHONE_VERIFICATION. Só vale para clientes da loja.
+func missingVerifications(user *schemas.Users) []string {
+	missing := []string{}
+	if user.Role != schemas.RoleClient {
+		return missing
+	}
+	if envFlag("REQUIRE_EMAIL_VERIFICATION") && user.EmailVerifiedAt == nil {
+		missing = append(missing, schemas.VerifyEmail)
+	}
+	if envFlag("REQUIRE_PHONE_VERIFICATION") && user.PhoneVerifiedAt == nil {
+		missing = append(missing, schemas.VerifyPhone)
+	}
+	return missing
+}
+
+// checkVerificationLimit limita os envios por canal: um por minuto e verifyPerHourLimit por hora.
+func checkVerificationLimit(userID uint64, channel string) *fiber.Error {
+	var recent []schemas.UserVerifications
+	if err := config.DB.Select("id", "created_at").
+		Where("user_id = ? AND channel = ? AND created_at > ?", userID, channel, time.Now().Add(-time.Hour)).
+		Order("created_at DESC").Find(&recent).Error; err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Erro ao verificar envios anteriores")
+	}
+	if len(recent) >= verifyPerHourLimit || (len(recent) > 0 && time.Since(recent[0].CreatedAt) < verifyCooldown) {
+		return fiber.NewError(fiber.StatusTooManyRequests, "Aguarde antes de pedir um novo envio")
+	}
+	return nil
+}
+
+// storeVerification registra o envio e invalida os anteriores do mesmo canal.
+func storeVerification(userID uint64, channel, target, tokenHash string, ttl time.Duration) error {
+	now := time.Now()
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&schemas.UserVerifications{}).
+			Where("user_id = ? AND channel = ? AND used_at IS NULL", userID, channel).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&schemas.UserVerifications{
+			UserID:    userID,
+			Channel:   channel,
+			Target:    target,
+			TokenHash: tokenHash,
+			ExpiresAt: now.Add(ttl),
+		}).Error
+	})
+}
+
+// confirmVerification marca o uso (uma vez só) e grava a data no campo do usuário.
+func confirmVerification(v *schemas.UserVerifications, column string, now time.Time) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		used := tx.Model(&schemas.UserVerifications{}).
+			Where("id = ? AND used_at IS NULL", v.ID).Update("used_at", now)
+		if used.Error != nil {
+			return used.Error
+		}
+		if used.RowsAffected == 0 {
+			return errVerificationUsed
+		}
+		return tx.Model(&schemas.Users{}).Where("id = ?", v.UserID).Update(column, now).Error
+	})
+}
+
+func currentUser(c *fiber.Ctx) (schemas.Users, *fiber.Error) {
+	var user schemas.Users
+	if err := config.DB.First(&user, c.Locals("user_id").(uint64)).Error; err != nil {
+		return user, fiber.NewError(fiber.StatusNotFound, "Usuário não encontrado")
+	}
+	return user, nil
+}
+
+func deliveryError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, notify.ErrNotConfigured) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Canal de envio não configurado",
+		})
+	}
+	return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+		"error":   "Erro ao enviar verificação",
+		"details": err.Error(),
+	})
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func envFlag(key string) bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(key))) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// VerifiedMiddleware barra clientes sem as verificações exigidas (REQUIRE_EMAIL_VERIFICATION,
+// REQUIRE_PHONE_VERIFICATION). Usar depois do AuthMiddleware, nas rotas de fechar pedido.
+func VerifiedMiddleware(c *fiber.Ctx) error {
+	if c.Locals("user_type") != "client" {
+		return c.Next()
+	}
+
+	user, ferr := currentUser(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	if missing := missingVerifications(&user); len(missing) > 0 {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":   "Verificação pendente",
+			"message": "Confirme seus dados de contato antes de fazer pedidos",
+			"missing": missing,
+		})
+	}
+
+	return c.Next()
+}
@@ -0,0 +1,380 @@
+package controller
+
+import (
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/catalog"
+	"backend_camisaria_store/service/minio"
+	"backend_camisaria_store/service/search"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// ListCategoryTree — loja pública: árvore de categorias ativas.
+func ListCategoryTree(c *fiber.Ctx) error {
+	categories, err := catalog.LoadCategories(config.DB, true)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar categorias",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"categories": buildCategoryTree(categories),
+	})
+}
+
+// ListCategories — admin: árvore completa, incluindo categorias inativas.
+func ListCategories(c *fiber.Ctx) error {
+	categories, err := catalog.LoadCategories(config.DB, false)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao buscar categorias",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"categories": buildCategoryTree(categories),
+	})
+}
+
+func CreateCategory(c *fiber.Ctx) error {
+	req := CreateCategoryRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Erro ao processar dados da requisição",
+			"details": err.Error(),
+		})
+	}
+
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	if req.ParentID != nil {
+		var parent schemas.Categories
+		if err := config.DB.First(&parent, *req.ParentID).Error; err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Categoria pai não encontrada",
+			})
+		}
+	}
+
+	slug := catalog.Slugify(req.Slug)
+	if slug == "" {
+		slug = catalog.Slugify(req.Name)
+	}
+	if slug == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Não foi possível gerar um slug válido para a categoria",
+		})
+	}
+	if taken, err := categorySlugTaken(slug, 0); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao verificar slug",
+		})
+	} else if taken {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Já existe uma categoria com este slug",
+			"slug":  slug,
+		})
+	}
+
+	category := schemas.Categories{
+		ParentID:    req.ParentID,
+		Name:        strings.TrimSpace(req.Name),
+		Slug:        slug,
+		Description: req.Description,
+		Gender:      req.Gender,
+		Position:    req.Position,
+		ImageURL:    req.ImageURL,
+		IsActive:    true,
+	}
+	if err := config.DB.Create(&category).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao salvar categoria",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":  "Categoria criada com sucesso",
+		"category": toCategoryResponse(category),
+	})
+}
+
+func UpdateCategory(c *fiber.Ctx) error {
+	categoryID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+
+	req := UpdateCategoryRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Erro ao processar dados da requisição",
+			"details": err.Error(),
+		})
+	}
+
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	var category schemas.Categories
+	if err := config.DB.First(&category, categoryID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Categoria não encontrada"})
+	}
+
+	updates := make(map[string]interface{})
+
+	if req.ParentID != nil {
+		categories, err := catalog.LoadCategories(config.DB, false)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro ao buscar categorias",
+			})
+		}
+		// Não permite mover a categoria para dentro dela mesma ou de uma descendente
+		if catalog.IsDescendant(categories, category.ID, *req.ParentID) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "A categoria pai não pode ser a própria categoria ou uma subcategoria dela",
+			})
+		}
+		if _, err := catalog.FindCategory(config.DB, strconv.FormatUint(*req.ParentID, 10)); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Categoria pai não encontrada",
+			})
+		}
+		updates["parent_id"] = *req.ParentID
+	}
+	if req.MakeRoot {
+		updates["parent_id"] = nil
+	}
+	if req.Name != nil {
+		updates["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.Slug != nil {
+		slug := catalog.Slugify(*req.Slug)
+		if slug == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Slug inválido",
+			})
+		}
+		if taken, err := categorySlugTaken(slug, category.ID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro ao verificar slug",
+			})
+		} else if taken {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Já existe uma categoria com este slug",
+				"slug":  slug,
+			})
+		}
+		updates["slug"] = slug
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Gender != nil {
+		updates["gender"] = *req.Gender
+	}
+	if req.Position != nil {
+		updates["position"] = *req.Position
+	}
+	if req.ImageURL != nil {
+		updates["image_url"] = *req.ImageURL
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+
+	if len(updates) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Nenhum campo para atualizar",
+		})
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&category).Updates(updates).Error; err != nil {
+			return err
+		}
+		return syncProductsCategory(tx, category.ID)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao atualizar categoria",
+			"details": err.Error(),
+		})
+	}
+
+	if err := config.DB.First(&category, categoryID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar categoria atualizada",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":  "Categoria atualizada com sucesso",
+		"category": toCategoryResponse(category),
+	})
+}
+
+// DeleteCategory remove a categoria apenas se não tiver subcategorias nem produtos.
+func DeleteCategory(c *fiber.Ctx) error {
+	categoryID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+
+	var category schemas.Categories
+	if err := config.DB.First(&category, categoryID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Categoria não encontrada"})
+	}
+
+	var children, products int64
+	if err := config.DB.Model(&schemas.Categories{}).Where("parent_id = ?", category.ID).Count(&children).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao verificar subcategorias",
+		})
+	}
+	if err := config.DB.Unscoped().Model(&schemas.Products{}).Where("category_id = ?", category.ID).Count(&products).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao verificar produtos da categoria",
+		})
+	}
+	if children > 0 || products > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":         "Categoria em uso",
+			"message":       "Mova as subcategorias e produtos antes de excluir, ou desative a categoria",
+			"subcategories": children,
+			"products":      products,
+		})
+	}
+
+	if err := config.DB.Delete(&category).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao excluir categoria",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Categoria removida com sucesso",
+	})
+}
+
+// UploadCategoryImage envia a imagem da categoria (campo "image") para o MinIO.
+func UploadCategoryImage(c *fiber.Ctx) error {
+	categoryID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+
+	var category schemas.Categories
+	if err := config.DB.First(&category, categoryID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Categoria não encontrada"})
+	}
+
+	file, err := c.FormFile("image")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Nenhum arquivo enviado",
+			"message": "Envie a imagem no campo 'image'",
+		})
+	}
+
+	if err := minio.ValidateImageFile(file); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":  err.Error(),
+			"reason": minio.RejectionReason(err),
+		})
+	}
+
+	_, key, err := minio.UploadProductImage(file, category.Slug, "categories", category.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao enviar imagem",
+			"details": err.Error(),
+		})
+	}
+
+	// Guarda a key; a URL pública é montada na resposta (MediaURL)
+	if err := config.DB.Model(&category).Update("image_url", key).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao salvar imagem da categoria",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":   "Imagem da categoria atualizada",
+		"image_url": minio.MediaURL(key),
+	})
+}
+
+func categorySlugTaken(slug string, exceptID uint64) (bool, error) {
+	var count int64
+	err := config.DB.Model(&schemas.Categories{}).
+		Where("slug = ? AND id <> ?", slug, exceptID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// syncProductsCategory atualiza slug, gênero e search_text denormalizados nos produtos da subárvore
+// após mudança de slug, gênero ou posição da categoria na árvore.
+func syncProductsCategory(tx *gorm.DB, categoryID uint64) error {
+	categories, err := catalog.LoadCategories(tx, false)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[uint64]schemas.Categories, len(categories))
+	for _, cat := range categories {
+		byID[cat.ID] = cat
+	}
+
+	for _, id := range catalog.DescendantIDs(categories, categoryID) {
+		cat := byID[id]
+		gender, err := catalog.ResolveGender(tx, &cat)
+		if err != nil {
+			return err
+		}
+
+		// Produto a produto: o search_text indexa o slug da categoria e precisa acompanhar
+		var products []schemas.Products
+		if err := tx.Unscoped().Where("category_id = ?", id).Find(&products).Error; err != nil {
+			return err
+		}
+		for _, p := range products {
+			if string(p.Categorys) == cat.Slug && p.Gender == gender {
+				continue
+			}
+			before := p
+			p.Categorys = schemas.Category(cat.Slug)
+			p.Gender = gender
+			if err := tx.Unscoped().Model(&schemas.Products{}).Where("id = ?", p.ID).
+				Updates(map[string]interface{}{
+					"categorys":   p.Categorys,
+					"gender":      gender,
+					"search_text": search.ProductDocument(p),
+				}).Error; err != nil {
+				return err
+			}
+			if err := catalog.RecordProductRevision(tx, schemas.RevisionUpdate, &before, &p, catalog.SystemActor); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
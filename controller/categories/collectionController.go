@@ -0,0 +1,366 @@
+package controller
+
+import (
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/catalog"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// ListPublicCollections — loja pública: coleções ativas na ordem de exibição.
+func ListPublicCollections(c *fiber.Ctx) error {
+	var collections []schemas.Collections
+	if err := config.DB.Where("is_active = ?", true).
+		Order("position ASC, name ASC").
+		Find(&collections).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar coleções",
+		})
+	}
+
+	responses := make([]CollectionResponse, 0, len(collections))
+	for _, col := range collections {
+		responses = append(responses, toCollectionResponse(col))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"collections": responses,
+	})
+}
+
+// ListCollections — admin: todas as coleções, incluindo inativas.
+func ListCollections(c *fiber.Ctx) error {
+	var collections []schemas.Collections
+	if err := config.DB.Order("position ASC, name ASC").Find(&collections).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao buscar coleções",
+			"details": err.Error(),
+		})
+	}
+
+	responses := make([]CollectionResponse, 0, len(collections))
+	for _, col := range collections {
+		responses = append(responses, toCollectionResponse(col))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"collections": responses,
+	})
+}
+
+func GetCollection(c *fiber.Ctx) error {
+	collectionID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+
+	var collection schemas.Collections
+	if err := config.DB.First(&collection, collectionID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Coleção não encontrada"})
+	}
+
+	response := toCollectionResponse(collection)
+	if collection.Type == schemas.CollectionManual {
+		if err := config.DB.Model(&schemas.CollectionProducts{}).
+			Where("collection_id = ?", collection.ID).
+			Order("position ASC, id ASC").
+			Pluck("product_id", &response.ProductIDs).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro ao buscar produtos da coleção",
+			})
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"collection": response,
+	})
+}
+
+func CreateCollection(c *fiber.Ctx) error {
+	req := CreateCollectionRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Erro ao processar dados da requisição",
+			"details": err.Error(),
+		})
+	}
+
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	slug := catalog.Slugify(req.Slug)
+	if slug == "" {
+		slug = catalog.Slugify(req.Name)
+	}
+	if slug == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Não foi possível gerar um slug válido para a coleção",
+		})
+	}
+	if taken, err := collectionSlugTaken(slug, 0); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao verificar slug",
+		})
+	} else if taken {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Já existe uma coleção com este slug",
+			"slug":  slug,
+		})
+	}
+
+	collection := schemas.Collections{
+		Name:        strings.TrimSpace(req.Name),
+		Slug:        slug,
+		Description: req.Description,
+		Type:        req.Type,
+		Rules:       req.Rules,
+		Position:    req.Position,
+		ImageURL:    req.ImageURL,
+		IsActive:    true,
+	}
+	if err := config.DB.Create(&collection).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao salvar coleção",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":    "Coleção criada com sucesso",
+		"collection": toCollectionResponse(collection),
+	})
+}
+
+func UpdateCollection(c *fiber.Ctx) error {
+	collectionID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+
+	req := UpdateCollectionRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Erro ao processar dados da requisição",
+			"details": err.Error(),
+		})
+	}
+
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	var collection schemas.Collections
+	if err := config.DB.First(&collection, collectionID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Coleção não encontrada"})
+	}
+
+	updates := make(map[string]interface{})
+
+	if req.Name != nil {
+		updates["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.Slug != nil {
+		slug := catalog.Slugify(*req.Slug)
+		if slug == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Slug inválido",
+			})
+		}
+		if taken, err := collectionSlugTaken(slug, collection.ID); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro ao verificar slug",
+			})
+		} else if taken {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Já existe uma coleção com este slug",
+				"slug":  slug,
+			})
+		}
+		updates["slug"] = slug
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Type != nil {
+		updates["type"] = *req.Type
+	}
+	if req.Rules != nil {
+		updates["rules"] = *req.Rules
+	}
+	if req.Position != nil {
+		updates["position"] = *req.Position
+	}
+	if req.ImageURL != nil {
+		updates["image_url"] = *req.ImageURL
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+
+	if len(updates) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Nenhum campo para atualizar",
+		})
+	}
+
+	// Updates com map não passa pelo serializer; as regras são gravadas via struct
+	if rules, ok := updates["rules"]; ok {
+		delete(updates, "rules")
+		collection.Rules = rules.(schemas.CollectionRules)
+		if err := config.DB.Model(&collection).Select("rules").Updates(&collection).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Erro ao atualizar regras da coleção",
+				"details": err.Error(),
+			})
+		}
+	}
+
+	if len(updates) > 0 {
+		if err := config.DB.Model(&collection).Updates(updates).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Erro ao atualizar coleção",
+				"details": err.Error(),
+			})
+		}
+	}
+
+	if err := config.DB.First(&collection, collectionID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar coleção atualizada",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":    "Coleção atualizada com sucesso",
+		"collection": toCollectionResponse(collection),
+	})
+}
+
+func DeleteCollection(c *fiber.Ctx) error {
+	collectionID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+
+	var collection schemas.Collections
+	if err := config.DB.First(&collection, collectionID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Coleção não encontrada"})
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", collection.ID).Delete(&schemas.CollectionProducts{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&collection).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao excluir coleção",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Coleção removida com sucesso",
+	})
+}
+
+// SetCollectionProducts substitui os produtos de uma coleção manual, na ordem enviada.
+func SetCollectionProducts(c *fiber.Ctx) error {
+	collectionID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+
+	req := SetCollectionProductsRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Erro ao processar dados da requisição",
+			"details": err.Error(),
+		})
+	}
+
+	var collection schemas.Collections
+	if err := config.DB.First(&collection, collectionID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Coleção não encontrada"})
+	}
+
+	if collection.Type != schemas.CollectionManual {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Apenas coleções manuais aceitam lista de produtos",
+		})
+	}
+
+	// Remove duplicados mantendo a primeira posição
+	seen := make(map[uint64]bool, len(req.ProductIDs))
+	productIDs := make([]uint64, 0, len(req.ProductIDs))
+	for _, id := range req.ProductIDs {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		productIDs = append(productIDs, id)
+	}
+
+	if len(productIDs) > 0 {
+		var found int64
+		if err := config.DB.Model(&schemas.Products{}).Where("id IN ?", productIDs).Count(&found).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro ao verificar produtos",
+			})
+		}
+		if int(found) != len(productIDs) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Um ou mais produtos não foram encontrados",
+			})
+		}
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", collection.ID).Delete(&schemas.CollectionProducts{}).Error; err != nil {
+			return err
+		}
+		if len(productIDs) == 0 {
+			return nil
+		}
+		items := make([]schemas.CollectionProducts, 0, len(productIDs))
+		for i, id := range productIDs {
+			items = append(items, schemas.CollectionProducts{
+				CollectionID: collection.ID,
+				ProductID:    id,
+				Position:     i,
+			})
+		}
+		return tx.Create(&items).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao salvar produtos da coleção",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":     "Produtos da coleção atualizados",
+		"product_ids": productIDs,
+	})
+}
+
+func collectionSlugTaken(slug string, exceptID uint64) (bool, error) {
+	var count int64
+	err := config.DB.Model(&schemas.Collections{}).
+		Where("slug = ? AND id <> ?", slug, exceptID).
+		Count(&count).Error
+	return count > 0, err
+}
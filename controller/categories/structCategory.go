@@ -0,0 +1,278 @@
+package controller
+
+import (
+	"errors"
+	"strings"
+
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/minio"
+)
+
+type CreateCategoryRequest struct {
+	ParentID    *uint64 `json:"parent_id,omitempty"`
+	Name        string  `json:"name"`
+	Slug        string  `json:"slug"` // opcional: gerado a partir do nome
+	Description string  `json:"description"`
+	Gender      string  `json:"gender"`
+	Position    int     `json:"position"`
+	ImageURL    string  `json:"image_url"`
+}
+
+type UpdateCategoryRequest struct {
+	ParentID    *uint64 `json:"parent_id,omitempty"`
+	MakeRoot    bool    `json:"make_root,omitempty"` // move a categoria para a raiz
+	Name        *string `json:"name,omitempty"`
+	Slug        *string `json:"slug,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Gender      *string `json:"gender,omitempty"`
+	Position    *int    `json:"position,omitempty"`
+	ImageURL    *string `json:"image_url,omitempty"`
+	IsActive    *bool   `json:"is_active,omitempty"`
+}
+
+type CategoryResponse struct {
+	ID          uint64             `json:"id"`
+	ParentID    *uint64            `json:"parent_id,omitempty"`
+	Name        string             `json:"name"`
+	Slug        string             `json:"slug"`
+	Description string             `json:"description"`
+	Gender      string             `json:"gender"`
+	Position    int                `json:"position"`
+	ImageURL    string             `json:"image_url"`
+	IsActive    bool               `json:"is_active"`
+	Children    []CategoryResponse `json:"children"`
+}
+
+type CreateCollectionRequest struct {
+	Name        string                  `json:"name"`
+	Slug        string                  `json:"slug"`
+	Description string                  `json:"description"`
+	Type        schemas.CollectionType  `json:"type"`
+	Rules       schemas.CollectionRules `json:"rules"`
+	Position    int                     `json:"position"`
+	ImageURL    string                  `json:"image_url"`
+}
+
+type UpdateCollectionRequest struct {
+	Name        *string                  `json:"name,omitempty"`
+	Slug        *string                  `json:"slug,omitempty"`
+	Description *string                  `json:"description,omitempty"`
+	Type        *schemas.CollectionType  `json:"type,omitempty"`
+	Rules       *schemas.CollectionRules `json:"rules,omitempty"`
+	Position    *int                     `json:"position,omitempty"`
+	ImageURL    *string                  `json:"image_url,omitempty"`
+	IsActive    *bool                    `json:"is_active,omitempty"`
+}
+
+type SetCollectionProductsRequest struct {
+	ProductIDs []uint64 `json:"product_ids"` // na ordem de exibição
+}
+
+type CollectionResponse struct {
+	ID          uint64                  `json:"id"`
+	Name        string                  `json:"name"`
+	Slug        string                  `json:"slug"`
+	Description string                  `json:"description"`
+	Type        schemas.CollectionType  `json:"type"`
+	Rules       schemas.CollectionRules `json:"rules"`
+	Position    int                     `json:"position"`
+	ImageURL    string                  `json:"image_url"`
+	IsActive    bool                    `json:"is_active"`
+	ProductIDs  []uint64                `json:"product_ids,omitempty"`
+}
+
+func toCategoryResponse(c schemas.Categories) CategoryResponse {
+	return CategoryResponse{
+		ID:          c.ID,
+		ParentID:    c.ParentID,
+		Name:        c.Name,
+		Slug:        c.Slug,
+		Description: c.Description,
+		Gender:      c.Gender,
+		Position:    c.Position,
+		ImageURL:    minio.MediaURL(c.ImageURL),
+		IsActive:    c.IsActive,
+		Children:    []CategoryResponse{},
+	}
+}
+
+// buildCategoryTree monta a árvore a partir da lista plana (já ordenada por posição).
+func buildCategoryTree(categories []schemas.Categories) []CategoryResponse {
+	children := make(map[uint64][]schemas.Categories)
+	known := make(map[uint64]bool, len(categories))
+	for _, c := range categories {
+		known[c.ID] = true
+	}
+
+	var roots []schemas.Categories
+	for _, c := range categories {
+		// Subcategoria cujo pai ficou fora da lista (ex.: pai inativo) não é exibida
+		if c.ParentID == nil {
+			roots = append(roots, c)
+		} else if known[*c.ParentID] {
+			children[*c.ParentID] = append(children[*c.ParentID], c)
+		}
+	}
+
+	var build func(c schemas.Categories) CategoryResponse
+	build = func(c schemas.Categories) CategoryResponse {
+		node := toCategoryResponse(c)
+		for _, child := range children[c.ID] {
+			node.Children = append(node.Children, build(child))
+		}
+		return node
+	}
+
+	tree := make([]CategoryResponse, 0, len(roots))
+	for _, r := range roots {
+		tree = append(tree, build(r))
+	}
+	return tree
+}
+
+func toCollectionResponse(c schemas.Collections) CollectionResponse {
+	return CollectionResponse{
+		ID:          c.ID,
+		Name:        c.Name,
+		Slug:        c.Slug,
+		Description: c.Description,
+		Type:        c.Type,
+		Rules:       c.Rules,
+		Position:    c.Position,
+		ImageURL:    minio.MediaURL(c.ImageURL),
+		IsActive:    c.IsActive,
+	}
+}
+
+func isValidGender(g string) bool {
+	return g == "" || g == "M" || g == "F" || g == "U"
+}
+
+func isValidCollectionType(t schemas.CollectionType) bool {
+	return t == schemas.CollectionManual || t == schemas.CollectionRule
+}
+
+func (req *CreateCategoryRequest) Validate() error {
+	var errs []string
+
+	if strings.TrimSpace(req.Name) == "" {
+		errs = append(errs, "nome é obrigatório")
+	} else if len(req.Name) < 2 || len(req.Name) > 100 {
+		errs = append(errs, "nome deve ter entre 2 e 100 caracteres")
+	}
+
+	if len(req.Slug) > 120 {
+		errs = append(errs, "slug deve ter no máximo 120 caracteres")
+	}
+
+	if !isValidGender(req.Gender) {
+		errs = append(errs, "gênero deve ser M, F ou U")
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (req *UpdateCategoryRequest) Validate() error {
+	var errs []string
+
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			errs = append(errs, "nome não pode ser vazio")
+		} else if len(*req.Name) < 2 || len(*req.Name) > 100 {
+			errs = append(errs, "nome deve ter entre 2 e 100 caracteres")
+		}
+	}
+
+	if req.Slug != nil && (strings.TrimSpace(*req.Slug) == "" || len(*req.Slug) > 120) {
+		errs = append(errs, "slug deve ter entre 1 e 120 caracteres")
+	}
+
+	if req.Gender != nil && !isValidGender(*req.Gender) {
+		errs = append(errs, "gênero deve ser M, F ou U")
+	}
+
+	if req.MakeRoot && req.ParentID != nil {
+		errs = append(errs, "informe parent_id ou make_root, não ambos")
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (req *CreateCollectionRequest) Validate() error {
+	var errs []string
+
+	if strings.TrimSpace(req.Name) == "" {
+		errs = append(errs, "nome é obrigatório")
+	} else if len(req.Name) < 2 || len(req.Name) > 100 {
+		errs = append(errs, "nome deve ter entre 2 e 100 caracteres")
+	}
+
+	if req.Type == "" {
+		req.Type = schemas.CollectionManual
+	}
+	if !isValidCollectionType(req.Type) {
+		errs = append(errs, "tipo deve ser manual ou rule")
+	}
+
+	if err := validateRules(req.Rules); err != "" {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (req *UpdateCollectionRequest) Validate() error {
+	var errs []string
+
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			errs = append(errs, "nome não pode ser vazio")
+		} else if len(*req.Name) < 2 || len(*req.Name) > 100 {
+			errs = append(errs, "nome deve ter entre 2 e 100 caracteres")
+		}
+	}
+
+	if req.Slug != nil && (strings.TrimSpace(*req.Slug) == "" || len(*req.Slug) > 120) {
+		errs = append(errs, "slug deve ter entre 1 e 120 caracteres")
+	}
+
+	if req.Type != nil && !isValidCollectionType(*req.Type) {
+		errs = append(errs, "tipo deve ser manual ou rule")
+	}
+
+	if req.Rules != nil {
+		if err := validateRules(*req.Rules); err != "" {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func validateRules(r schemas.CollectionRules) string {
+	if r.MinPrice != nil && *r.MinPrice < 0 {
+		return "preço mínimo não pode ser negativo"
+	}
+	if r.MaxPrice != nil && *r.MaxPrice < 0 {
+		return "preço máximo não pode ser negativo"
+	}
+	if r.MinPrice != nil && r.MaxPrice != nil && *r.MinPrice > *r.MaxPrice {
+		return "preço mínimo não pode ser maior que o máximo"
+	}
+	if r.CreatedWithinDays < 0 {
+		return "created_within_days não pode ser negativo"
+	}
+	return ""
+}
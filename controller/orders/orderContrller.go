@@ -1,10 +1,11 @@
 package controller
 
 import (
+	"backend_camisaria_store/common"
 	"backend_camisaria_store/config"
 	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/catalog"
 	"fmt"
-	"math/rand"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -14,7 +15,7 @@ import (
 func CreateOrder(c *fiber.Ctx) error {
 
 	req := CreateOrderRequest{}
-	clientID := c.Locals("user_id").(uint64)
+	userID := c.Locals("user_id").(uint64)
 
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -29,36 +30,76 @@ func CreateOrder(c *fiber.Ctx) error {
 		})
 	}
 
+	// Cadastro de cliente vinculado ao usuário logado
 	client := schemas.Clients{}
-	if err := config.DB.First(&client, clientID).Error; err != nil {
+	if err := config.DB.Where("user_id = ?", userID).First(&client).Error; err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Cliente não encontrado",
 		})
 	}
 
 	var total = 0.0
-	allproducts := []schemas.Products{}
+	var originalTotal = 0.0
+	now := time.Now()
+	lines := make([]orderLine, 0, len(req.Products))
 	for _, prod := range req.Products {
 
-		product, err := getProductsValue(prod.ProductID, client.StoreID)
+		product, err := getProductsValue(prod.ProductID, now)
 		if err != nil {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": err.Error(),
 			})
 		}
 
-		if product.StockQuantity < prod.Quantity {
+		line := orderLine{product: *product, quantity: prod.Quantity}
+		available := product.StockQuantity
+		if product.IsBundle {
+			// Kit: disponibilidade vem do estoque dos componentes
+			line.components, err = catalog.LoadBundleComponents(config.DB, product.ID)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error":   "Erro ao buscar componentes do kit",
+					"details": err.Error(),
+				})
+			}
+			visible, err := componentsVisible(line.components, now)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error":   "Erro ao buscar componentes do kit",
+					"details": err.Error(),
+				})
+			}
+			if !visible {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error":   "Kit indisponível",
+					"product": product.Name,
+				})
+			}
+			available = catalog.BundleAvailability(line.components)
+		}
+
+		if available < prod.Quantity {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":     "Quantidade de produto insuficiente",
+				"product":   product.Name,
+				"available": available,
+			})
+		}
+		line.personalization, line.personalizationPrice, err = catalog.ApplyPersonalization(product.PersonalizationOptions, prod.Personalization)
+		if err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Quantidade de produto insuficiente",
+				"error":   "Personalização inválida",
+				"details": err.Error(),
+				"product": product.Name,
 			})
 		}
-		totalValues := product.Price * float64(prod.Quantity)
-		total += totalValues
 
-		// Armazenar produto com quantidade do pedido (para usar depois)
-		productCopy := *product
-		productCopy.StockQuantity = prod.Quantity // Quantidade sendo comprada
-		allproducts = append(allproducts, productCopy)
+		// Preço vigente no momento do pedido (considera janela de promoção) + personalização
+		line.unitPrice = catalog.EffectivePrice(*product, now) + line.personalizationPrice
+		total += line.unitPrice * float64(prod.Quantity)
+		originalTotal += (product.Price + line.personalizationPrice) * float64(prod.Quantity)
+
+		lines = append(lines, line)
 	}
 	// Usar transação para garantir consistência
 	tx := config.DB.Begin()
@@ -68,13 +109,13 @@ func CreateOrder(c *fiber.Ctx) error {
 		}
 	}()
 
-	orderNumber := generateOrderNumber()
+	orderNumber := common.GenerateOrderNumber()
 
 	order := schemas.Orders{
 		ClientID:      client.ID,
 		OrderNumber:   orderNumber,
 		Value:         total,
-		Originalvalue: total,
+		Originalvalue: originalTotal,
 		StatusPayment: schemas.PendingPayment,
 		DeliveryType:  schemas.DeliveryType(req.DeliveryType),
 	}
@@ -87,8 +128,17 @@ func CreateOrder(c *fiber.Ctx) error {
 		})
 	}
 
-	for _, prod := range allproducts {
-		if err := createOrderItems(tx, order.ID, prod.ID, prod.StockQuantity, prod.Price, prod.Price); err != nil {
+	for _, line := range lines {
+		item, err := createOrderItems(tx, schemas.OrderItems{
+			OrderID:              order.ID,
+			ProductID:            line.product.ID,
+			Quantity:             line.quantity,
+			Price:                line.unitPrice,
+			OriginalPrice:        line.product.Price + line.personalizationPrice,
+			Personalization:      line.personalization,
+			PersonalizationPrice: line.personalizationPrice,
+		})
+		if err != nil {
 			tx.Rollback()
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error":   "Erro ao criar item do pedido",
@@ -96,13 +146,43 @@ func CreateOrder(c *fiber.Ctx) error {
 			})
 		}
 
-		// Atualizar estoque do produto
-		if err := updateProductStock(tx, prod.ID, prod.StockQuantity); err != nil {
-			tx.Rollback()
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   "Erro ao atualizar estoque do produto",
-				"details": err.Error(),
-			})
+		if !line.product.IsBundle {
+			// Atualizar estoque do produto
+			if err := updateProductStock(tx, line.product.ID, line.quantity); err != nil {
+				tx.Rollback()
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error":   "Erro ao atualizar estoque do produto",
+					"details": err.Error(),
+				})
+			}
+			continue
+		}
+
+		// Componentes do kit: registrados como itens filhos (valor já cobrado no kit)
+		// e baixados do estoque de cada produto
+		for _, comp := range line.components {
+			quantity := comp.Item.Quantity * line.quantity
+			if _, err := createOrderItems(tx, schemas.OrderItems{
+				OrderID:       order.ID,
+				ParentItemID:  &item.ID,
+				ProductID:     comp.Product.ID,
+				Quantity:      quantity,
+				Price:         0,
+				OriginalPrice: comp.Product.Price,
+			}); err != nil {
+				tx.Rollback()
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error":   "Erro ao criar item do pedido",
+					"details": err.Error(),
+				})
+			}
+			if err := updateProductStock(tx, comp.Product.ID, quantity); err != nil {
+				tx.Rollback()
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error":   "Erro ao atualizar estoque do produto",
+					"details": err.Error(),
+				})
+			}
 		}
 	}
 
@@ -114,44 +194,83 @@ func CreateOrder(c *fiber.Ctx) error {
 		})
 	}
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"message": "Pedido criado com sucesso",
+		"message":      "Pedido criado com sucesso",
+		"order_id":     order.ID,
+		"order_number": order.OrderNumber,
 	})
 }
 
-func getProductsValue(prodID, storeID uint64) (*schemas.Products, error) {
+// orderLine é um produto do pedido já validado; kits trazem seus componentes.
+type orderLine struct {
+	product    schemas.Products
+	quantity   int
+	unitPrice  float64
+	components []catalog.BundleComponent
+
+	personalization      []schemas.PersonalizationValue
+	personalizationPrice float64
+}
+
+// getProductsValue busca o produto à venda em now (ativo, publicado e dentro do agendamento).
+func getProductsValue(prodID uint64, now time.Time) (*schemas.Products, error) {
 	productSchemas := &schemas.Products{}
-	product := config.DB.Where("id = ? AND store_id = ?", prodID, storeID).First(productSchemas)
+	product := config.DB.Scopes(catalog.VisibleAt(now)).Where("id = ?", prodID).First(productSchemas)
 	if product.Error != nil {
 		return nil, fmt.Errorf("produto não encontrado")
 	}
 	return productSchemas, nil
 }
 
-func createOrderItems(tx *gorm.DB, orderID, productID uint64, quantity int, price, originalPrice float64) error {
-	orderItem := schemas.OrderItems{
-		OrderID:       orderID,
-		ProductID:     productID,
-		Quantity:      quantity,
-		Price:         price,
-		OriginalPrice: originalPrice,
+// componentsVisible indica se todos os componentes do kit estão à venda em now.
+func componentsVisible(components []catalog.BundleComponent, now time.Time) (bool, error) {
+	if len(components) == 0 {
+		return false, nil
 	}
+	ids := make(map[uint64]bool, len(components))
+	for _, comp := range components {
+		ids[comp.Product.ID] = true
+	}
+	idList := make([]uint64, 0, len(ids))
+	for id := range ids {
+		idList = append(idList, id)
+	}
+	var visible int64
+	if err := config.DB.Model(&schemas.Products{}).Scopes(catalog.VisibleAt(now)).
+		Where("id IN ?", idList).Count(&visible).Error; err != nil {
+		return false, err
+	}
+	return int(visible) == len(idList), nil
+}
+
+func createOrderItems(tx *gorm.DB, orderItem schemas.OrderItems) (*schemas.OrderItems, error) {
 	if err := tx.Create(&orderItem).Error; err != nil {
-		return fmt.Errorf("erro ao criar item do pedido: %w", err)
+		return nil, fmt.Errorf("erro ao criar item do pedido: %w", err)
 	}
-	return nil
+	return &orderItem, nil
 }
 
+// updateProductStock baixa o estoque apenas se houver quantidade suficiente,
+// evitando estoque negativo quando pedidos concorrentes (ou kits) disputam o mesmo produto.
+// A baixa fica no histórico de revisões do produto em nome do sistema.
 func updateProductStock(tx *gorm.DB, productID uint64, quantitySold int) error {
-	if err := tx.Model(&schemas.Products{}).Where("id = ?", productID).
-		Update("stock_quantity", gorm.Expr("stock_quantity - ?", quantitySold)).Error; err != nil {
+	var before schemas.Products
+	if err := tx.First(&before, productID).Error; err != nil {
 		return fmt.Errorf("erro ao atualizar estoque: %w", err)
 	}
-	return nil
-}
 
-func generateOrderNumber() string {
-	// Formato: ORD + timestamp + 3 dígitos aleatórios
-	timestamp := time.Now().Format("20060102150405")
-	randomNum := rand.Intn(900) + 100 // 100-999
-	return fmt.Sprintf("ORD%s%d", timestamp, randomNum)
+	result := tx.Model(&schemas.Products{}).
+		Where("id = ? AND stock_quantity >= ?", productID, quantitySold).
+		Update("stock_quantity", gorm.Expr("stock_quantity - ?", quantitySold))
+	if result.Error != nil {
+		return fmt.Errorf("erro ao atualizar estoque: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("estoque insuficiente para o produto %d", productID)
+	}
+
+	var after schemas.Products
+	if err := tx.First(&after, productID).Error; err != nil {
+		return fmt.Errorf("erro ao atualizar estoque: %w", err)
+	}
+	return catalog.RecordProductRevision(tx, schemas.RevisionUpdate, &before, &after, catalog.SystemActor)
 }
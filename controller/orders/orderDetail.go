@@ -0,0 +1,296 @@
+package controller
+
+import (
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/minio"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListMyOrders — pedidos do cliente logado, mais recentes primeiro.
+func ListMyOrders(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint64)
+
+	var client schemas.Clients
+	if err := config.DB.Where("user_id = ?", userID).First(&client).Error; err != nil {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"orders": []OrderResponse{}})
+	}
+
+	var orders []schemas.Orders
+	if err := config.DB.Where("client_id = ?", client.ID).Order("id DESC").Limit(100).Find(&orders).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar pedidos",
+		})
+	}
+
+	responses := make([]OrderResponse, 0, len(orders))
+	for _, o := range orders {
+		items, err := loadOrderItems(o.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro ao buscar itens do pedido",
+			})
+		}
+		responses = append(responses, toOrderResponse(o, items))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"orders": responses})
+}
+
+// GetOrder — detalhe do pedido com personalizações (dono ou equipe interna).
+func GetOrder(c *fiber.Ctx) error {
+	order, ferr := findOrder(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	items, err := loadOrderItems(order.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar itens do pedido",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"order": toOrderResponse(*order, items),
+	})
+}
+
+// GetProductionSheet — admin: ficha de produção com o que confeccionar e bordar.
+// Pedidos de orçamento usam a grade do orçamento; kits aparecem pelos componentes.
+func GetProductionSheet(c *fiber.Ctx) error {
+	order, ferr := findOrder(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	sheet := ProductionSheetResponse{
+		OrderNumber:      order.OrderNumber,
+		ProductionStatus: order.ProductionStatus,
+		Lines:            []ProductionSheetLine{},
+		GeneratedAt:      time.Now(),
+	}
+
+	var client schemas.Clients
+	if err := config.DB.First(&client, order.ClientID).Error; err == nil {
+		sheet.ClientName = client.Name
+	}
+
+	if order.QuoteID != nil {
+		var quote schemas.Quotes
+		if err := config.DB.Preload("Items").Preload("Artworks").First(&quote, *order.QuoteID).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro ao buscar orçamento do pedido",
+			})
+		}
+		sheet.CompanyName = quote.CompanyName
+		sheet.Notes = quote.Notes
+
+		artworks := make(map[uint64]string, len(quote.Artworks))
+		for _, a := range quote.Artworks {
+			artworks[a.ID] = minio.SignedURL(a.ObjectName, 24*time.Hour) // ficha impressa/consultada no dia
+		}
+		products, err := productsByID(quoteProductIDs(quote.Items))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro ao buscar produtos do pedido",
+			})
+		}
+		for _, it := range quote.Items {
+			p := products[it.ProductID]
+			line := ProductionSheetLine{
+				SKU:                 p.SKU,
+				Name:                p.Name,
+				Color:               it.Color,
+				Quantity:            it.Quantity,
+				SizeGrid:            it.SizeGrid,
+				EmbroideryPositions: it.EmbroideryPositions,
+				PieceNames:          it.Personalization,
+			}
+			if it.ArtworkID != nil {
+				line.ArtworkURL = artworks[*it.ArtworkID]
+			}
+			sheet.Lines = append(sheet.Lines, line)
+		}
+		return c.Status(fiber.StatusOK).JSON(sheet)
+	}
+
+	items, err := loadOrderItems(order.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar itens do pedido",
+		})
+	}
+
+	kits := make(map[uint64]OrderItemResponse)
+	for _, it := range items {
+		if it.ParentItemID == nil {
+			kits[it.ID] = it
+		}
+	}
+	hasComponents := make(map[uint64]bool)
+	for _, it := range items {
+		if it.ParentItemID != nil {
+			hasComponents[*it.ParentItemID] = true
+		}
+	}
+
+	for _, it := range items {
+		// A linha do kit em si não é confeccionada; seus componentes sim
+		if hasComponents[it.ID] {
+			continue
+		}
+		line := ProductionSheetLine{
+			SKU:             it.SKU,
+			Name:            it.Name,
+			Size:            it.Size,
+			Color:           it.Color,
+			Quantity:        it.Quantity,
+			Personalization: it.Personalization,
+		}
+		if it.ParentItemID != nil {
+			kit := kits[*it.ParentItemID]
+			line.Kit = kit.Name
+			line.Personalization = kit.Personalization
+		}
+		sheet.Lines = append(sheet.Lines, line)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(sheet)
+}
+
+// findOrder carrega o pedido do path; clientes só enxergam os próprios.
+func findOrder(c *fiber.Ctx) (*schemas.Orders, *fiber.Error) {
+	orderID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "ID inválido")
+	}
+
+	var order schemas.Orders
+	if err := config.DB.First(&order, orderID).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Pedido não encontrado")
+	}
+
+	userType, _ := c.Locals("user_type").(string)
+	if userType == string(schemas.RoleClient) {
+		var client schemas.Clients
+		if err := config.DB.First(&client, order.ClientID).Error; err != nil ||
+			client.UserID != c.Locals("user_id").(uint64) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Pedido não encontrado")
+		}
+	}
+	return &order, nil
+}
+
+func loadOrderItems(orderID uint64) ([]OrderItemResponse, error) {
+	var items []schemas.OrderItems
+	if err := config.DB.Where("order_id = ?", orderID).Order("id ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint64, 0, len(items))
+	for _, it := range items {
+		ids = append(ids, it.ProductID)
+	}
+	products, err := productsByID(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]OrderItemResponse, 0, len(items))
+	for _, it := range items {
+		p := products[it.ProductID]
+		responses = append(responses, OrderItemResponse{
+			ID:                   it.ID,
+			ParentItemID:         it.ParentItemID,
+			ProductID:            it.ProductID,
+			SKU:                  p.SKU,
+			Name:                 p.Name,
+			Size:                 p.Size,
+			Color:                p.Color,
+			Quantity:             it.Quantity,
+			Price:                it.Price,
+			OriginalPrice:        it.OriginalPrice,
+			Personalization:      it.Personalization,
+			PersonalizationPrice: it.PersonalizationPrice,
+		})
+	}
+	return responses, nil
+}
+
+func productsByID(ids []uint64) (map[uint64]schemas.Products, error) {
+	result := make(map[uint64]schemas.Products, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+	var products []schemas.Products
+	if err := config.DB.Unscoped().Select("id", "sku", "name", "size", "color").
+		Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range products {
+		result[p.ID] = p
+	}
+	return result, nil
+}
+
+func quoteProductIDs(items []schemas.QuoteItems) []uint64 {
+	ids := make([]uint64, 0, len(items))
+	for _, it := range items {
+		ids = append(ids, it.ProductID)
+	}
+	return ids
+}
+
+func toOrderResponse(o schemas.Orders, items []OrderItemResponse) OrderResponse {
+	response := OrderResponse{
+		ID:            o.ID,
+		OrderNumber:   o.OrderNumber,
+		Value:         o.Value,
+		OriginalValue: o.Originalvalue,
+		StatusPayment: o.StatusPayment,
+		DeliveryType:  o.DeliveryType,
+		QuoteID:       o.QuoteID,
+		Items:         items,
+		DeliveredAt:   o.DeliveredAt,
+		CreatedAt:     o.CreatedAt.Format(time.RFC3339),
+	}
+	// Só pedidos sob encomenda têm etapa de produção (nil nos demais)
+	response.ProductionStatus = o.ProductionStatus
+	return response
+}
+
+// MarkOrderDelivered — admin confirma a entrega; a partir daí os itens podem ser avaliados.
+func MarkOrderDelivered(c *fiber.Ctx) error {
+	order, ferr := findOrder(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+	if order.DeliveredAt != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":        "Pedido já marcado como entregue",
+			"delivered_at": order.DeliveredAt,
+		})
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"delivered_at": now}
+	if order.ProductionStatus != nil {
+		updates["production_status"] = schemas.ProductionDelivered
+	}
+	if err := config.DB.Model(order).Updates(updates).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao marcar pedido como entregue",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":      "Pedido marcado como entregue",
+		"order_id":     order.ID,
+		"delivered_at": now,
+	})
+}
@@ -3,6 +3,9 @@ package controller
 import (
 	"errors"
 	"strings"
+	"time"
+
+	"backend_camisaria_store/schemas"
 )
 
 type DeliveryType string
@@ -18,8 +21,63 @@ type CreateOrderRequest struct {
 }
 
 type productsStruct struct {
-	ProductID uint64 `json:"product_id" validate:"required"`
-	Quantity  int    `json:"quantity" validate:"required,min=1"`
+	ProductID       uint64                         `json:"product_id" validate:"required"`
+	Quantity        int                            `json:"quantity" validate:"required,min=1"`
+	Personalization []schemas.PersonalizationValue `json:"personalization,omitempty"` // aplicada a todas as peças da linha
+}
+
+type OrderItemResponse struct {
+	ID                   uint64                         `json:"id"`
+	ParentItemID         *uint64                        `json:"parent_item_id,omitempty"` // componente de kit
+	ProductID            uint64                         `json:"product_id"`
+	SKU                  string                         `json:"sku"`
+	Name                 string                         `json:"name"`
+	Size                 string                         `json:"size"`
+	Color                string                         `json:"color"`
+	Quantity             int                            `json:"quantity"`
+	Price                float64                        `json:"price"`
+	OriginalPrice        float64                        `json:"original_price"`
+	Personalization      []schemas.PersonalizationValue `json:"personalization,omitempty"`
+	PersonalizationPrice float64                        `json:"personalization_price,omitempty"`
+}
+
+type OrderResponse struct {
+	ID               uint64                    `json:"id"`
+	OrderNumber      string                    `json:"order_number"`
+	Value            float64                   `json:"value"`
+	OriginalValue    float64                   `json:"original_value"`
+	StatusPayment    schemas.StatusPayment     `json:"status_payment"`
+	DeliveryType     schemas.DeliveryType      `json:"delivery_type"`
+	QuoteID          *uint64                   `json:"quote_id,omitempty"`
+	ProductionStatus *schemas.ProductionStatus `json:"production_status,omitempty"`
+	Items            []OrderItemResponse       `json:"items"`
+	DeliveredAt      *time.Time                `json:"delivered_at,omitempty"`
+	CreatedAt        string                    `json:"created_at"`
+}
+
+// ProductionSheetLine é uma linha da ficha de produção: peça, quantidade e o que bordar.
+type ProductionSheetLine struct {
+	SKU                 string                         `json:"sku"`
+	Name                string                         `json:"name"`
+	Size                string                         `json:"size"`
+	Color               string                         `json:"color"`
+	Quantity            int                            `json:"quantity"`
+	SizeGrid            map[string]int                 `json:"size_grid,omitempty"` // pedidos de orçamento
+	EmbroideryPositions []string                       `json:"embroidery_positions,omitempty"`
+	Personalization     []schemas.PersonalizationValue `json:"personalization,omitempty"`
+	PieceNames          []schemas.QuotePersonalization `json:"piece_names,omitempty"` // nome/número por peça
+	ArtworkURL          string                         `json:"artwork_url,omitempty"`
+	Kit                 string                         `json:"kit,omitempty"` // nome do kit de origem
+}
+
+type ProductionSheetResponse struct {
+	OrderNumber      string                    `json:"order_number"`
+	ClientName       string                    `json:"client_name"`
+	CompanyName      string                    `json:"company_name,omitempty"`
+	ProductionStatus *schemas.ProductionStatus `json:"production_status"` // null até a produção começar
+	Lines            []ProductionSheetLine     `json:"lines"`
+	Notes            string                    `json:"notes,omitempty"`
+	GeneratedAt      time.Time                 `json:"generated_at"`
 }
 
 func (req *CreateOrderRequest) Validate() error {
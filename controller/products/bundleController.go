@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/catalog"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetProductBundle — componentes do kit, disponibilidade e economia em relação aos avulsos.
+func GetProductBundle(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+
+	var product schemas.Products
+	if err := config.DB.First(&product, productID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produto não encontrado"})
+	}
+	if !product.IsBundle {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produto não é um kit"})
+	}
+
+	components, err := catalog.LoadBundleComponents(config.DB, product.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao buscar componentes do kit",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"bundle": toBundleResponse(product, components),
+	})
+}
+
+// SetProductBundle substitui os componentes do kit; um produto comum passa a ser kit.
+func SetProductBundle(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+
+	req := SetBundleRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Erro ao processar dados da requisição",
+			"details": err.Error(),
+		})
+	}
+
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	var product schemas.Products
+	if err := config.DB.First(&product, productID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produto não encontrado"})
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := replaceBundleItems(tx, product.ID, req.Items); err != nil {
+			return err
+		}
+		if product.IsBundle && product.StockQuantity == 0 {
+			return nil
+		}
+		// Kits não têm estoque próprio: o saldo do produto comum é descartado
+		return applyProductChanges(tx, &product, map[string]interface{}{
+			"is_bundle":      true,
+			"stock_quantity": 0,
+		}, "", schemas.RevisionUpdate, actorFromCtx(c))
+	})
+	if err != nil {
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			return c.Status(fiberErr.Code).JSON(fiber.Map{
+				"error":   "Dados inválidos",
+				"details": fiberErr.Message,
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao salvar componentes do kit",
+			"details": err.Error(),
+		})
+	}
+
+	components, err := catalog.LoadBundleComponents(config.DB, product.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao buscar componentes do kit",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Kit atualizado com sucesso",
+		"bundle":  toBundleResponse(product, components),
+	})
+}
+
+// RemoveProductBundle desfaz o kit: remove os componentes e o produto volta a ter estoque próprio.
+func RemoveProductBundle(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+
+	var product schemas.Products
+	if err := config.DB.First(&product, productID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produto não encontrado"})
+	}
+	if !product.IsBundle {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Produto não é um kit"})
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bundle_id = ?", product.ID).Delete(&schemas.BundleItems{}).Error; err != nil {
+			return err
+		}
+		return applyProductChanges(tx, &product, map[string]interface{}{
+			"is_bundle": false,
+		}, "", schemas.RevisionUpdate, actorFromCtx(c))
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao desfazer kit",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Kit desfeito com sucesso",
+		"product": toProductResponse(product),
+	})
+}
+
+// replaceBundleItems grava os componentes do kit. Erros de validação voltam como *fiber.Error.
+// Kits não podem ser aninhados: componente não pode ser kit e um kit não pode ser componente.
+func replaceBundleItems(tx *gorm.DB, bundleID uint64, items []BundleItemRequest) error {
+	var usedAsComponent int64
+	if err := tx.Model(&schemas.BundleItems{}).Where("component_id = ?", bundleID).
+		Count(&usedAsComponent).Error; err != nil {
+		return err
+	}
+	if usedAsComponent > 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "produto é componente de outro kit e não pode ser um kit")
+	}
+
+	ids := make([]uint64, 0, len(items))
+	for _, it := range items {
+		if it.ProductID == bundleID {
+			return fiber.NewError(fiber.StatusBadRequest, "o kit não pode conter a si mesmo")
+		}
+		ids = append(ids, it.ProductID)
+	}
+
+	var components []schemas.Products
+	if err := tx.Select("id", "is_bundle").Where("id IN ?", ids).Find(&components).Error; err != nil {
+		return err
+	}
+	if len(components) != len(ids) {
+		return fiber.NewError(fiber.StatusBadRequest, "componente do kit não encontrado")
+	}
+	for _, p := range components {
+		if p.IsBundle {
+			return fiber.NewError(fiber.StatusBadRequest, "um kit não pode ser componente de outro kit")
+		}
+	}
+
+	if err := tx.Where("bundle_id = ?", bundleID).Delete(&schemas.BundleItems{}).Error; err != nil {
+		return err
+	}
+	rows := make([]schemas.BundleItems, 0, len(items))
+	for _, it := range items {
+		rows = append(rows, schemas.BundleItems{
+			BundleID:    bundleID,
+			ComponentID: it.ProductID,
+			Quantity:    it.Quantity,
+		})
+	}
+	return tx.Create(&rows).Error
+}
+
+// withBundleStock troca o estoque de um kit pela disponibilidade calculada, para exibição.
+func withBundleStock(product *schemas.Products) error {
+	products := []schemas.Products{*product}
+	if err := catalog.ApplyBundleStock(config.DB, products); err != nil {
+		return err
+	}
+	*product = products[0]
+	return nil
+}
@@ -0,0 +1,232 @@
+package controller
+
+import (
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/catalog"
+	"backend_camisaria_store/service/minio"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// ListProductImages — galeria do produto na ordem de exibição (capa primeiro).
+func ListProductImages(c *fiber.Ctx) error {
+	product, ferr := findProductForImages(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	images, err := catalog.ProductGallery(config.DB, product.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar imagens do produto",
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"images": toImageResponses(images)})
+}
+
+// ReorderProductImages grava a nova ordem da galeria; a lista deve conter todas as imagens.
+func ReorderProductImages(c *fiber.Ctx) error {
+	req := ReorderImagesRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Erro ao processar dados da requisição",
+			"details": err.Error(),
+		})
+	}
+
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	product, ferr := findProductForImages(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	var current []uint64
+	if err := config.DB.Model(&schemas.ProductImages{}).Where("product_id = ?", product.ID).
+		Pluck("id", &current).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar imagens do produto",
+		})
+	}
+	owned := make(map[uint64]bool, len(current))
+	for _, id := range current {
+		owned[id] = true
+	}
+	for _, id := range req.ImageIDs {
+		if !owned[id] {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":    "Dados inválidos",
+				"details":  "imagem não pertence ao produto",
+				"image_id": id,
+			})
+		}
+	}
+	if len(req.ImageIDs) != len(current) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": "informe todas as imagens do produto na nova ordem",
+		})
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for i, id := range req.ImageIDs {
+			if err := tx.Model(&schemas.ProductImages{}).Where("id = ?", id).
+				Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return catalog.SyncProductImages(tx, product.ID)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao reordenar imagens",
+			"details": err.Error(),
+		})
+	}
+
+	return respondGallery(c, product.ID, "Ordem das imagens atualizada")
+}
+
+// UpdateProductImage edita alt, cor e capa de uma imagem; marcar capa desmarca a anterior.
+func UpdateProductImage(c *fiber.Ctx) error {
+	req := UpdateImageRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Erro ao processar dados da requisição",
+			"details": err.Error(),
+		})
+	}
+
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	image, ferr := findProductImage(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	updates := make(map[string]interface{})
+	if req.AltText != nil {
+		updates["alt_text"] = strings.TrimSpace(*req.AltText)
+	}
+	if req.Color != nil {
+		updates["color"] = strings.TrimSpace(*req.Color)
+	}
+	if req.IsCover != nil {
+		updates["is_cover"] = true
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if req.IsCover != nil {
+			if err := tx.Model(&schemas.ProductImages{}).
+				Where("product_id = ? AND id <> ?", image.ProductID, image.ID).
+				Update("is_cover", false).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(image).Updates(updates).Error; err != nil {
+			return err
+		}
+		return catalog.SyncProductImages(tx, image.ProductID)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao atualizar imagem",
+			"details": err.Error(),
+		})
+	}
+
+	return respondGallery(c, image.ProductID, "Imagem atualizada")
+}
+
+// DeleteProductImage remove a imagem da galeria e do MinIO; sem capa, a primeira assume.
+func DeleteProductImage(c *fiber.Ctx) error {
+	image, ferr := findProductImage(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(image).Error; err != nil {
+			return err
+		}
+		return catalog.SyncProductImages(tx, image.ProductID)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao remover imagem",
+			"details": err.Error(),
+		})
+	}
+
+	// As versões saem do bucket depois do commit, se nenhuma outra imagem usar o mesmo arquivo
+	var failed []minio.DeleteImageResult
+	for _, f := range minio.ReleaseObjects(catalog.ImageObjectRefs(*image)) {
+		if !f.Success {
+			failed = append(failed, f)
+		}
+	}
+	if len(failed) > 0 {
+		return c.Status(fiber.StatusPartialContent).JSON(fiber.Map{
+			"message": "Imagem removida da galeria, mas arquivos não foram apagados do armazenamento",
+			"files":   failed,
+		})
+	}
+
+	return respondGallery(c, image.ProductID, "Imagem removida")
+}
+
+func respondGallery(c *fiber.Ctx, productID uint64, message string) error {
+	images, err := catalog.ProductGallery(config.DB, productID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar imagens do produto",
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": message,
+		"images":  toImageResponses(images),
+	})
+}
+
+func findProductForImages(c *fiber.Ctx) (*schemas.Products, *fiber.Error) {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "ID inválido")
+	}
+	var product schemas.Products
+	if err := config.DB.Select("id", "name").First(&product, productID).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Produto não encontrado")
+	}
+	return &product, nil
+}
+
+// findProductImage carrega a imagem do path garantindo que pertence ao produto do path.
+func findProductImage(c *fiber.Ctx) (*schemas.ProductImages, *fiber.Error) {
+	product, ferr := findProductForImages(c)
+	if ferr != nil {
+		return nil, ferr
+	}
+	imageID, err := strconv.ParseUint(c.Params("imageId"), 10, 64)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "ID da imagem inválido")
+	}
+	var image schemas.ProductImages
+	if err := config.DB.Where("id = ? AND product_id = ?", imageID, product.ID).First(&image).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Imagem não encontrada")
+	}
+	return &image, nil
+}
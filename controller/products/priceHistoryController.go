@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/catalog"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultPriceHistoryDays = 90
+	maxPriceHistoryDays     = 365
+)
+
+// GetProductPriceHistory — admin: mudanças de preço do período (?days=, padrão 90)
+// já com o preço vigente no início, para o gráfico começar preenchido.
+func GetProductPriceHistory(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+
+	days := c.QueryInt("days", defaultPriceHistoryDays)
+	if days < 1 || days > maxPriceHistoryDays {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "days deve estar entre 1 e " + strconv.Itoa(maxPriceHistoryDays),
+		})
+	}
+
+	var product schemas.Products
+	if err := config.DB.First(&product, productID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produto não encontrado"})
+	}
+
+	now := time.Now()
+	entries, err := catalog.PriceHistory(config.DB, product.ID, now.AddDate(0, 0, -days))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar histórico de preços",
+		})
+	}
+	lowest, err := catalog.LowestPrices(config.DB, []uint64{product.ID}, now)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao calcular menor preço",
+		})
+	}
+
+	response := PriceHistoryResponse{
+		ProductID:      product.ID,
+		Days:           days,
+		Price:          product.Price,
+		EffectivePrice: catalog.EffectivePrice(product, now),
+		Points:         make([]PricePoint, 0, len(entries)),
+	}
+	if price, ok := lowest[product.ID]; ok {
+		response.LowestPrice30d = &price
+	}
+	for _, e := range entries {
+		response.Points = append(response.Points, PricePoint{
+			At:               e.CreatedAt,
+			Price:            e.Price,
+			PromotionalPrice: e.PromotionalPrice,
+			PromotionActive:  e.PromotionActive,
+			EffectivePrice:   e.EffectivePrice,
+			Source:           e.Source,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// lowestPrices busca o menor preço dos últimos 30 dias dos produtos da resposta.
+func lowestPrices(products ...schemas.Products) (map[uint64]float64, error) {
+	ids := make([]uint64, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+	return catalog.LowestPrices(config.DB, ids, time.Now())
+}
+
+func lowestPriceOf(prices map[uint64]float64, productID uint64) *float64 {
+	price, ok := prices[productID]
+	if !ok {
+		return nil
+	}
+	return &price
+}
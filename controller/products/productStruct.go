@@ -1,19 +1,24 @@
 package controller
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
 	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/catalog"
 	"backend_camisaria_store/service/minio"
 )
 
 type CreateProductRequest struct {
 	SKU              string                `json:"sku"`
 	Name             string                `json:"name"`
+	Slug             string                `json:"slug"` // opcional: gerado a partir do nome
 	Description      string                `json:"description"`
-	Categorys        schemas.Category      `json:"categorys"`
+	CategoryID       *uint64               `json:"category_id,omitempty"`
+	Categorys        schemas.Category      `json:"categorys"` // slug da categoria (alternativa a category_id)
 	Size             string                `json:"size"`
 	Color            string                `json:"color"`
 	Material         string                `json:"material"`
@@ -28,12 +33,27 @@ type CreateProductRequest struct {
 	SEODescription   string                `json:"seo_description"`
 	SEOKeywords      string                `json:"seo_keywords"`
 	Status           schemas.ProductStatus `json:"status"`
+
+	// Kit: o estoque é calculado pelos componentes (stock_quantity é ignorado)
+	IsBundle    bool                `json:"is_bundle"`
+	BundleItems []BundleItemRequest `json:"bundle_items,omitempty"`
+
+	PersonalizationOptions []schemas.PersonalizationField `json:"personalization_options,omitempty"`
+	SizeChartID            *uint64                        `json:"size_chart_id,omitempty"` // sem tabela, vale a da categoria
+
+	// Agendamentos (RFC3339); aplicados pelo service/scheduler
+	PublishAt         *time.Time `json:"publish_at,omitempty"`
+	UnpublishAt       *time.Time `json:"unpublish_at,omitempty"`
+	PromotionStartsAt *time.Time `json:"promotion_starts_at,omitempty"`
+	PromotionEndsAt   *time.Time `json:"promotion_ends_at,omitempty"`
 }
 
 type UpdateProductRequest struct {
 	SKU              *string                `json:"sku,omitempty"`
 	Name             *string                `json:"name,omitempty"`
+	Slug             *string                `json:"slug,omitempty"` // sem slug, um novo nome gera novo slug
 	Description      *string                `json:"description,omitempty"`
+	CategoryID       *uint64                `json:"category_id,omitempty"`
 	Categorys        *schemas.Category      `json:"categorys,omitempty"`
 	Size             *string                `json:"size,omitempty"`
 	Color            *string                `json:"color,omitempty"`
@@ -51,13 +71,25 @@ type UpdateProductRequest struct {
 	Tags             *string                `json:"tags,omitempty"`
 	SEODescription   *string                `json:"seo_description,omitempty"`
 	SEOKeywords      *string                `json:"seo_keywords,omitempty"`
+
+	// Lista vazia remove as opções de personalização
+	PersonalizationOptions *[]schemas.PersonalizationField `json:"personalization_options,omitempty"`
+	SizeChartID            *uint64                         `json:"size_chart_id,omitempty"` // 0 remove a tabela própria
+
+	// Agendamentos em RFC3339; string vazia remove o agendamento
+	PublishAt         *string `json:"publish_at,omitempty"`
+	UnpublishAt       *string `json:"unpublish_at,omitempty"`
+	PromotionStartsAt *string `json:"promotion_starts_at,omitempty"`
+	PromotionEndsAt   *string `json:"promotion_ends_at,omitempty"`
 }
 
 type ProductResponse struct {
 	ID               uint64                `json:"id"`
 	SKU              string                `json:"sku"`
 	Name             string                `json:"name"`
+	Slug             string                `json:"slug"`
 	Description      string                `json:"description"`
+	CategoryID       *uint64               `json:"category_id,omitempty"`
 	Categorys        schemas.Category      `json:"categorys"`
 	Size             string                `json:"size"`
 	Color            string                `json:"color"`
@@ -73,23 +105,143 @@ type ProductResponse struct {
 	Status           schemas.ProductStatus `json:"status"`
 	IsActive         bool                  `json:"is_active"`
 	IsPromotional    bool                  `json:"is_promotional"`
+	IsBundle         bool                  `json:"is_bundle"`        // stock_quantity = kits disponíveis
+	PromotionActive  bool                  `json:"promotion_active"` // considera a janela da promoção
+	EffectivePrice   float64               `json:"effective_price"`
 	Tags             string                `json:"tags"`
 	SEODescription   string                `json:"seo_description"`
 	SEOKeywords      string                `json:"seo_keywords"`
-	CreatedAt        string                `json:"created_at"`
-	UpdatedAt        string                `json:"updated_at"`
+
+	PersonalizationOptions []schemas.PersonalizationField `json:"personalization_options"`
+	SizeChartID            *uint64                        `json:"size_chart_id,omitempty"`
+
+	RatingAverage float64 `json:"rating_average"`
+	RatingCount   int     `json:"rating_count"`
+
+	// Menor preço efetivo nos 30 dias antes do preço atual (referência ao divulgar a promoção)
+	LowestPrice30d *float64 `json:"lowest_price_30d,omitempty"`
+
+	Gallery []ProductImageResponse `json:"gallery,omitempty"` // detalhe do produto
+
+	PublishAt         *time.Time `json:"publish_at,omitempty"`
+	UnpublishAt       *time.Time `json:"unpublish_at,omitempty"`
+	PromotionStartsAt *time.Time `json:"promotion_starts_at,omitempty"`
+	PromotionEndsAt   *time.Time `json:"promotion_ends_at,omitempty"`
+
+	CreatedAt string     `json:"created_at"`
+	UpdatedAt string     `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"` // na lixeira
 }
 
 type ProductListResponse struct {
-	Products []ProductResponse `json:"products"`
-	Total    int64             `json:"total"`
-	Page     int               `json:"page"`
-	Limit    int               `json:"limit"`
-	Pages    int               `json:"pages"`
+	Products   []ProductResponse `json:"products"`
+	Total      int64             `json:"total"`
+	Page       int               `json:"page"`
+	Limit      int               `json:"limit"`
+	Pages      int               `json:"pages"`
+	Suggestion string            `json:"suggestion,omitempty"` // busca corrigida ("você quis dizer")
+}
+
+type ProductSuggestion struct {
+	ID    uint64  `json:"id"`
+	Name  string  `json:"name"`
+	Slug  string  `json:"slug"`
+	SKU   string  `json:"sku"`
+	Price float64 `json:"price"`
+}
+
+type PublicCategory struct {
+	ID   uint64 `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type ProductSEO struct {
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	Keywords     string `json:"keywords"`
+	CanonicalURL string `json:"canonical_url,omitempty"`
+}
+
+// PublicProductResponse é a página do produto na loja: só dados publicáveis
+// (sem estoque mínimo, status interno ou datas de auditoria).
+type PublicProductResponse struct {
+	ID               uint64                         `json:"id"`
+	Slug             string                         `json:"slug"`
+	SKU              string                         `json:"sku"`
+	Name             string                         `json:"name"`
+	Description      string                         `json:"description"`
+	Category         *PublicCategory                `json:"category,omitempty"`
+	Size             string                         `json:"size"`
+	Color            string                         `json:"color"`
+	Material         string                         `json:"material"`
+	Gender           string                         `json:"gender"`
+	Price            float64                        `json:"price"`
+	PromotionalPrice *float64                       `json:"promotional_price,omitempty"`
+	IsPromotional    bool                           `json:"is_promotional"`
+	PromotionEndsAt  *time.Time                     `json:"promotion_ends_at,omitempty"`
+	EffectivePrice   float64                        `json:"effective_price"`
+	LowestPrice30d   *float64                       `json:"lowest_price_30d,omitempty"` // menor preço nos 30 dias antes do atual
+	RatingAverage    float64                        `json:"rating_average"`
+	RatingCount      int                            `json:"rating_count"`
+	InStock          bool                           `json:"in_stock"`
+	Weight           float64                        `json:"weight"`
+	Dimensions       string                         `json:"dimensions"`
+	Images           []string                       `json:"images"`
+	Gallery          []PublicImage                  `json:"gallery"` // imagens com alt e cor (capa primeiro)
+	Tags             string                         `json:"tags"`
+	BundleItems      []PublicBundleItem             `json:"bundle_items,omitempty"` // conteúdo do kit
+	Personalization  []schemas.PersonalizationField `json:"personalization_options,omitempty"`
+	SEO              ProductSEO                     `json:"seo"`
 }
 
+type BundleItemRequest struct {
+	ProductID uint64 `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+type SetBundleRequest struct {
+	Items []BundleItemRequest `json:"items"`
+}
+
+type BundleComponentResponse struct {
+	ProductID     uint64  `json:"product_id"`
+	SKU           string  `json:"sku"`
+	Name          string  `json:"name"`
+	Quantity      int     `json:"quantity"`
+	StockQuantity int     `json:"stock_quantity"`
+	IsActive      bool    `json:"is_active"`
+	Price         float64 `json:"price"`
+}
+
+type BundleResponse struct {
+	BundleID        uint64                    `json:"bundle_id"`
+	Available       int                       `json:"available"`        // kits que o estoque permite montar
+	ComponentsPrice float64                   `json:"components_price"` // soma dos componentes avulsos
+	BundlePrice     float64                   `json:"bundle_price"`
+	Savings         float64                   `json:"savings"`
+	Components      []BundleComponentResponse `json:"components"`
+}
+
+type PublicBundleItem struct {
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	Size     string `json:"size"`
+	Color    string `json:"color"`
+	Quantity int    `json:"quantity"`
+}
+
+type SuggestResponse struct {
+	Terms    []string            `json:"terms"`
+	Products []ProductSuggestion `json:"products"`
+}
+
+// CategoryCountItem traz o total de produtos ativos da categoria somado ao das subcategorias.
 type CategoryCountItem struct {
-	Category schemas.Category `json:"category"`
+	ID       uint64           `json:"id"`
+	ParentID *uint64          `json:"parent_id,omitempty"`
+	Name     string           `json:"name"`
+	Category schemas.Category `json:"category"` // slug
 	Count    int64            `json:"count"`
 }
 
@@ -99,18 +251,40 @@ type CategoriesSummaryResponse struct {
 }
 
 type ProductFilter struct {
-	Category *schemas.Category
-	Status   *schemas.ProductStatus
-	Search   *string
-	Active   *bool
+	CategoryIDs []uint64 // categoria e subcategorias
+	Status      *schemas.ProductStatus
+	Search      *string
+	Active      *bool
+	VisibleAt   *time.Time // loja: publicados no instante, respeitando agendamentos
+
+	// Filtros usados pelas coleções
+	ProductIDs      []uint64
+	Tags            []string
+	OnlyPromotional bool
+	MinPrice        *float64
+	MaxPrice        *float64
+	CreatedAfter    *time.Time
+	manualOrder     bool // ordena pela posição de ProductIDs
+
+	// Sort: "rating" (melhor avaliados), "price_asc", "price_desc"; vazio = mais recentes
+	Sort string
+
+	// Trashed lista só a lixeira (deleted_at preenchido), mais recentes primeiro
+	Trashed bool
+
+	// searchExpr é a expressão FULLTEXT (BOOLEAN MODE) resolvida a partir de Search.
+	searchExpr string
 }
 
 func toProductResponse(p schemas.Products) ProductResponse {
-	return ProductResponse{
+	now := time.Now()
+	response := ProductResponse{
 		ID:               p.ID,
 		SKU:              p.SKU,
 		Name:             p.Name,
+		Slug:             productSlug(p),
 		Description:      p.Description,
+		CategoryID:       p.CategoryID,
 		Categorys:        p.Categorys,
 		Size:             p.Size,
 		Color:            p.Color,
@@ -122,38 +296,109 @@ func toProductResponse(p schemas.Products) ProductResponse {
 		MinStock:         p.MinStock,
 		Weight:           p.Weight,
 		Dimensions:       p.Dimensions,
-		Images:           minio.JsonToStringSlice(p.Images),
+		Images:           minio.MediaURLs(minio.JsonToStringSlice(p.Images)),
 		Status:           p.Status,
 		IsActive:         p.IsActive,
 		IsPromotional:    p.IsPromotional,
+		IsBundle:         p.IsBundle,
+		PromotionActive:  catalog.PromotionActive(p, now),
+		EffectivePrice:   catalog.EffectivePrice(p, now),
 		Tags:             p.Tags,
 		SEODescription:   p.SEODescription,
 		SEOKeywords:      p.SEOKeywords,
-		CreatedAt:        p.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:        p.UpdatedAt.Format(time.RFC3339),
+
+		PersonalizationOptions: personalizationOptions(p),
+		SizeChartID:            p.SizeChartID,
+
+		RatingAverage: p.RatingAverage,
+		RatingCount:   p.RatingCount,
+
+		PublishAt:         p.PublishAt,
+		UnpublishAt:       p.UnpublishAt,
+		PromotionStartsAt: p.PromotionStartsAt,
+		PromotionEndsAt:   p.PromotionEndsAt,
+
+		CreatedAt: p.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: p.UpdatedAt.Format(time.RFC3339),
+	}
+	if p.DeletedAt.Valid {
+		deletedAt := p.DeletedAt.Time
+		response.DeletedAt = &deletedAt
 	}
+	return response
 }
 
-func isValidCategory(c schemas.Category) bool {
-	return c == schemas.Masculino || c == schemas.Feminino || c == schemas.Fardamentos
+func personalizationOptions(p schemas.Products) []schemas.PersonalizationField {
+	if p.PersonalizationOptions == nil {
+		return []schemas.PersonalizationField{}
+	}
+	return p.PersonalizationOptions
 }
 
-func isValidStatus(s schemas.ProductStatus) bool {
-	return s == schemas.ProductStatusDraft || s == schemas.ProductStatusPublished
+func productSlug(p schemas.Products) string {
+	if p.Slug == nil {
+		return ""
+	}
+	return *p.Slug
 }
 
-// genderForCategory mantém gênero alinhado à categoria do catálogo.
-func genderForCategory(cat schemas.Category) string {
-	switch cat {
-	case schemas.Masculino:
-		return "M"
-	case schemas.Feminino:
-		return "F"
-	case schemas.Fardamentos:
-		return "U"
-	default:
-		return "U"
+func toPublicProductResponse(p schemas.Products, category *schemas.Categories) PublicProductResponse {
+	seoDescription := p.SEODescription
+	if strings.TrimSpace(seoDescription) == "" {
+		seoDescription = p.Description
+		if r := []rune(seoDescription); len(r) > 160 {
+			seoDescription = strings.TrimSpace(string(r[:157])) + "..."
+		}
+	}
+
+	slug := productSlug(p)
+	now := time.Now()
+	promotionActive := catalog.PromotionActive(p, now)
+	response := PublicProductResponse{
+		ID:              p.ID,
+		Slug:            slug,
+		SKU:             p.SKU,
+		Name:            p.Name,
+		Description:     p.Description,
+		Size:            p.Size,
+		Color:           p.Color,
+		Material:        p.Material,
+		Gender:          p.Gender,
+		Price:           p.Price,
+		IsPromotional:   promotionActive,
+		EffectivePrice:  catalog.EffectivePrice(p, now),
+		RatingAverage:   p.RatingAverage,
+		RatingCount:     p.RatingCount,
+		InStock:         p.StockQuantity > 0,
+		Weight:          p.Weight,
+		Dimensions:      p.Dimensions,
+		Images:          minio.MediaURLs(minio.JsonToStringSlice(p.Images)),
+		Gallery:         []PublicImage{},
+		Tags:            p.Tags,
+		Personalization: p.PersonalizationOptions,
+		SEO: ProductSEO{
+			Title:        p.Name,
+			Description:  seoDescription,
+			Keywords:     p.SEOKeywords,
+			CanonicalURL: catalog.StoreURL(catalog.ProductPath, slug),
+		},
+	}
+	if promotionActive {
+		response.PromotionalPrice = p.PromotionalPrice
+		response.PromotionEndsAt = p.PromotionEndsAt
 	}
+	if category != nil {
+		response.Category = &PublicCategory{
+			ID:   category.ID,
+			Name: category.Name,
+			Slug: category.Slug,
+		}
+	}
+	return response
+}
+
+func isValidStatus(s schemas.ProductStatus) bool {
+	return s == schemas.ProductStatusDraft || s == schemas.ProductStatusPublished
 }
 
 func (req *CreateProductRequest) Validate() error {
@@ -171,8 +416,8 @@ func (req *CreateProductRequest) Validate() error {
 		errs = append(errs, "nome deve ter entre 3 e 255 caracteres")
 	}
 
-	if !isValidCategory(req.Categorys) {
-		errs = append(errs, "categoria deve ser masculino, feminino ou fardamentos")
+	if req.CategoryID == nil && strings.TrimSpace(string(req.Categorys)) == "" {
+		errs = append(errs, "categoria é obrigatória")
 	}
 
 	if strings.TrimSpace(req.Size) == "" {
@@ -183,8 +428,6 @@ func (req *CreateProductRequest) Validate() error {
 		errs = append(errs, "cor é obrigatória")
 	}
 
-	req.Gender = genderForCategory(req.Categorys)
-
 	if req.Price <= 0 {
 		errs = append(errs, "preço deve ser maior que zero")
 	}
@@ -209,12 +452,50 @@ func (req *CreateProductRequest) Validate() error {
 		errs = append(errs, "status deve ser draft ou published")
 	}
 
+	errs = append(errs, scheduleErrors(schemas.Products{
+		PromotionalPrice:  req.PromotionalPrice,
+		PublishAt:         req.PublishAt,
+		UnpublishAt:       req.UnpublishAt,
+		PromotionStartsAt: req.PromotionStartsAt,
+		PromotionEndsAt:   req.PromotionEndsAt,
+	})...)
+
+	if err := catalog.ValidatePersonalizationOptions(req.PersonalizationOptions); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if req.IsBundle {
+		if err := validateBundleItems(req.BundleItems); err != "" {
+			errs = append(errs, err)
+		}
+	} else if len(req.BundleItems) > 0 {
+		errs = append(errs, "bundle_items exige is_bundle")
+	}
+
 	if len(errs) > 0 {
 		return errors.New(strings.Join(errs, "; "))
 	}
 	return nil
 }
 
+// scheduleErrors confere a ordem das janelas de publicação e de promoção. No update
+// recebe o produto salvo com as alterações do request aplicadas.
+func scheduleErrors(p schemas.Products) []string {
+	var errs []string
+	if p.PublishAt != nil && p.UnpublishAt != nil && !p.UnpublishAt.After(*p.PublishAt) {
+		errs = append(errs, "unpublish_at deve ser posterior a publish_at")
+	}
+	if p.PromotionStartsAt != nil || p.PromotionEndsAt != nil {
+		if p.PromotionalPrice == nil || *p.PromotionalPrice <= 0 {
+			errs = append(errs, "janela de promoção exige preço promocional")
+		}
+		if p.PromotionStartsAt != nil && p.PromotionEndsAt != nil && !p.PromotionEndsAt.After(*p.PromotionStartsAt) {
+			errs = append(errs, "promotion_ends_at deve ser posterior a promotion_starts_at")
+		}
+	}
+	return errs
+}
+
 func (req *UpdateProductRequest) Validate() error {
 	var errs []string
 
@@ -234,13 +515,8 @@ func (req *UpdateProductRequest) Validate() error {
 		}
 	}
 
-	if req.Categorys != nil && !isValidCategory(*req.Categorys) {
-		errs = append(errs, "categoria deve ser masculino, feminino ou fardamentos")
-	}
-
-	if req.Categorys != nil {
-		g := genderForCategory(*req.Categorys)
-		req.Gender = &g
+	if req.Categorys != nil && strings.TrimSpace(string(*req.Categorys)) == "" {
+		errs = append(errs, "categoria não pode ser vazia")
 	}
 
 	if req.Price != nil && *req.Price <= 0 {
@@ -267,6 +543,301 @@ func (req *UpdateProductRequest) Validate() error {
 		errs = append(errs, "status deve ser draft ou published")
 	}
 
+	for name, value := range map[string]*string{
+		"publish_at":          req.PublishAt,
+		"unpublish_at":        req.UnpublishAt,
+		"promotion_starts_at": req.PromotionStartsAt,
+		"promotion_ends_at":   req.PromotionEndsAt,
+	} {
+		if _, err := parseScheduleTime(value); err != nil {
+			errs = append(errs, name+" deve estar no formato RFC3339 (ex.: 2025-08-01T00:00:00-03:00)")
+		}
+	}
+	// Ordem das janelas enviadas juntas; o handler confere também contra o produto salvo
+	publishAt, _ := parseScheduleTime(req.PublishAt)
+	unpublishAt, _ := parseScheduleTime(req.UnpublishAt)
+	if publishAt != nil && unpublishAt != nil && !unpublishAt.After(*publishAt) {
+		errs = append(errs, "unpublish_at deve ser posterior a publish_at")
+	}
+	startsAt, _ := parseScheduleTime(req.PromotionStartsAt)
+	endsAt, _ := parseScheduleTime(req.PromotionEndsAt)
+	if startsAt != nil && endsAt != nil && !endsAt.After(*startsAt) {
+		errs = append(errs, "promotion_ends_at deve ser posterior a promotion_starts_at")
+	}
+	if (startsAt != nil || endsAt != nil) && req.PromotionalPrice != nil && *req.PromotionalPrice <= 0 {
+		errs = append(errs, "janela de promoção exige preço promocional")
+	}
+
+	if req.PersonalizationOptions != nil {
+		if err := catalog.ValidatePersonalizationOptions(*req.PersonalizationOptions); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// parseScheduleTime interpreta um agendamento do UpdateProductRequest:
+// nil = não alterar, "" = remover, RFC3339 = novo horário.
+func parseScheduleTime(value *string) (*time.Time, error) {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(*value))
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+type RevisionResponse struct {
+	ID        uint64                         `json:"id"`
+	ProductID uint64                         `json:"product_id"`
+	Action    schemas.RevisionAction         `json:"action"`
+	UserID    *uint64                        `json:"user_id,omitempty"`
+	UserName  string                         `json:"user_name"`
+	Changes   map[string]catalog.FieldChange `json:"changes"`
+	Snapshot  *ProductResponse               `json:"snapshot,omitempty"`
+	CreatedAt string                         `json:"created_at"`
+}
+
+type RevisionListResponse struct {
+	Revisions []RevisionResponse `json:"revisions"`
+	Total     int64              `json:"total"`
+	Page      int                `json:"page"`
+	Limit     int                `json:"limit"`
+	Pages     int                `json:"pages"`
+}
+
+func toRevisionResponse(r schemas.ProductRevisions, withSnapshot bool) RevisionResponse {
+	response := RevisionResponse{
+		ID:        r.ID,
+		ProductID: r.ProductID,
+		Action:    r.Action,
+		UserID:    r.UserID,
+		UserName:  r.UserName,
+		Changes:   map[string]catalog.FieldChange{},
+		CreatedAt: r.CreatedAt.Format(time.RFC3339),
+	}
+	_ = json.Unmarshal(r.Changes, &response.Changes)
+
+	if withSnapshot {
+		var snapshot schemas.Products
+		if err := json.Unmarshal(r.Snapshot, &snapshot); err == nil {
+			p := toProductResponse(snapshot)
+			response.Snapshot = &p
+		}
+	}
+	return response
+}
+
+func (req *SetBundleRequest) Validate() error {
+	if err := validateBundleItems(req.Items); err != "" {
+		return errors.New(err)
+	}
+	return nil
+}
+
+func validateBundleItems(items []BundleItemRequest) string {
+	if len(items) == 0 {
+		return "kit deve ter ao menos um componente"
+	}
+	seen := make(map[uint64]bool, len(items))
+	for _, it := range items {
+		if it.ProductID == 0 {
+			return "product_id do componente é obrigatório"
+		}
+		if it.Quantity <= 0 {
+			return "quantidade do componente deve ser maior que zero"
+		}
+		if seen[it.ProductID] {
+			return "componente repetido no kit"
+		}
+		seen[it.ProductID] = true
+	}
+	return ""
+}
+
+func toBundleResponse(bundle schemas.Products, components []catalog.BundleComponent) BundleResponse {
+	response := BundleResponse{
+		BundleID:    bundle.ID,
+		Available:   catalog.BundleAvailability(components),
+		BundlePrice: catalog.EffectivePrice(bundle, time.Now()),
+		Components:  make([]BundleComponentResponse, 0, len(components)),
+	}
+	for _, comp := range components {
+		response.ComponentsPrice += comp.Product.Price * float64(comp.Item.Quantity)
+		response.Components = append(response.Components, BundleComponentResponse{
+			ProductID:     comp.Item.ComponentID,
+			SKU:           comp.Product.SKU,
+			Name:          comp.Product.Name,
+			Quantity:      comp.Item.Quantity,
+			StockQuantity: comp.Product.StockQuantity,
+			IsActive:      comp.Product.IsActive,
+			Price:         comp.Product.Price,
+		})
+	}
+	if response.ComponentsPrice > response.BundlePrice {
+		response.Savings = response.ComponentsPrice - response.BundlePrice
+	}
+	return response
+}
+
+// SetRelatedRequest substitui os relacionados manuais, na ordem de exibição.
+type SetRelatedRequest struct {
+	ProductIDs []uint64 `json:"product_ids"`
+}
+
+type RelatedItemResponse struct {
+	ProductID uint64               `json:"product_id"`
+	SKU       string               `json:"sku"`
+	Name      string               `json:"name"`
+	Slug      string               `json:"slug"`
+	IsActive  bool                 `json:"is_active"`
+	Type      schemas.RelationType `json:"type"`
+	Position  int                  `json:"position"`
+	Score     int                  `json:"score,omitempty"` // pedidos em comum (comprados juntos)
+}
+
+func (req *SetRelatedRequest) Validate(productID uint64) error {
+	if len(req.ProductIDs) > maxManualRelated {
+		return fmt.Errorf("máximo de %d produtos relacionados", maxManualRelated)
+	}
+	seen := make(map[uint64]bool, len(req.ProductIDs))
+	for _, id := range req.ProductIDs {
+		if id == 0 {
+			return errors.New("product_ids não pode conter 0")
+		}
+		if id == productID {
+			return errors.New("produto não pode ser relacionado a ele mesmo")
+		}
+		if seen[id] {
+			return errors.New("produto repetido na lista")
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// PricePoint é uma mudança de preço, pronta para o gráfico do admin.
+type PricePoint struct {
+	At               time.Time           `json:"at"`
+	Price            float64             `json:"price"`
+	PromotionalPrice *float64            `json:"promotional_price,omitempty"`
+	PromotionActive  bool                `json:"promotion_active"`
+	EffectivePrice   float64             `json:"effective_price"`
+	Source           schemas.PriceSource `json:"source"`
+}
+
+type PriceHistoryResponse struct {
+	ProductID      uint64       `json:"product_id"`
+	Days           int          `json:"days"`
+	Price          float64      `json:"price"`
+	EffectivePrice float64      `json:"effective_price"`
+	LowestPrice30d *float64     `json:"lowest_price_30d,omitempty"`
+	Points         []PricePoint `json:"points"`
+}
+
+type ProductImageResponse struct {
+	ID       uint64 `json:"id"`
+	URL      string `json:"url"`
+	Position int    `json:"position"`
+	IsCover  bool   `json:"is_cover"`
+	AltText  string `json:"alt_text"`
+	Color    string `json:"color,omitempty"`
+
+	Renditions map[string]schemas.ImageRendition `json:"renditions,omitempty"` // thumb, card, zoom
+}
+
+// PublicImage é a imagem da galeria na loja; a vitrine troca as fotos pela cor escolhida.
+type PublicImage struct {
+	URL     string `json:"url"`
+	Alt     string `json:"alt"`
+	Color   string `json:"color,omitempty"`
+	IsCover bool   `json:"is_cover"`
+
+	Renditions map[string]schemas.ImageRendition `json:"renditions,omitempty"` // thumb, card, zoom
+}
+
+// ReorderImagesRequest traz todas as imagens do produto na nova ordem.
+type ReorderImagesRequest struct {
+	ImageIDs []uint64 `json:"image_ids"`
+}
+
+type UpdateImageRequest struct {
+	AltText *string `json:"alt_text,omitempty"`
+	Color   *string `json:"color,omitempty"` // "" desassocia da cor
+	IsCover *bool   `json:"is_cover,omitempty"`
+}
+
+func toImageResponses(images []schemas.ProductImages) []ProductImageResponse {
+	responses := make([]ProductImageResponse, 0, len(images))
+	for _, img := range images {
+		responses = append(responses, ProductImageResponse{
+			ID:         img.ID,
+			URL:        minio.MediaURL(img.URL),
+			Position:   img.Position,
+			IsCover:    img.IsCover,
+			AltText:    img.AltText,
+			Color:      img.Color,
+			Renditions: minio.RenditionURLs(img.Renditions),
+		})
+	}
+	return responses
+}
+
+// toPublicGallery usa o nome do produto como alt quando a imagem não tem texto próprio.
+func toPublicGallery(images []schemas.ProductImages, productName string) []PublicImage {
+	gallery := make([]PublicImage, 0, len(images))
+	for _, img := range images {
+		alt := img.AltText
+		if alt == "" {
+			alt = productName
+		}
+		gallery = append(gallery, PublicImage{
+			URL:        minio.MediaURL(img.URL),
+			Alt:        alt,
+			Color:      img.Color,
+			IsCover:    img.IsCover,
+			Renditions: minio.RenditionURLs(img.Renditions),
+		})
+	}
+	return gallery
+}
+
+func (req *ReorderImagesRequest) Validate() error {
+	if len(req.ImageIDs) == 0 {
+		return errors.New("image_ids é obrigatório")
+	}
+	seen := make(map[uint64]bool, len(req.ImageIDs))
+	for _, id := range req.ImageIDs {
+		if seen[id] {
+			return errors.New("imagem repetida na lista")
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+func (req *UpdateImageRequest) Validate() error {
+	var errs []string
+
+	if req.AltText == nil && req.Color == nil && req.IsCover == nil {
+		errs = append(errs, "nenhum campo para atualizar")
+	}
+	if req.AltText != nil && len(strings.TrimSpace(*req.AltText)) > 255 {
+		errs = append(errs, "alt_text deve ter no máximo 255 caracteres")
+	}
+	if req.Color != nil && len(strings.TrimSpace(*req.Color)) > 50 {
+		errs = append(errs, "cor deve ter no máximo 50 caracteres")
+	}
+	if req.IsCover != nil && !*req.IsCover {
+		errs = append(errs, "para trocar a capa, marque outra imagem com is_cover=true")
+	}
+
 	if len(errs) > 0 {
 		return errors.New(strings.Join(errs, "; "))
 	}
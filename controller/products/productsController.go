@@ -3,11 +3,17 @@ package controller
 import (
 	"backend_camisaria_store/config"
 	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/catalog"
+	"backend_camisaria_store/service/search"
+	"encoding/json"
+	"errors"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func parsePagination(c *fiber.Ctx) (page, limit, offset int) {
@@ -27,8 +33,26 @@ func parsePagination(c *fiber.Ctx) (page, limit, offset int) {
 }
 
 func applyProductFilters(query *gorm.DB, filters ProductFilter) *gorm.DB {
-	if filters.Category != nil {
-		query = query.Where("LOWER(categorys) = ?", strings.ToLower(string(*filters.Category)))
+	if len(filters.CategoryIDs) > 0 {
+		query = query.Where("category_id IN ?", filters.CategoryIDs)
+	}
+	if len(filters.ProductIDs) > 0 {
+		query = query.Where("id IN ?", filters.ProductIDs)
+	}
+	for _, tag := range filters.Tags {
+		query = query.Where("tags LIKE ?", "%"+strings.TrimSpace(tag)+"%")
+	}
+	if filters.OnlyPromotional {
+		query = query.Where("is_promotional = ?", true)
+	}
+	if filters.MinPrice != nil {
+		query = query.Where("price >= ?", *filters.MinPrice)
+	}
+	if filters.MaxPrice != nil {
+		query = query.Where("price <= ?", *filters.MaxPrice)
+	}
+	if filters.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *filters.CreatedAfter)
 	}
 	if filters.Status != nil {
 		query = query.Where("status = ?", *filters.Status)
@@ -36,18 +60,73 @@ func applyProductFilters(query *gorm.DB, filters ProductFilter) *gorm.DB {
 	if filters.Active != nil {
 		query = query.Where("is_active = ?", *filters.Active)
 	}
-	if filters.Search != nil && strings.TrimSpace(*filters.Search) != "" {
+	if filters.VisibleAt != nil {
+		query = query.Scopes(catalog.VisibleAt(*filters.VisibleAt))
+	}
+	if filters.searchExpr != "" {
+		query = query.Where("(MATCH(search_text) AGAINST (? IN BOOLEAN MODE) OR sku = ?)",
+			filters.searchExpr, strings.TrimSpace(*filters.Search))
+	} else if filters.Search != nil && strings.TrimSpace(*filters.Search) != "" {
+		// Termos curtos demais para o índice FULLTEXT (ex.: "P", "GG") caem no LIKE
 		term := "%" + strings.TrimSpace(*filters.Search) + "%"
 		query = query.Where("name LIKE ? OR description LIKE ? OR sku LIKE ?", term, term, term)
 	}
 	return query
 }
 
+// resolveSearch monta a expressão FULLTEXT exigindo todos os termos; se nada for
+// encontrado, corrige erros de digitação pelo vocabulário do catálogo e relaxa para
+// qualquer termo. Retorna a busca corrigida quando houve correção.
+func resolveSearch(filters *ProductFilter) (string, error) {
+	if filters.Search == nil {
+		return "", nil
+	}
+	terms := search.Terms(*filters.Search)
+	if len(terms) == 0 {
+		return "", nil
+	}
+
+	filters.searchExpr = search.BooleanQuery(terms, true)
+
+	var count int64
+	query := applyProductFilters(productsScope(*filters), *filters)
+	if err := query.Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "", nil
+	}
+
+	corrected, changed, err := search.Correct(config.DB, terms)
+	if err != nil {
+		return "", err
+	}
+	filters.searchExpr = search.BooleanQuery(corrected, false)
+	if !changed {
+		return "", nil
+	}
+	return strings.Join(corrected, " "), nil
+}
+
+// productsScope é a consulta base da listagem: produtos ativos ou, com Trashed, só a lixeira.
+func productsScope(filters ProductFilter) *gorm.DB {
+	if filters.Trashed {
+		return config.DB.Unscoped().Model(&schemas.Products{}).Where("deleted_at IS NOT NULL")
+	}
+	return config.DB.Model(&schemas.Products{})
+}
+
 func listProductsWithFilters(c *fiber.Ctx, filters ProductFilter) error {
 	page, limit, offset := parsePagination(c)
 
-	query := config.DB.Model(&schemas.Products{})
-	query = applyProductFilters(query, filters)
+	suggestion, err := resolveSearch(&filters)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao processar busca",
+		})
+	}
+
+	query := applyProductFilters(productsScope(filters), filters)
 
 	var total int64
 	if err := query.Count(&total).Error; err != nil {
@@ -56,6 +135,31 @@ func listProductsWithFilters(c *fiber.Ctx, filters ProductFilter) error {
 		})
 	}
 
+	if filters.Trashed {
+		query = query.Order("deleted_at DESC")
+	}
+	switch filters.Sort {
+	case "rating":
+		query = query.Order("rating_average DESC").Order("rating_count DESC")
+	case "price_asc":
+		query = query.Order("price ASC")
+	case "price_desc":
+		query = query.Order("price DESC")
+	}
+
+	if filters.searchExpr != "" {
+		query = query.Order(clause.Expr{
+			SQL:  "MATCH(search_text) AGAINST (? IN BOOLEAN MODE) DESC",
+			Vars: []interface{}{filters.searchExpr},
+		})
+	} else if filters.manualOrder && len(filters.ProductIDs) > 0 {
+		query = query.Order(clause.Expr{
+			SQL:                "FIELD(id, ?)",
+			Vars:               []interface{}{filters.ProductIDs},
+			WithoutParentheses: true,
+		})
+	}
+
 	var products []schemas.Products
 	if err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&products).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -63,9 +167,33 @@ func listProductsWithFilters(c *fiber.Ctx, filters ProductFilter) error {
 		})
 	}
 
+	if err := catalog.ApplyBundleStock(config.DB, products); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao calcular estoque dos kits",
+		})
+	}
+
+	prices, err := lowestPrices(products...)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao calcular menor preço dos produtos",
+		})
+	}
+
+	// Galeria com as versões redimensionadas (a grade usa o thumb/card)
+	galleries, err := catalog.ProductGalleries(config.DB, productIDs(products))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar imagens dos produtos",
+		})
+	}
+
 	responses := make([]ProductResponse, 0, len(products))
 	for _, p := range products {
-		responses = append(responses, toProductResponse(p))
+		response := toProductResponse(p)
+		response.LowestPrice30d = lowestPriceOf(prices, p.ID)
+		response.Gallery = toImageResponses(galleries[p.ID])
+		responses = append(responses, response)
 	}
 
 	totalPages := int((total + int64(limit) - 1) / int64(limit))
@@ -74,27 +202,37 @@ func listProductsWithFilters(c *fiber.Ctx, filters ProductFilter) error {
 	}
 
 	return c.Status(fiber.StatusOK).JSON(ProductListResponse{
-		Products: responses,
-		Total:    total,
-		Page:     page,
-		Limit:    limit,
-		Pages:    totalPages,
+		Products:   responses,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		Pages:      totalPages,
+		Suggestion: suggestion,
 	})
 }
 
 // ListCategoriesSummary — totais por categoria para o aside do admin.
+// O total de cada categoria inclui os produtos das subcategorias.
 func ListCategoriesSummary(c *fiber.Ctx) error {
+	categories, err := catalog.LoadCategories(config.DB, false)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao buscar categorias",
+			"details": err.Error(),
+		})
+	}
+
 	type row struct {
-		Category string `gorm:"column:category"`
-		Count    int64  `gorm:"column:count"`
+		CategoryID uint64 `gorm:"column:category_id"`
+		Count      int64  `gorm:"column:count"`
 	}
 	var rows []row
 
-	err := config.DB.Raw(`
-		SELECT categorys AS category, COUNT(*) AS count
+	err = config.DB.Raw(`
+		SELECT category_id, COUNT(*) AS count
 		FROM products
-		WHERE is_active = ?
-		GROUP BY categorys
+		WHERE is_active = ? AND category_id IS NOT NULL AND deleted_at IS NULL
+		GROUP BY category_id
 	`, true).Scan(&rows).Error
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -103,43 +241,49 @@ func ListCategoriesSummary(c *fiber.Ctx) error {
 		})
 	}
 
-	counts := map[schemas.Category]int64{
-		schemas.Masculino:   0,
-		schemas.Feminino:    0,
-		schemas.Fardamentos: 0,
-	}
+	direct := make(map[uint64]int64, len(rows))
 	var total int64
 	for _, r := range rows {
-		cat := schemas.Category(strings.ToLower(strings.TrimSpace(r.Category)))
-		if isValidCategory(cat) {
-			counts[cat] += r.Count
-			total += r.Count
-		}
+		direct[r.CategoryID] = r.Count
+		total += r.Count
 	}
 
-	categories := []CategoryCountItem{
-		{Category: schemas.Masculino, Count: counts[schemas.Masculino]},
-		{Category: schemas.Feminino, Count: counts[schemas.Feminino]},
-		{Category: schemas.Fardamentos, Count: counts[schemas.Fardamentos]},
+	items := make([]CategoryCountItem, 0, len(categories))
+	for _, cat := range categories {
+		var count int64
+		for _, id := range catalog.DescendantIDs(categories, cat.ID) {
+			count += direct[id]
+		}
+		items = append(items, CategoryCountItem{
+			ID:       cat.ID,
+			ParentID: cat.ParentID,
+			Name:     cat.Name,
+			Category: schemas.Category(cat.Slug),
+			Count:    count,
+		})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(CategoriesSummaryResponse{
-		Categories: categories,
+		Categories: items,
 		Total:      total,
 	})
 }
 
-// ListProductsByCategory — lista paginada filtrada pela categoria (path).
+// ListProductsByCategory — lista paginada filtrada pela categoria (slug ou ID no path), incluindo subcategorias.
 func ListProductsByCategory(c *fiber.Ctx) error {
-	categoryParam := c.Params("category")
-	cat := schemas.Category(categoryParam)
-	if !isValidCategory(cat) {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Categoria inválida. Use: masculino, feminino ou fardamentos",
+	_, categoryIDs, err := catalog.CategoryWithDescendants(config.DB, c.Params("category"))
+	if err != nil {
+		if errors.Is(err, catalog.ErrCategoryNotFound) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Categoria inválida",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar categoria",
 		})
 	}
 
-	filters := ProductFilter{Category: &cat}
+	filters := ProductFilter{CategoryIDs: categoryIDs}
 
 	if status := c.Query("status"); status != "" {
 		st := schemas.ProductStatus(status)
@@ -162,8 +306,18 @@ func ListProducts(c *fiber.Ctx) error {
 	filters := ProductFilter{}
 
 	if category := c.Query("category"); category != "" {
-		cat := schemas.Category(category)
-		filters.Category = &cat
+		_, categoryIDs, err := catalog.CategoryWithDescendants(config.DB, category)
+		if err != nil {
+			if errors.Is(err, catalog.ErrCategoryNotFound) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Categoria inválida",
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro ao buscar categoria",
+			})
+		}
+		filters.CategoryIDs = categoryIDs
 	}
 	if status := c.Query("status"); status != "" {
 		st := schemas.ProductStatus(status)
@@ -176,7 +330,7 @@ func ListProducts(c *fiber.Ctx) error {
 		active := activeStr == "true" || activeStr == "1"
 		filters.Active = &active
 	} else {
-		// Admin vê apenas registros ativos por padrão (soft delete mantém is_active=false)
+		// Admin vê apenas registros ativos por padrão; excluídos ficam na lixeira (ListTrashedProducts)
 		active := true
 		filters.Active = &active
 	}
@@ -184,21 +338,78 @@ func ListProducts(c *fiber.Ctx) error {
 	return listProductsWithFilters(c, filters)
 }
 
-// ListPublishedProducts — loja pública: apenas publicados e ativos.
+// ListPublishedProducts — loja pública: apenas publicados e ativos, respeitando publish_at/unpublish_at.
 func ListPublishedProducts(c *fiber.Ctx) error {
-	published := schemas.ProductStatusPublished
-	active := true
-	filters := ProductFilter{
-		Status: &published,
-		Active: &active,
-	}
+	now := time.Now()
+	filters := ProductFilter{VisibleAt: &now}
 
 	if category := c.Query("category"); category != "" {
-		cat := schemas.Category(category)
-		if isValidCategory(cat) {
-			filters.Category = &cat
+		if cat, categoryIDs, err := catalog.CategoryWithDescendants(config.DB, category); err == nil && cat.IsActive {
+			filters.CategoryIDs = categoryIDs
+		}
+	}
+	if search := c.Query("search"); search != "" {
+		filters.Search = &search
+	}
+	filters.Sort = c.Query("sort")
+
+	return listProductsWithFilters(c, filters)
+}
+
+// ListCollectionProducts — loja pública: produtos publicados de uma coleção (manual ou por regra).
+func ListCollectionProducts(c *fiber.Ctx) error {
+	var collection schemas.Collections
+	if err := config.DB.Where("slug = ? AND is_active = ?", c.Params("slug"), true).
+		First(&collection).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Coleção não encontrada"})
+	}
+
+	now := time.Now()
+	filters := ProductFilter{VisibleAt: &now}
+
+	switch collection.Type {
+	case schemas.CollectionRule:
+		rules := collection.Rules
+		if rules.CategoryID != nil {
+			categories, err := catalog.LoadCategories(config.DB, false)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Erro ao buscar categorias",
+				})
+			}
+			filters.CategoryIDs = catalog.DescendantIDs(categories, *rules.CategoryID)
+		}
+		filters.Tags = rules.Tags
+		filters.OnlyPromotional = rules.OnlyPromotional
+		filters.MinPrice = rules.MinPrice
+		filters.MaxPrice = rules.MaxPrice
+		if rules.CreatedWithinDays > 0 {
+			after := time.Now().AddDate(0, 0, -rules.CreatedWithinDays)
+			filters.CreatedAfter = &after
+		}
+	default:
+		var productIDs []uint64
+		if err := config.DB.Model(&schemas.CollectionProducts{}).
+			Where("collection_id = ?", collection.ID).
+			Order("position ASC, id ASC").
+			Pluck("product_id", &productIDs).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro ao buscar produtos da coleção",
+			})
+		}
+		if len(productIDs) == 0 {
+			_, limit, _ := parsePagination(c)
+			return c.Status(fiber.StatusOK).JSON(ProductListResponse{
+				Products: []ProductResponse{},
+				Page:     1,
+				Limit:    limit,
+				Pages:    1,
+			})
 		}
+		filters.ProductIDs = productIDs
+		filters.manualOrder = true
 	}
+
 	if search := c.Query("search"); search != "" {
 		filters.Search = &search
 	}
@@ -206,6 +417,84 @@ func ListPublishedProducts(c *fiber.Ctx) error {
 	return listProductsWithFilters(c, filters)
 }
 
+// resolveProductCategory encontra a categoria informada por ID ou slug e o gênero herdado dela.
+func resolveProductCategory(categoryID *uint64, slug *schemas.Category) (*schemas.Categories, string, error) {
+	ref := ""
+	if categoryID != nil {
+		ref = strconv.FormatUint(*categoryID, 10)
+	} else if slug != nil {
+		ref = string(*slug)
+	}
+
+	category, err := catalog.FindCategory(config.DB, ref)
+	if err != nil {
+		return nil, "", err
+	}
+	gender, err := catalog.ResolveGender(config.DB, category)
+	if err != nil {
+		return nil, "", err
+	}
+	return category, gender, nil
+}
+
+// SuggestProducts — autocomplete da loja: termos do catálogo e produtos publicados para o texto digitado.
+func SuggestProducts(c *fiber.Ctx) error {
+	q := c.Query("q")
+	terms := search.Terms(q)
+	if len(terms) == 0 {
+		return c.Status(fiber.StatusOK).JSON(SuggestResponse{
+			Terms:    []string{},
+			Products: []ProductSuggestion{},
+		})
+	}
+
+	// Completa apenas a última palavra, mantendo as anteriores como digitadas
+	last := terms[len(terms)-1]
+	prefix := strings.Join(terms[:len(terms)-1], " ")
+	completions, err := search.CompleteTerm(config.DB, last, 5)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar sugestões",
+		})
+	}
+	suggestedTerms := make([]string, 0, len(completions))
+	for _, w := range completions {
+		suggestedTerms = append(suggestedTerms, strings.TrimSpace(prefix+" "+w))
+	}
+
+	var products []schemas.Products
+	expr := search.BooleanQuery(terms, true)
+	if err := config.DB.Select("id", "name", "slug", "sku", "price").
+		Scopes(catalog.VisibleAt(time.Now())).
+		Where("MATCH(search_text) AGAINST (? IN BOOLEAN MODE)", expr).
+		Order(clause.Expr{
+			SQL:  "MATCH(search_text) AGAINST (? IN BOOLEAN MODE) DESC",
+			Vars: []interface{}{expr},
+		}).
+		Limit(8).
+		Find(&products).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar sugestões",
+		})
+	}
+
+	suggestions := make([]ProductSuggestion, 0, len(products))
+	for _, p := range products {
+		suggestions = append(suggestions, ProductSuggestion{
+			ID:    p.ID,
+			Name:  p.Name,
+			Slug:  productSlug(p),
+			SKU:   p.SKU,
+			Price: p.Price,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(SuggestResponse{
+		Terms:    suggestedTerms,
+		Products: suggestions,
+	})
+}
+
 func CreateProduct(c *fiber.Ctx) error {
 	req := CreateProductRequest{}
 	if err := c.BodyParser(&req); err != nil {
@@ -222,6 +511,58 @@ func CreateProduct(c *fiber.Ctx) error {
 		})
 	}
 
+	if req.SizeChartID != nil {
+		exists, err := sizeChartExists(*req.SizeChartID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Erro ao verificar tabela de medidas",
+				"details": err.Error(),
+			})
+		}
+		if !exists {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Dados inválidos",
+				"details": "tabela de medidas não encontrada",
+			})
+		}
+	}
+
+	category, gender, err := resolveProductCategory(req.CategoryID, &req.Categorys)
+	if err != nil {
+		if errors.Is(err, catalog.ErrCategoryNotFound) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Dados inválidos",
+				"details": "categoria não encontrada",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao buscar categoria",
+			"details": err.Error(),
+		})
+	}
+
+	owner, err := skuOwner(strings.TrimSpace(req.SKU), 0)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao verificar SKU",
+		})
+	}
+	if owner != nil {
+		return skuConflict(c, owner)
+	}
+
+	slugSource := req.Slug
+	if strings.TrimSpace(slugSource) == "" {
+		slugSource = req.Name
+	}
+	slug, err := catalog.UniqueProductSlug(config.DB, slugSource, 0)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao gerar slug do produto",
+			"details": err.Error(),
+		})
+	}
+
 	status := req.Status
 	if status == "" {
 		status = schemas.ProductStatusDraft
@@ -229,18 +570,25 @@ func CreateProduct(c *fiber.Ctx) error {
 
 	isPromotional := req.PromotionalPrice != nil && *req.PromotionalPrice > 0
 
+	stock := req.StockQuantity
+	if req.IsBundle {
+		stock = 0 // kits não têm estoque próprio
+	}
+
 	product := schemas.Products{
 		SKU:              strings.TrimSpace(req.SKU),
 		Name:             strings.TrimSpace(req.Name),
+		Slug:             &slug,
 		Description:      req.Description,
-		Categorys:        req.Categorys,
+		CategoryID:       &category.ID,
+		Categorys:        schemas.Category(category.Slug),
 		Size:             req.Size,
 		Color:            req.Color,
 		Material:         req.Material,
-		Gender:           req.Gender,
+		Gender:           gender,
 		Price:            req.Price,
 		PromotionalPrice: req.PromotionalPrice,
-		StockQuantity:    req.StockQuantity,
+		StockQuantity:    stock,
 		MinStock:         req.MinStock,
 		Weight:           req.Weight,
 		Dimensions:       req.Dimensions,
@@ -250,15 +598,53 @@ func CreateProduct(c *fiber.Ctx) error {
 		Status:           status,
 		IsActive:         true,
 		IsPromotional:    isPromotional,
+		IsBundle:         req.IsBundle,
+
+		PersonalizationOptions: req.PersonalizationOptions,
+		SizeChartID:            req.SizeChartID,
+
+		PublishAt:         req.PublishAt,
+		UnpublishAt:       req.UnpublishAt,
+		PromotionStartsAt: req.PromotionStartsAt,
+		PromotionEndsAt:   req.PromotionEndsAt,
 	}
+	// Com janela de promoção o flag acompanha a janela (ver catalog.ApplySchedules)
+	if product.PromotionStartsAt != nil || product.PromotionEndsAt != nil {
+		product.IsPromotional = catalog.PromotionActive(product, time.Now())
+	}
+	product.SearchText = search.ProductDocument(product)
 
-	if err := config.DB.Create(&product).Error; err != nil {
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&product).Error; err != nil {
+			return err
+		}
+		if product.IsBundle {
+			if err := replaceBundleItems(tx, product.ID, req.BundleItems); err != nil {
+				return err
+			}
+		}
+		if err := catalog.RecordProductRevision(tx, schemas.RevisionCreate, nil, &product, actorFromCtx(c)); err != nil {
+			return err
+		}
+		return catalog.RecordPriceChange(tx, nil, product, schemas.PriceSourceCreate, actorFromCtx(c), time.Now())
+	})
+	if err != nil {
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			return c.Status(fiberErr.Code).JSON(fiber.Map{
+				"error":   "Dados inválidos",
+				"details": fiberErr.Message,
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   "Erro ao salvar produto",
 			"details": err.Error(),
 		})
 	}
 
+	search.InvalidateVocabulary()
+	_ = withBundleStock(&product)
+
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message": "Produto criado com sucesso",
 		"product": toProductResponse(product),
@@ -276,8 +662,31 @@ func GetProduct(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produto não encontrado"})
 	}
 
+	if err := withBundleStock(&product); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao calcular estoque do kit",
+		})
+	}
+
+	prices, err := lowestPrices(product)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao calcular menor preço do produto",
+		})
+	}
+
+	gallery, err := catalog.ProductGallery(config.DB, product.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar imagens do produto",
+		})
+	}
+
+	response := toProductResponse(product)
+	response.LowestPrice30d = lowestPriceOf(prices, product.ID)
+	response.Gallery = toImageResponses(gallery)
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"product": toProductResponse(product),
+		"product": response,
 	})
 }
 
@@ -310,7 +719,17 @@ func UpdateProduct(c *fiber.Ctx) error {
 	updates := make(map[string]interface{})
 
 	if req.SKU != nil {
-		updates["sku"] = strings.TrimSpace(*req.SKU)
+		sku := strings.TrimSpace(*req.SKU)
+		owner, err := skuOwner(sku, product.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro ao verificar SKU",
+			})
+		}
+		if owner != nil {
+			return skuConflict(c, owner)
+		}
+		updates["sku"] = sku
 	}
 	if req.Name != nil {
 		updates["name"] = strings.TrimSpace(*req.Name)
@@ -318,8 +737,23 @@ func UpdateProduct(c *fiber.Ctx) error {
 	if req.Description != nil {
 		updates["description"] = *req.Description
 	}
-	if req.Categorys != nil {
-		updates["categorys"] = *req.Categorys
+	if req.CategoryID != nil || req.Categorys != nil {
+		category, gender, err := resolveProductCategory(req.CategoryID, req.Categorys)
+		if err != nil {
+			if errors.Is(err, catalog.ErrCategoryNotFound) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error":   "Dados inválidos",
+					"details": "categoria não encontrada",
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Erro ao buscar categoria",
+				"details": err.Error(),
+			})
+		}
+		updates["category_id"] = category.ID
+		updates["categorys"] = category.Slug
+		req.Gender = &gender
 	}
 	if req.Size != nil {
 		updates["size"] = *req.Size
@@ -338,13 +772,14 @@ func UpdateProduct(c *fiber.Ctx) error {
 	}
 	if req.PromotionalPrice != nil {
 		updates["promotional_price"] = req.PromotionalPrice
-		if *req.PromotionalPrice > 0 {
-			updates["is_promotional"] = true
-		} else {
-			updates["is_promotional"] = false
-		}
 	}
 	if req.StockQuantity != nil {
+		if product.IsBundle {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Dados inválidos",
+				"details": "o estoque de um kit é calculado pelos componentes",
+			})
+		}
 		updates["stock_quantity"] = *req.StockQuantity
 	}
 	if req.MinStock != nil {
@@ -359,9 +794,6 @@ func UpdateProduct(c *fiber.Ctx) error {
 	if req.IsActive != nil {
 		updates["is_active"] = *req.IsActive
 	}
-	if req.IsPromotional != nil {
-		updates["is_promotional"] = *req.IsPromotional
-	}
 	if req.Status != nil {
 		updates["status"] = *req.Status
 	}
@@ -374,25 +806,110 @@ func UpdateProduct(c *fiber.Ctx) error {
 	if req.SEOKeywords != nil {
 		updates["seo_keywords"] = *req.SEOKeywords
 	}
+	if req.SizeChartID != nil {
+		if *req.SizeChartID == 0 {
+			updates["size_chart_id"] = nil
+		} else {
+			exists, err := sizeChartExists(*req.SizeChartID)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error":   "Erro ao verificar tabela de medidas",
+					"details": err.Error(),
+				})
+			}
+			if !exists {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error":   "Dados inválidos",
+					"details": "tabela de medidas não encontrada",
+				})
+			}
+			updates["size_chart_id"] = *req.SizeChartID
+		}
+	}
+	if req.PersonalizationOptions != nil {
+		// Coluna com serializer: o valor precisa passar pelo JSON explicitamente em updates por map
+		options, _ := json.Marshal(*req.PersonalizationOptions)
+		updates["personalization_options"] = string(options)
+	}
+
+	schedules := map[string]*string{
+		"publish_at":          req.PublishAt,
+		"unpublish_at":        req.UnpublishAt,
+		"promotion_starts_at": req.PromotionStartsAt,
+		"promotion_ends_at":   req.PromotionEndsAt,
+	}
+	merged := product
+	for column, value := range schedules {
+		if value == nil {
+			continue
+		}
+		t, _ := parseScheduleTime(value) // formato já validado
+		updates[column] = t
+		switch column {
+		case "publish_at":
+			merged.PublishAt = t
+		case "unpublish_at":
+			merged.UnpublishAt = t
+		case "promotion_starts_at":
+			merged.PromotionStartsAt = t
+		case "promotion_ends_at":
+			merged.PromotionEndsAt = t
+		}
+	}
 
-	if len(updates) == 0 {
+	// Agendamentos e preço promocional conferidos com o que já está salvo no produto
+	if req.PromotionalPrice != nil {
+		merged.PromotionalPrice = req.PromotionalPrice
+	}
+	if errs := scheduleErrors(merged); len(errs) > 0 {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Nenhum campo para atualizar",
+			"error":   "Dados inválidos",
+			"details": strings.Join(errs, "; "),
 		})
 	}
+	// is_promotional acompanha o preço e a janela (promoção futura ainda não vale)
+	if req.PromotionalPrice != nil || req.IsPromotional != nil ||
+		req.PromotionStartsAt != nil || req.PromotionEndsAt != nil {
+		if req.IsPromotional != nil {
+			merged.IsPromotional = *req.IsPromotional
+		} else if req.PromotionalPrice != nil {
+			merged.IsPromotional = *req.PromotionalPrice > 0
+		}
+		updates["is_promotional"] = catalog.PromotionActive(merged, time.Now())
+	}
 
-	if err := config.DB.Model(&product).Updates(updates).Error; err != nil {
+	// Slug muda só quando enviado explicitamente ou quando o nome muda;
+	// o anterior vai para o histórico para redirecionar links antigos
+	newSlug := ""
+	if req.Slug != nil {
+		newSlug, err = catalog.UniqueProductSlug(config.DB, *req.Slug, product.ID)
+	} else if req.Name != nil && strings.TrimSpace(*req.Name) != product.Name {
+		newSlug, err = catalog.UniqueProductSlug(config.DB, *req.Name, product.ID)
+	}
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "Erro ao atualizar produto",
+			"error":   "Erro ao gerar slug do produto",
 			"details": err.Error(),
 		})
 	}
 
-	if err := config.DB.First(&product, productID).Error; err != nil {
+	if len(updates) == 0 && newSlug == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Nenhum campo para atualizar",
+		})
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		return applyProductChanges(tx, &product, updates, newSlug, schemas.RevisionUpdate, actorFromCtx(c))
+	})
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Erro ao buscar produto atualizado",
+			"error":   "Erro ao atualizar produto",
+			"details": err.Error(),
 		})
 	}
+	search.InvalidateVocabulary()
+	_ = withBundleStock(&product)
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "Produto atualizado com sucesso",
@@ -400,6 +917,81 @@ func UpdateProduct(c *fiber.Ctx) error {
 	})
 }
 
+// GetPublishedProduct — página pública do produto pelo slug. Slugs antigos redirecionam (301)
+// para o atual; produtos não publicados respondem 404.
+func GetPublishedProduct(c *fiber.Ctx) error {
+	slug := strings.ToLower(strings.TrimSpace(c.Params("slug")))
+
+	var product schemas.Products
+	visible := catalog.VisibleAt(time.Now())
+	err := config.DB.Scopes(visible).Where("slug = ?", slug).First(&product).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		var history schemas.ProductSlugHistory
+		if err := config.DB.Where("slug = ?", slug).First(&history).Error; err == nil {
+			if err := config.DB.Scopes(visible).Where("id = ?", history.ProductID).
+				First(&product).Error; err == nil && product.Slug != nil {
+				return c.Redirect("/public/store/products/"+*product.Slug, fiber.StatusMovedPermanently)
+			}
+		}
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produto não encontrado"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar produto",
+		})
+	}
+
+	var category *schemas.Categories
+	if product.CategoryID != nil {
+		var cat schemas.Categories
+		if err := config.DB.First(&cat, *product.CategoryID).Error; err == nil {
+			category = &cat
+		}
+	}
+
+	prices, err := lowestPrices(product)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao calcular menor preço do produto",
+		})
+	}
+
+	gallery, err := catalog.ProductGallery(config.DB, product.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar imagens do produto",
+		})
+	}
+
+	response := toPublicProductResponse(product, category)
+	response.LowestPrice30d = lowestPriceOf(prices, product.ID)
+	response.Gallery = toPublicGallery(gallery, product.Name)
+	if product.IsBundle {
+		components, err := catalog.LoadBundleComponents(config.DB, product.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro ao buscar componentes do kit",
+			})
+		}
+		response.InStock = catalog.BundleAvailability(components) > 0
+		for _, comp := range components {
+			response.BundleItems = append(response.BundleItems, PublicBundleItem{
+				Name:     comp.Product.Name,
+				Slug:     productSlug(comp.Product),
+				Size:     comp.Product.Size,
+				Color:    comp.Product.Color,
+				Quantity: comp.Item.Quantity,
+			})
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"product": response,
+	})
+}
+
+// DeleteProduct move o produto para a lixeira (deleted_at); RestoreProduct desfaz e
+// PurgeProduct exclui de vez.
 func DeleteProduct(c *fiber.Ctx) error {
 	productID, err := strconv.ParseUint(c.Params("id"), 10, 64)
 	if err != nil {
@@ -411,17 +1003,106 @@ func DeleteProduct(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produto não encontrado"})
 	}
 
-	if err := config.DB.Model(&product).Updates(map[string]interface{}{
-		"is_active": false,
-		"status":    schemas.ProductStatusDraft,
-	}).Error; err != nil {
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		before := product
+		if err := tx.Delete(&product).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().First(&product, before.ID).Error; err != nil {
+			return err
+		}
+		return catalog.RecordProductRevision(tx, schemas.RevisionDelete, &before, &product, actorFromCtx(c))
+	})
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   "Erro ao excluir produto",
 			"details": err.Error(),
 		})
 	}
+	search.InvalidateVocabulary()
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Produto removido com sucesso",
+		"message": "Produto movido para a lixeira",
 	})
 }
+
+// applyProductChanges aplica as alterações, troca o slug quando informado, reindexa a busca
+// e grava a revisão, tudo na transação recebida. product volta com o estado atualizado.
+func applyProductChanges(tx *gorm.DB, product *schemas.Products, updates map[string]interface{}, newSlug string, action schemas.RevisionAction, actor catalog.Actor) error {
+	before := *product
+
+	if len(updates) > 0 {
+		if err := tx.Model(product).Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+	if newSlug != "" {
+		if err := catalog.ChangeProductSlug(tx, product, newSlug); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.First(product, before.ID).Error; err != nil {
+		return err
+	}
+
+	product.SearchText = search.ProductDocument(*product)
+	if err := tx.Model(product).UpdateColumn("search_text", product.SearchText).Error; err != nil {
+		return err
+	}
+
+	if err := catalog.RecordProductRevision(tx, action, &before, product, actor); err != nil {
+		return err
+	}
+
+	source := schemas.PriceSourceUpdate
+	if action == schemas.RevisionRestore {
+		source = schemas.PriceSourceRestore
+	}
+	return catalog.RecordPriceChange(tx, &before, *product, source, actor, time.Now())
+}
+
+// actorFromCtx identifica o usuário autenticado para o histórico de revisões.
+func actorFromCtx(c *fiber.Ctx) catalog.Actor {
+	actor := catalog.Actor{}
+	if id, ok := c.Locals("user_id").(uint64); ok {
+		actor.UserID = &id
+	}
+	actor.Name, _ = c.Locals("user_name").(string)
+	return actor
+}
+
+func sizeChartExists(id uint64) (bool, error) {
+	var count int64
+	err := config.DB.Model(&schemas.SizeCharts{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+// skuOwner devolve o outro produto que usa o SKU, inclusive na lixeira (o índice único vale
+// para os dois); nil quando o SKU está livre.
+func skuOwner(sku string, exceptID uint64) (*schemas.Products, error) {
+	var owner schemas.Products
+	err := config.DB.Unscoped().Select("id", "sku", "name", "deleted_at").
+		Where("sku = ? AND id <> ?", sku, exceptID).First(&owner).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &owner, nil
+}
+
+// skuConflict responde 409 indicando se o SKU está preso a um produto da lixeira.
+func skuConflict(c *fiber.Ctx, owner *schemas.Products) error {
+	response := fiber.Map{
+		"error":      "SKU já cadastrado",
+		"sku":        owner.SKU,
+		"product_id": owner.ID,
+		"in_trash":   owner.DeletedAt.Valid,
+	}
+	if owner.DeletedAt.Valid {
+		response["message"] = "O produto com este SKU está na lixeira: restaure-o ou exclua-o definitivamente"
+	}
+	return c.Status(fiber.StatusConflict).JSON(response)
+}
@@ -0,0 +1,224 @@
+package controller
+
+import (
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/catalog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	maxManualRelated = 20
+	// publicRelatedLimit é quantos produtos cada bloco da página exibe.
+	publicRelatedLimit = 8
+)
+
+// GetProductRelations — admin: relacionados manuais e os "comprados juntos" calculados.
+func GetProductRelations(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+	if err := config.DB.Select("id").First(&schemas.Products{}, productID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produto não encontrado"})
+	}
+
+	manual, boughtTogether, err := loadRelationItems(productID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar produtos relacionados",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"related":         manual,
+		"bought_together": boughtTogether,
+	})
+}
+
+// SetProductRelations substitui os relacionados manuais; a ordem da lista é a de exibição.
+// Lista vazia remove todos.
+func SetProductRelations(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+
+	req := SetRelatedRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Erro ao processar dados da requisição",
+			"details": err.Error(),
+		})
+	}
+
+	if err := req.Validate(productID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	if err := config.DB.Select("id").First(&schemas.Products{}, productID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produto não encontrado"})
+	}
+	if len(req.ProductIDs) > 0 {
+		var count int64
+		if err := config.DB.Model(&schemas.Products{}).Where("id IN ?", req.ProductIDs).Count(&count).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro ao verificar produtos",
+			})
+		}
+		if int(count) != len(req.ProductIDs) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Produto relacionado não encontrado",
+			})
+		}
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ? AND type = ?", productID, schemas.RelationManual).
+			Delete(&schemas.ProductRelations{}).Error; err != nil {
+			return err
+		}
+		if len(req.ProductIDs) == 0 {
+			return nil
+		}
+		relations := make([]schemas.ProductRelations, 0, len(req.ProductIDs))
+		for i, id := range req.ProductIDs {
+			relations = append(relations, schemas.ProductRelations{
+				ProductID: productID,
+				RelatedID: id,
+				Type:      schemas.RelationManual,
+				Position:  i + 1,
+			})
+		}
+		return tx.Create(&relations).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao salvar produtos relacionados",
+			"details": err.Error(),
+		})
+	}
+
+	manual, _, err := loadRelationItems(productID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar produtos relacionados",
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Produtos relacionados atualizados",
+		"related": manual,
+	})
+}
+
+// GetRelatedProducts — loja pública: relacionados e "comprados juntos" do produto publicado.
+// Só aparecem produtos visíveis na loja.
+func GetRelatedProducts(c *fiber.Ctx) error {
+	slug := strings.ToLower(strings.TrimSpace(c.Params("slug")))
+	now := time.Now()
+
+	var product schemas.Products
+	if err := config.DB.Scopes(catalog.VisibleAt(now)).Where("slug = ?", slug).First(&product).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produto não encontrado"})
+	}
+
+	related, err := catalog.RelatedProducts(config.DB, product.ID, schemas.RelationManual, publicRelatedLimit, now)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar produtos relacionados",
+		})
+	}
+	boughtTogether, err := catalog.RelatedProducts(config.DB, product.ID, schemas.RelationBoughtTogether, publicRelatedLimit, now)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar produtos comprados juntos",
+		})
+	}
+
+	galleries, err := catalog.ProductGalleries(config.DB, append(productIDs(related), productIDs(boughtTogether)...))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar imagens dos produtos",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"related":         toPublicProductList(related, galleries),
+		"bought_together": toPublicProductList(boughtTogether, galleries),
+	})
+}
+
+func toPublicProductList(products []schemas.Products, galleries map[uint64][]schemas.ProductImages) []PublicProductResponse {
+	responses := make([]PublicProductResponse, 0, len(products))
+	for _, p := range products {
+		response := toPublicProductResponse(p, nil)
+		response.Gallery = toPublicGallery(galleries[p.ID], p.Name)
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+func productIDs(products []schemas.Products) []uint64 {
+	ids := make([]uint64, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func loadRelationItems(productID uint64) (manual, boughtTogether []RelatedItemResponse, err error) {
+	var relations []schemas.ProductRelations
+	if err := config.DB.Where("product_id = ?", productID).
+		Order("type ASC, position ASC").Find(&relations).Error; err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]uint64, 0, len(relations))
+	for _, r := range relations {
+		ids = append(ids, r.RelatedID)
+	}
+	byID := make(map[uint64]schemas.Products, len(ids))
+	if len(ids) > 0 {
+		var products []schemas.Products
+		if err := config.DB.Select("id", "sku", "name", "slug", "is_active").
+			Where("id IN ?", ids).Find(&products).Error; err != nil {
+			return nil, nil, err
+		}
+		for _, p := range products {
+			byID[p.ID] = p
+		}
+	}
+
+	manual = []RelatedItemResponse{}
+	boughtTogether = []RelatedItemResponse{}
+	for _, r := range relations {
+		p, ok := byID[r.RelatedID]
+		if !ok {
+			continue
+		}
+		item := RelatedItemResponse{
+			ProductID: p.ID,
+			SKU:       p.SKU,
+			Name:      p.Name,
+			Slug:      productSlug(p),
+			IsActive:  p.IsActive,
+			Type:      r.Type,
+			Position:  r.Position,
+			Score:     r.Score,
+		}
+		if r.Type == schemas.RelationManual {
+			manual = append(manual, item)
+		} else {
+			boughtTogether = append(boughtTogether, item)
+		}
+	}
+	return manual, boughtTogether, nil
+}
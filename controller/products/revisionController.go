@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/search"
+	"encoding/json"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// restoreUpdates lista as colunas restauradas a partir de uma revisão. Estoque, imagens e slug
+// ficam de fora: estoque muda por pedidos, imagens podem já ter sido removidas do MinIO e o slug é estável.
+func restoreUpdates(snapshot schemas.Products) map[string]interface{} {
+	updates := map[string]interface{}{
+		"sku":                 snapshot.SKU,
+		"name":                snapshot.Name,
+		"description":         snapshot.Description,
+		"category_id":         snapshot.CategoryID,
+		"categorys":           snapshot.Categorys,
+		"size":                snapshot.Size,
+		"color":               snapshot.Color,
+		"material":            snapshot.Material,
+		"gender":              snapshot.Gender,
+		"price":               snapshot.Price,
+		"promotional_price":   snapshot.PromotionalPrice,
+		"promotion_starts_at": snapshot.PromotionStartsAt,
+		"promotion_ends_at":   snapshot.PromotionEndsAt,
+		"min_stock":           snapshot.MinStock,
+		"weight":              snapshot.Weight,
+		"dimensions":          snapshot.Dimensions,
+		"status":              snapshot.Status,
+		"publish_at":          snapshot.PublishAt,
+		"unpublish_at":        snapshot.UnpublishAt,
+		"is_active":           snapshot.IsActive,
+		"is_promotional":      snapshot.IsPromotional,
+		"tags":                snapshot.Tags,
+		"seo_description":     snapshot.SEODescription,
+		"seo_keywords":        snapshot.SEOKeywords,
+		"size_chart_id":       snapshot.SizeChartID,
+	}
+	if options, err := json.Marshal(snapshot.PersonalizationOptions); err == nil {
+		updates["personalization_options"] = string(options)
+	}
+	return updates
+}
+
+// ListProductRevisions — histórico paginado de alterações do produto (mais recentes primeiro).
+func ListProductRevisions(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+
+	page, limit, offset := parsePagination(c)
+
+	query := config.DB.Model(&schemas.ProductRevisions{}).Where("product_id = ?", productID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao contar revisões",
+		})
+	}
+
+	var revisions []schemas.ProductRevisions
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&revisions).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar revisões",
+		})
+	}
+
+	responses := make([]RevisionResponse, 0, len(revisions))
+	for _, r := range revisions {
+		responses = append(responses, toRevisionResponse(r, false))
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	if totalPages < 1 {
+		totalPages = 1
+	}
+
+	return c.Status(fiber.StatusOK).JSON(RevisionListResponse{
+		Revisions: responses,
+		Total:     total,
+		Page:      page,
+		Limit:     limit,
+		Pages:     totalPages,
+	})
+}
+
+// GetProductRevision — revisão com o estado completo do produto naquele momento.
+func GetProductRevision(c *fiber.Ctx) error {
+	revision, ferr := findRevision(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"revision": toRevisionResponse(*revision, true),
+	})
+}
+
+// RestoreProductRevision reverte o produto ao estado gravado na revisão; a restauração
+// também vira uma revisão, então pode ser desfeita.
+func RestoreProductRevision(c *fiber.Ctx) error {
+	revision, ferr := findRevision(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	var snapshot schemas.Products
+	if err := json.Unmarshal(revision.Snapshot, &snapshot); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": "Revisão sem estado do produto para restaurar",
+		})
+	}
+
+	var product schemas.Products
+	if err := config.DB.First(&product, revision.ProductID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produto não encontrado"})
+	}
+
+	// SKU pode ter sido reaproveitado por outro produto depois da revisão
+	owner, err := skuOwner(snapshot.SKU, product.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao verificar SKU",
+		})
+	}
+	if owner != nil {
+		return skuConflict(c, owner)
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		return applyProductChanges(tx, &product, restoreUpdates(snapshot), "", schemas.RevisionRestore, actorFromCtx(c))
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao restaurar revisão",
+			"details": err.Error(),
+		})
+	}
+	search.InvalidateVocabulary()
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":     "Produto restaurado",
+		"revision_id": revision.ID,
+		"product":     toProductResponse(product),
+	})
+}
+
+// findRevision carrega a revisão do path garantindo que pertence ao produto do path.
+func findRevision(c *fiber.Ctx) (*schemas.ProductRevisions, *fiber.Error) {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "ID inválido")
+	}
+	revisionID, err := strconv.ParseUint(c.Params("revisionId"), 10, 64)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "ID da revisão inválido")
+	}
+
+	var revision schemas.ProductRevisions
+	if err := config.DB.Where("id = ? AND product_id = ?", revisionID, productID).First(&revision).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Revisão não encontrada")
+	}
+	return &revision, nil
+}
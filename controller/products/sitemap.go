@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/catalog"
+	"encoding/xml"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+// Sitemap — sitemap.xml com produtos publicados, categorias e coleções ativas.
+func Sitemap(c *fiber.Ctx) error {
+	if strings.TrimSpace(os.Getenv("STORE_PUBLIC_URL")) == "" {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "STORE_PUBLIC_URL não configurada",
+		})
+	}
+
+	var products []schemas.Products
+	if err := config.DB.Select("id", "slug", "updated_at").
+		Scopes(catalog.VisibleAt(time.Now())).
+		Where("slug IS NOT NULL").
+		Order("id ASC").
+		Find(&products).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao gerar sitemap",
+		})
+	}
+
+	var categories []schemas.Categories
+	if err := config.DB.Select("slug", "updated_at").Where("is_active = ?", true).
+		Order("position ASC").Find(&categories).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao gerar sitemap",
+		})
+	}
+
+	var collections []schemas.Collections
+	if err := config.DB.Select("slug", "updated_at").Where("is_active = ?", true).
+		Order("position ASC").Find(&collections).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao gerar sitemap",
+		})
+	}
+
+	set := sitemapURLSet{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, cat := range categories {
+		set.URLs = append(set.URLs, sitemapURL{
+			Loc:        catalog.StoreURL(catalog.CategoryPath, cat.Slug),
+			LastMod:    cat.UpdatedAt.Format(time.DateOnly),
+			ChangeFreq: "daily",
+			Priority:   "0.8",
+		})
+	}
+	for _, col := range collections {
+		set.URLs = append(set.URLs, sitemapURL{
+			Loc:        catalog.StoreURL(catalog.CollectionPath, col.Slug),
+			LastMod:    col.UpdatedAt.Format(time.DateOnly),
+			ChangeFreq: "daily",
+			Priority:   "0.7",
+		})
+	}
+	for _, p := range products {
+		set.URLs = append(set.URLs, sitemapURL{
+			Loc:        catalog.StoreURL(catalog.ProductPath, productSlug(p)),
+			LastMod:    p.UpdatedAt.Format(time.DateOnly),
+			ChangeFreq: "weekly",
+			Priority:   "0.6",
+		})
+	}
+
+	body, err := xml.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao gerar sitemap",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/xml; charset=utf-8")
+	c.Set(fiber.HeaderCacheControl, "public, max-age=3600")
+	return c.Status(fiber.StatusOK).Send(append([]byte(xml.Header), body...))
+}
@@ -0,0 +1,213 @@
+package controller
+
+import (
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/catalog"
+	"backend_camisaria_store/service/minio"
+	"backend_camisaria_store/service/search"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// ListTrashedProducts — lixeira: produtos excluídos, mais recentes primeiro (?search=).
+func ListTrashedProducts(c *fiber.Ctx) error {
+	filters := ProductFilter{Trashed: true}
+	if search := c.Query("search"); search != "" {
+		filters.Search = &search
+	}
+	return listProductsWithFilters(c, filters)
+}
+
+// RestoreProduct tira o produto da lixeira no mesmo estado em que foi excluído.
+func RestoreProduct(c *fiber.Ctx) error {
+	product, ferr := findTrashedProduct(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		before := *product
+		if err := tx.Unscoped().Model(product).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.First(product, before.ID).Error; err != nil {
+			return err
+		}
+		return catalog.RecordProductRevision(tx, schemas.RevisionRestore, &before, product, actorFromCtx(c))
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao restaurar produto",
+			"details": err.Error(),
+		})
+	}
+	search.InvalidateVocabulary()
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Produto restaurado da lixeira",
+		"product": toProductResponse(*product),
+	})
+}
+
+// PurgeProduct exclui definitivamente um produto da lixeira, com os vínculos do catálogo
+// e as imagens no MinIO. Produtos com pedidos, orçamentos ou que compõem kits ficam na
+// lixeira para não quebrar o histórico.
+func PurgeProduct(c *fiber.Ctx) error {
+	product, ferr := findTrashedProduct(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	var orderItems, quoteItems int64
+	if err := config.DB.Model(&schemas.OrderItems{}).Where("product_id = ?", product.ID).Count(&orderItems).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao verificar pedidos do produto"})
+	}
+	if err := config.DB.Model(&schemas.QuoteItems{}).Where("product_id = ?", product.ID).Count(&quoteItems).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao verificar orçamentos do produto"})
+	}
+	if orderItems > 0 || quoteItems > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":       "Produto com histórico de vendas",
+			"message":     "Produtos presentes em pedidos ou orçamentos não podem ser excluídos definitivamente",
+			"order_items": orderItems,
+			"quote_items": quoteItems,
+		})
+	}
+
+	var bundles []uint64
+	if err := config.DB.Model(&schemas.BundleItems{}).Where("component_id = ?", product.ID).
+		Pluck("bundle_id", &bundles).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao verificar kits do produto"})
+	}
+	if len(bundles) > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":      "Produto é componente de kit",
+			"message":    "Remova o produto dos kits antes de excluí-lo definitivamente",
+			"bundle_ids": bundles,
+		})
+	}
+
+	var reviewPhotos []string
+	var reviews []schemas.ProductReviews
+	if err := config.DB.Select("id", "photos").Where("product_id = ?", product.ID).Find(&reviews).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao buscar avaliações do produto"})
+	}
+	for _, r := range reviews {
+		reviewPhotos = append(reviewPhotos, r.Photos...)
+	}
+
+	gallery, err := catalog.ProductGallery(config.DB, product.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao buscar imagens do produto"})
+	}
+	// Cada imagem da galeria devolve sua referência aos arquivos (compartilhados por hash);
+	// URLs fora da galeria e fotos das avaliações são apagadas direto
+	var releaseURLs []string
+	inGallery := map[string]bool{}
+	for _, img := range gallery {
+		urls := catalog.ImageObjectRefs(img)
+		releaseURLs = append(releaseURLs, urls...)
+		for _, u := range urls {
+			inGallery[u] = true
+		}
+	}
+	var objectURLs []string
+	for _, u := range minio.JsonToStringSlice(product.Images) {
+		if !inGallery[u] {
+			objectURLs = append(objectURLs, u)
+		}
+	}
+	objectURLs = uniqueStrings(append(objectURLs, reviewPhotos...))
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ? OR related_id = ?", product.ID, product.ID).
+			Delete(&schemas.ProductRelations{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("bundle_id = ?", product.ID).Delete(&schemas.BundleItems{}).Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{
+			&schemas.CollectionProducts{},
+			&schemas.WishlistItems{},
+			&schemas.StockSubscriptions{},
+			&schemas.ProductSlugHistory{},
+			&schemas.ProductPriceHistory{},
+			&schemas.ProductImages{},
+			&schemas.ProductReviews{},
+		} {
+			if err := tx.Where("product_id = ?", product.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Delete(product).Error; err != nil {
+			return err
+		}
+		// after nil: a revisão guarda o último estado do produto excluído
+		return catalog.RecordProductRevision(tx, schemas.RevisionDelete, product, nil, actorFromCtx(c))
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao excluir produto definitivamente",
+			"details": err.Error(),
+		})
+	}
+
+	// Arquivos saem depois do commit: se o MinIO falhar, o produto já não existe e o
+	// resultado indica o que ficou para trás
+	files := append(minio.ReleaseObjects(releaseURLs), minio.DeleteObjects(objectURLs)...)
+	failed := 0
+	for _, f := range files {
+		if !f.Success {
+			failed++
+		}
+	}
+
+	response := fiber.Map{
+		"message":      "Produto excluído definitivamente",
+		"product_id":   product.ID,
+		"files":        files,
+		"files_failed": failed,
+	}
+	if failed > 0 {
+		return c.Status(fiber.StatusPartialContent).JSON(response)
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// findTrashedProduct carrega o produto do path desde que esteja na lixeira.
+func findTrashedProduct(c *fiber.Ctx) (*schemas.Products, *fiber.Error) {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "ID inválido")
+	}
+
+	var product schemas.Products
+	err = config.DB.Unscoped().First(&product, productID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Produto não encontrado")
+	}
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Erro ao buscar produto")
+	}
+	if !product.DeletedAt.Valid {
+		return nil, fiber.NewError(fiber.StatusConflict, "Produto não está na lixeira")
+	}
+	return &product, nil
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" && !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/minio"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Formatos de arte aceitos: imagens, vetores e PDF enviados pelas empresas.
+var allowedArtworkExts = map[string]bool{
+	".png":  true,
+	".jpg":  true,
+	".jpeg": true,
+	".webp": true,
+	".svg":  true,
+	".pdf":  true,
+	".ai":   true,
+	".eps":  true,
+	".cdr":  true,
+}
+
+const maxArtworkSize = 20 * 1024 * 1024 // 20MB
+
+// UploadQuoteArtwork envia a arte/logo (campo "artwork") para o MinIO em quotes/.
+// O campo opcional "item_id" vincula a arte a um item do orçamento.
+func UploadQuoteArtwork(c *fiber.Ctx) error {
+	quote, ferr := findOwnQuote(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+	if quote.Status != schemas.QuoteSubmitted && quote.Status != schemas.QuotePriced {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":  "Não é possível enviar arte para este orçamento",
+			"status": quote.Status,
+		})
+	}
+
+	file, err := c.FormFile("artwork")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Nenhum arquivo enviado",
+			"message": "Envie a arte no campo 'artwork'",
+		})
+	}
+
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if !allowedArtworkExts[ext] {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Formato de arte não permitido. Use PNG, JPG, WebP, SVG, PDF, AI, EPS ou CDR",
+		})
+	}
+	if file.Size > maxArtworkSize {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Arquivo muito grande. Tamanho máximo: 20MB",
+		})
+	}
+
+	var itemID *uint64
+	if raw := c.FormValue("item_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil || !quoteHasItem(quote, id) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Item do orçamento não encontrado",
+			})
+		}
+		itemID = &id
+	}
+
+	publicURL, objectName, err := minio.UploadProductImage(file, quote.CompanyName, "quotes/"+quote.Number, quote.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao enviar arte",
+			"details": err.Error(),
+		})
+	}
+
+	artwork := schemas.QuoteArtworks{
+		QuoteID:     quote.ID,
+		FileName:    file.Filename,
+		ObjectName:  objectName,
+		URL:         publicURL,
+		ContentType: file.Header.Get("Content-Type"),
+		Size:        file.Size,
+	}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&artwork).Error; err != nil {
+			return err
+		}
+		if itemID == nil {
+			return nil
+		}
+		return tx.Model(&schemas.QuoteItems{}).Where("id = ?", *itemID).Update("artwork_id", artwork.ID).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao salvar arte",
+			"details": err.Error(),
+		})
+	}
+
+	return respondQuote(c, quote.ID, "Arte enviada com sucesso")
+}
+
+func quoteHasItem(quote *schemas.Quotes, itemID uint64) bool {
+	for _, it := range quote.Items {
+		if it.ID == itemID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,604 @@
+package controller
+
+import (
+	"backend_camisaria_store/common"
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const defaultQuoteValidDays = 15
+
+// CreateQuote — empresa envia a grade de tamanhos/quantidades para orçamento.
+func CreateQuote(c *fiber.Ctx) error {
+	req := CreateQuoteRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Erro ao processar dados da requisição",
+			"details": err.Error(),
+		})
+	}
+
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	ids := make([]uint64, 0, len(req.Items))
+	for _, it := range req.Items {
+		ids = append(ids, it.ProductID)
+	}
+	var found int64
+	if err := config.DB.Model(&schemas.Products{}).
+		Where("id IN ? AND is_active = ?", ids, true).
+		Distinct("id").Count(&found).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao verificar produtos",
+		})
+	}
+	if int(found) != len(uniqueIDs(ids)) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": "produto do orçamento não encontrado",
+		})
+	}
+
+	quote := schemas.Quotes{
+		Number:       generateQuoteNumber(),
+		UserID:       c.Locals("user_id").(uint64),
+		CompanyName:  strings.TrimSpace(req.CompanyName),
+		CNPJ:         req.CNPJ,
+		ContactName:  req.ContactName,
+		ContactEmail: req.ContactEmail,
+		ContactPhone: req.ContactPhone,
+		Notes:        req.Notes,
+		Status:       schemas.QuoteSubmitted,
+	}
+	for _, it := range req.Items {
+		quote.Items = append(quote.Items, schemas.QuoteItems{
+			ProductID:           it.ProductID,
+			Description:         it.Description,
+			Color:               it.Color,
+			SizeGrid:            it.SizeGrid,
+			Quantity:            gridQuantity(it.SizeGrid),
+			EmbroideryPositions: it.EmbroideryPositions,
+			Personalization:     it.Personalization,
+		})
+	}
+
+	if err := config.DB.Create(&quote).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao salvar orçamento",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Orçamento enviado com sucesso",
+		"quote":   toQuoteResponse(quote),
+	})
+}
+
+// ListMyQuotes — orçamentos do usuário logado.
+func ListMyQuotes(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint64)
+	return listQuotes(c, config.DB.Where("user_id = ?", userID))
+}
+
+// ListQuotes — admin: fila de orçamentos, filtrável por status.
+func ListQuotes(c *fiber.Ctx) error {
+	query := config.DB
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	return listQuotes(c, query)
+}
+
+func listQuotes(c *fiber.Ctx, query *gorm.DB) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	query = query.Model(&schemas.Quotes{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao contar orçamentos",
+		})
+	}
+
+	var quotes []schemas.Quotes
+	if err := query.Preload("Items").Order("id DESC").Offset(offset).Limit(limit).Find(&quotes).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar orçamentos",
+		})
+	}
+
+	responses := make([]QuoteResponse, 0, len(quotes))
+	for _, q := range quotes {
+		responses = append(responses, toQuoteResponse(q))
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	if totalPages < 1 {
+		totalPages = 1
+	}
+
+	return c.Status(fiber.StatusOK).JSON(QuoteListResponse{
+		Quotes: responses,
+		Total:  total,
+		Page:   page,
+		Limit:  limit,
+		Pages:  totalPages,
+	})
+}
+
+// GetQuote — detalhe do orçamento (dono ou equipe interna).
+func GetQuote(c *fiber.Ctx) error {
+	quote, ferr := findQuote(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	response := toQuoteResponse(*quote)
+	if quote.OrderID != nil {
+		var order schemas.Orders
+		if err := config.DB.Select("id", "production_status").First(&order, *quote.OrderID).Error; err == nil {
+			response.ProductionStatus = order.ProductionStatus
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"quote": response,
+	})
+}
+
+// PriceQuote — equipe informa o preço unitário de cada item, desconto e validade.
+// Um orçamento já precificado pode ser reprecificado enquanto não for aprovado.
+func PriceQuote(c *fiber.Ctx) error {
+	req := PriceQuoteRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Erro ao processar dados da requisição",
+			"details": err.Error(),
+		})
+	}
+
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	quote, ferr := findQuote(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+	if quote.Status != schemas.QuoteSubmitted && quote.Status != schemas.QuotePriced {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":  "Orçamento não pode mais ser precificado",
+			"status": quote.Status,
+		})
+	}
+
+	prices := make(map[uint64]float64, len(req.Items))
+	for _, it := range req.Items {
+		prices[it.ItemID] = it.UnitPrice
+	}
+
+	subtotal := 0.0
+	for i := range quote.Items {
+		price, ok := prices[quote.Items[i].ID]
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Dados inválidos",
+				"details": fmt.Sprintf("preço do item %d não informado", quote.Items[i].ID),
+			})
+		}
+		quote.Items[i].UnitPrice = price
+		quote.Items[i].LineTotal = roundMoney(price * float64(quote.Items[i].Quantity))
+		subtotal += quote.Items[i].LineTotal
+	}
+	if req.Discount > subtotal {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": "desconto maior que o subtotal",
+		})
+	}
+
+	validDays := req.ValidDays
+	if validDays == 0 {
+		validDays = defaultQuoteValidDays
+	}
+	now := time.Now()
+	validUntil := now.AddDate(0, 0, validDays)
+	staffID := c.Locals("user_id").(uint64)
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, it := range quote.Items {
+			if err := tx.Model(&schemas.QuoteItems{}).Where("id = ?", it.ID).Updates(map[string]interface{}{
+				"unit_price": it.UnitPrice,
+				"line_total": it.LineTotal,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(quote).Updates(map[string]interface{}{
+			"status":      schemas.QuotePriced,
+			"subtotal":    roundMoney(subtotal),
+			"discount":    req.Discount,
+			"total":       roundMoney(subtotal - req.Discount),
+			"valid_until": validUntil,
+			"priced_by":   staffID,
+			"priced_at":   now,
+			"staff_notes": req.StaffNotes,
+		}).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao precificar orçamento",
+			"details": err.Error(),
+		})
+	}
+
+	return respondQuote(c, quote.ID, "Orçamento precificado com sucesso")
+}
+
+// ApproveQuote — cliente aprova o orçamento precificado dentro da validade.
+func ApproveQuote(c *fiber.Ctx) error {
+	quote, ferr := findOwnQuote(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+	if quote.Status != schemas.QuotePriced {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":  "Apenas orçamentos precificados podem ser aprovados",
+			"status": quote.Status,
+		})
+	}
+	if quote.ValidUntil != nil && time.Now().After(*quote.ValidUntil) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":       "Orçamento expirado; solicite nova precificação",
+			"valid_until": quote.ValidUntil,
+		})
+	}
+
+	if err := config.DB.Model(quote).Updates(map[string]interface{}{
+		"status":      schemas.QuoteApproved,
+		"approved_at": time.Now(),
+	}).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao aprovar orçamento",
+			"details": err.Error(),
+		})
+	}
+
+	return respondQuote(c, quote.ID, "Orçamento aprovado")
+}
+
+// RejectQuote — cliente recusa o orçamento precificado.
+func RejectQuote(c *fiber.Ctx) error {
+	req := RejectQuoteRequest{}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Erro ao processar dados da requisição",
+				"details": err.Error(),
+			})
+		}
+	}
+	if len(req.Reason) > 500 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": "motivo deve ter no máximo 500 caracteres",
+		})
+	}
+
+	quote, ferr := findOwnQuote(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+	if quote.Status != schemas.QuotePriced {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":  "Apenas orçamentos precificados podem ser recusados",
+			"status": quote.Status,
+		})
+	}
+
+	if err := config.DB.Model(quote).Updates(map[string]interface{}{
+		"status":           schemas.QuoteRejected,
+		"rejected_at":      time.Now(),
+		"rejection_reason": req.Reason,
+	}).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao recusar orçamento",
+			"details": err.Error(),
+		})
+	}
+
+	return respondQuote(c, quote.ID, "Orçamento recusado")
+}
+
+// ConvertQuote — admin transforma o orçamento aprovado em pedido sob encomenda.
+// Peças são confeccionadas, então o estoque do catálogo não é baixado.
+func ConvertQuote(c *fiber.Ctx) error {
+	req := ConvertQuoteRequest{}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Erro ao processar dados da requisição",
+				"details": err.Error(),
+			})
+		}
+	}
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	quote, ferr := findQuote(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+	if quote.Status != schemas.QuoteApproved {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":  "Apenas orçamentos aprovados podem virar pedido",
+			"status": quote.Status,
+		})
+	}
+
+	var order schemas.Orders
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Reserva o orçamento: cliques repetidos não geram um segundo pedido
+		claim := tx.Model(&schemas.Quotes{}).
+			Where("id = ? AND status = ?", quote.ID, schemas.QuoteApproved).
+			Update("status", schemas.QuoteConverted)
+		if claim.Error != nil {
+			return claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			return fiber.NewError(fiber.StatusConflict, "Orçamento já convertido em pedido")
+		}
+
+		client, err := quoteClient(tx, quote)
+		if err != nil {
+			return err
+		}
+
+		quoteID := quote.ID
+		production := schemas.ProductionPending
+		order = schemas.Orders{
+			ClientID:         client.ID,
+			OrderNumber:      common.GenerateOrderNumber(),
+			Value:            quote.Total,
+			Originalvalue:    quote.Subtotal,
+			StatusPayment:    schemas.PendingPayment,
+			DeliveryType:     req.DeliveryType,
+			QuoteID:          &quoteID,
+			ProductionStatus: &production,
+		}
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+
+		// Desconto do orçamento distribuído proporcionalmente no preço dos itens
+		factor := 1.0
+		if quote.Subtotal > 0 {
+			factor = quote.Total / quote.Subtotal
+		}
+		for _, it := range quote.Items {
+			item := schemas.OrderItems{
+				OrderID:       order.ID,
+				ProductID:     it.ProductID,
+				Quantity:      it.Quantity,
+				Price:         roundMoney(it.UnitPrice * factor),
+				OriginalPrice: it.UnitPrice,
+			}
+			if err := tx.Create(&item).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&schemas.Quotes{}).Where("id = ?", quote.ID).Update("order_id", order.ID).Error
+	})
+	if err != nil {
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			return c.Status(fiberErr.Code).JSON(fiber.Map{"error": fiberErr.Message})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao converter orçamento em pedido",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":      "Pedido criado a partir do orçamento",
+		"order_id":     order.ID,
+		"order_number": order.OrderNumber,
+		"quote_id":     quote.ID,
+	})
+}
+
+// UpdateOrderProduction — admin avança a etapa de produção de um pedido sob encomenda
+// (orçamento convertido ou pedido da loja com personalização).
+func UpdateOrderProduction(c *fiber.Ctx) error {
+	orderID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+
+	req := UpdateProductionRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Erro ao processar dados da requisição",
+			"details": err.Error(),
+		})
+	}
+	if !isValidProductionStatus(req.ProductionStatus) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": "production_status deve ser pending, cutting, embroidery, finishing, ready ou delivered",
+		})
+	}
+
+	var order schemas.Orders
+	if err := config.DB.First(&order, orderID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Pedido não encontrado"})
+	}
+	if order.QuoteID == nil {
+		// Pedidos da loja só entram em produção quando têm peças personalizadas
+		var personalized int64
+		if err := config.DB.Model(&schemas.OrderItems{}).
+			Where("order_id = ? AND JSON_TYPE(personalization) = 'ARRAY'", order.ID).
+			Count(&personalized).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro ao verificar itens do pedido",
+			})
+		}
+		if personalized == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Pedido não tem itens sob encomenda ou personalizados",
+			})
+		}
+	}
+
+	if err := config.DB.Model(&order).Update("production_status", req.ProductionStatus).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao atualizar produção",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":           "Etapa de produção atualizada",
+		"order_id":          order.ID,
+		"production_status": req.ProductionStatus,
+	})
+}
+
+// findQuote carrega o orçamento do path; clientes só enxergam os próprios.
+func findQuote(c *fiber.Ctx) (*schemas.Quotes, *fiber.Error) {
+	quoteID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "ID inválido")
+	}
+
+	var quote schemas.Quotes
+	if err := config.DB.Preload("Items").Preload("Artworks").First(&quote, quoteID).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Orçamento não encontrado")
+	}
+
+	userType, _ := c.Locals("user_type").(string)
+	if userType == string(schemas.RoleClient) && quote.UserID != c.Locals("user_id").(uint64) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Orçamento não encontrado")
+	}
+	return &quote, nil
+}
+
+// findOwnQuote exige que o orçamento seja do usuário logado (aprovação/recusa).
+func findOwnQuote(c *fiber.Ctx) (*schemas.Quotes, *fiber.Error) {
+	quote, ferr := findQuote(c)
+	if ferr != nil {
+		return nil, ferr
+	}
+	if quote.UserID != c.Locals("user_id").(uint64) {
+		return nil, fiber.NewError(fiber.StatusForbidden, "Apenas quem solicitou o orçamento pode respondê-lo")
+	}
+	return quote, nil
+}
+
+func respondQuote(c *fiber.Ctx, quoteID uint64, message string) error {
+	var quote schemas.Quotes
+	if err := config.DB.Preload("Items").Preload("Artworks").First(&quote, quoteID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar orçamento atualizado",
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": message,
+		"quote":   toQuoteResponse(quote),
+	})
+}
+
+// quoteClient obtém (ou cria) o cadastro de cliente do solicitante para vincular o pedido.
+func quoteClient(tx *gorm.DB, quote *schemas.Quotes) (*schemas.Clients, error) {
+	var user schemas.Users
+	if err := tx.First(&user, quote.UserID).Error; err != nil {
+		return nil, fmt.Errorf("solicitante do orçamento não encontrado: %w", err)
+	}
+
+	// Só o cadastro do próprio usuário; o e-mail igual pode ser de outro cliente
+	var client schemas.Clients
+	err := tx.Where("user_id = ?", user.ID).First(&client).Error
+	if err == nil {
+		return &client, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var emailInUse int64
+	if err := tx.Model(&schemas.Clients{}).Where("email = ?", user.Email).Count(&emailInUse).Error; err != nil {
+		return nil, err
+	}
+	if emailInUse > 0 {
+		return nil, fiber.NewError(fiber.StatusConflict, "O e-mail do solicitante já pertence a outro cadastro de cliente")
+	}
+
+	client = schemas.Clients{
+		StoreID: config.StoreID(),
+		UserID:  user.ID,
+		Name:    quote.CompanyName,
+		Email:   user.Email,
+		Phone:   quote.ContactPhone,
+		Role:    schemas.RoleClient,
+	}
+	if err := tx.Create(&client).Error; err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+func uniqueIDs(ids []uint64) []uint64 {
+	seen := make(map[uint64]bool, len(ids))
+	out := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func generateQuoteNumber() string {
+	// Formato: ORC + timestamp + 3 dígitos aleatórios (mesmo padrão dos pedidos)
+	timestamp := time.Now().Format("20060102150405")
+	return fmt.Sprintf("ORC%s%d", timestamp, rand.Intn(900)+100)
+}
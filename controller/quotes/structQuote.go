@@ -0,0 +1,316 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/minio"
+)
+
+// Posições de bordado/estampa aceitas na grade do orçamento.
+var embroideryPositions = map[string]bool{
+	"peito_esquerdo": true,
+	"peito_direito":  true,
+	"costas":         true,
+	"manga_esquerda": true,
+	"manga_direita":  true,
+	"gola":           true,
+	"bolso":          true,
+}
+
+var cnpjDigits = regexp.MustCompile(`^\d{14}$`)
+
+type QuoteItemRequest struct {
+	ProductID           uint64                         `json:"product_id"`
+	Description         string                         `json:"description"`
+	Color               string                         `json:"color"`
+	SizeGrid            map[string]int                 `json:"size_grid"` // ex.: {"P": 10, "M": 25}
+	EmbroideryPositions []string                       `json:"embroidery_positions"`
+	Personalization     []schemas.QuotePersonalization `json:"personalization"` // nome por peça
+	ArtworkID           *uint64                        `json:"artwork_id,omitempty"`
+}
+
+type CreateQuoteRequest struct {
+	CompanyName  string             `json:"company_name"`
+	CNPJ         string             `json:"cnpj"`
+	ContactName  string             `json:"contact_name"`
+	ContactEmail string             `json:"contact_email"`
+	ContactPhone string             `json:"contact_phone"`
+	Notes        string             `json:"notes"`
+	Items        []QuoteItemRequest `json:"items"`
+}
+
+type PriceQuoteItemRequest struct {
+	ItemID    uint64  `json:"item_id"`
+	UnitPrice float64 `json:"unit_price"`
+}
+
+type PriceQuoteRequest struct {
+	Items      []PriceQuoteItemRequest `json:"items"`
+	Discount   float64                 `json:"discount"`
+	ValidDays  int                     `json:"valid_days"` // validade do preço; padrão 15 dias
+	StaffNotes string                  `json:"staff_notes"`
+}
+
+type RejectQuoteRequest struct {
+	Reason string `json:"reason"`
+}
+
+type ConvertQuoteRequest struct {
+	DeliveryType schemas.DeliveryType `json:"delivery_type"`
+}
+
+type UpdateProductionRequest struct {
+	ProductionStatus schemas.ProductionStatus `json:"production_status"`
+}
+
+type QuoteItemResponse struct {
+	ID                  uint64                         `json:"id"`
+	ProductID           uint64                         `json:"product_id"`
+	Description         string                         `json:"description"`
+	Color               string                         `json:"color"`
+	SizeGrid            map[string]int                 `json:"size_grid"`
+	Quantity            int                            `json:"quantity"`
+	EmbroideryPositions []string                       `json:"embroidery_positions"`
+	Personalization     []schemas.QuotePersonalization `json:"personalization"`
+	ArtworkID           *uint64                        `json:"artwork_id,omitempty"`
+	UnitPrice           float64                        `json:"unit_price"`
+	LineTotal           float64                        `json:"line_total"`
+}
+
+// As artes são privadas: a resposta leva um link assinado válido por artworkLinkTTL.
+const artworkLinkTTL = 2 * time.Hour
+
+type QuoteArtworkResponse struct {
+	ID          uint64 `json:"id"`
+	FileName    string `json:"file_name"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type QuoteResponse struct {
+	ID               uint64                    `json:"id"`
+	Number           string                    `json:"number"`
+	UserID           uint64                    `json:"user_id"`
+	CompanyName      string                    `json:"company_name"`
+	CNPJ             string                    `json:"cnpj"`
+	ContactName      string                    `json:"contact_name"`
+	ContactEmail     string                    `json:"contact_email"`
+	ContactPhone     string                    `json:"contact_phone"`
+	Notes            string                    `json:"notes"`
+	Status           schemas.QuoteStatus       `json:"status"`
+	StaffNotes       string                    `json:"staff_notes"`
+	Subtotal         float64                   `json:"subtotal"`
+	Discount         float64                   `json:"discount"`
+	Total            float64                   `json:"total"`
+	ValidUntil       *time.Time                `json:"valid_until,omitempty"`
+	PricedAt         *time.Time                `json:"priced_at,omitempty"`
+	ApprovedAt       *time.Time                `json:"approved_at,omitempty"`
+	RejectedAt       *time.Time                `json:"rejected_at,omitempty"`
+	RejectionReason  string                    `json:"rejection_reason,omitempty"`
+	OrderID          *uint64                   `json:"order_id,omitempty"`
+	ProductionStatus *schemas.ProductionStatus `json:"production_status,omitempty"` // quando convertido
+	Items            []QuoteItemResponse       `json:"items"`
+	Artworks         []QuoteArtworkResponse    `json:"artworks"`
+	CreatedAt        string                    `json:"created_at"`
+	UpdatedAt        string                    `json:"updated_at"`
+}
+
+type QuoteListResponse struct {
+	Quotes []QuoteResponse `json:"quotes"`
+	Total  int64           `json:"total"`
+	Page   int             `json:"page"`
+	Limit  int             `json:"limit"`
+	Pages  int             `json:"pages"`
+}
+
+func toQuoteResponse(q schemas.Quotes) QuoteResponse {
+	response := QuoteResponse{
+		ID:              q.ID,
+		Number:          q.Number,
+		UserID:          q.UserID,
+		CompanyName:     q.CompanyName,
+		CNPJ:            q.CNPJ,
+		ContactName:     q.ContactName,
+		ContactEmail:    q.ContactEmail,
+		ContactPhone:    q.ContactPhone,
+		Notes:           q.Notes,
+		Status:          q.Status,
+		StaffNotes:      q.StaffNotes,
+		Subtotal:        q.Subtotal,
+		Discount:        q.Discount,
+		Total:           q.Total,
+		ValidUntil:      q.ValidUntil,
+		PricedAt:        q.PricedAt,
+		ApprovedAt:      q.ApprovedAt,
+		RejectedAt:      q.RejectedAt,
+		RejectionReason: q.RejectionReason,
+		OrderID:         q.OrderID,
+		Items:           make([]QuoteItemResponse, 0, len(q.Items)),
+		Artworks:        make([]QuoteArtworkResponse, 0, len(q.Artworks)),
+		CreatedAt:       q.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:       q.UpdatedAt.Format(time.RFC3339),
+	}
+	for _, it := range q.Items {
+		response.Items = append(response.Items, QuoteItemResponse{
+			ID:                  it.ID,
+			ProductID:           it.ProductID,
+			Description:         it.Description,
+			Color:               it.Color,
+			SizeGrid:            it.SizeGrid,
+			Quantity:            it.Quantity,
+			EmbroideryPositions: it.EmbroideryPositions,
+			Personalization:     it.Personalization,
+			ArtworkID:           it.ArtworkID,
+			UnitPrice:           it.UnitPrice,
+			LineTotal:           it.LineTotal,
+		})
+	}
+	for _, a := range q.Artworks {
+		response.Artworks = append(response.Artworks, QuoteArtworkResponse{
+			ID:          a.ID,
+			FileName:    a.FileName,
+			URL:         minio.SignedURL(a.ObjectName, artworkLinkTTL),
+			ContentType: a.ContentType,
+			Size:        a.Size,
+			CreatedAt:   a.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return response
+}
+
+// gridQuantity soma as peças da grade de tamanhos.
+func gridQuantity(grid map[string]int) int {
+	total := 0
+	for _, q := range grid {
+		total += q
+	}
+	return total
+}
+
+func (req *CreateQuoteRequest) Validate() error {
+	var errs []string
+
+	if strings.TrimSpace(req.CompanyName) == "" {
+		errs = append(errs, "nome da empresa é obrigatório")
+	} else if len(req.CompanyName) > 255 {
+		errs = append(errs, "nome da empresa deve ter no máximo 255 caracteres")
+	}
+
+	if req.CNPJ != "" {
+		digits := strings.NewReplacer(".", "", "/", "", "-", "").Replace(req.CNPJ)
+		if !cnpjDigits.MatchString(digits) {
+			errs = append(errs, "CNPJ deve ter 14 dígitos")
+		}
+	}
+
+	if strings.TrimSpace(req.ContactEmail) == "" && strings.TrimSpace(req.ContactPhone) == "" {
+		errs = append(errs, "informe e-mail ou telefone de contato")
+	}
+
+	if len(req.Items) == 0 {
+		errs = append(errs, "orçamento deve ter ao menos um item")
+	}
+
+	for i, it := range req.Items {
+		prefix := fmt.Sprintf("item %d: ", i+1)
+		if it.ProductID == 0 {
+			errs = append(errs, prefix+"product_id é obrigatório")
+		}
+		if len(it.SizeGrid) == 0 {
+			errs = append(errs, prefix+"grade de tamanhos é obrigatória")
+		}
+		for size, q := range it.SizeGrid {
+			if strings.TrimSpace(size) == "" || q < 0 {
+				errs = append(errs, prefix+"grade de tamanhos inválida")
+				break
+			}
+		}
+		total := gridQuantity(it.SizeGrid)
+		if len(it.SizeGrid) > 0 && total == 0 {
+			errs = append(errs, prefix+"grade de tamanhos sem peças")
+		}
+		for _, pos := range it.EmbroideryPositions {
+			if !embroideryPositions[pos] {
+				errs = append(errs, prefix+"posição de bordado inválida: "+pos)
+			}
+		}
+		// Personalização: no máximo uma por peça, sempre em um tamanho da grade
+		if len(it.Personalization) > total {
+			errs = append(errs, prefix+"mais personalizações que peças")
+		}
+		perSize := make(map[string]int)
+		for _, p := range it.Personalization {
+			if strings.TrimSpace(p.Name) == "" && strings.TrimSpace(p.Number) == "" {
+				errs = append(errs, prefix+"personalização sem nome ou número")
+				break
+			}
+			if len(p.Name) > 30 || len(p.Number) > 4 {
+				errs = append(errs, prefix+"personalização excede o tamanho máximo (nome 30, número 4)")
+				break
+			}
+			perSize[p.Size]++
+			if perSize[p.Size] > it.SizeGrid[p.Size] {
+				errs = append(errs, prefix+"personalização no tamanho "+p.Size+" excede a grade")
+				break
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (req *PriceQuoteRequest) Validate() error {
+	var errs []string
+
+	if len(req.Items) == 0 {
+		errs = append(errs, "informe o preço dos itens")
+	}
+	for _, it := range req.Items {
+		if it.ItemID == 0 {
+			errs = append(errs, "item_id é obrigatório")
+		}
+		if it.UnitPrice <= 0 {
+			errs = append(errs, "preço unitário deve ser maior que zero")
+		}
+	}
+	if req.Discount < 0 {
+		errs = append(errs, "desconto não pode ser negativo")
+	}
+	if req.ValidDays < 0 || req.ValidDays > 90 {
+		errs = append(errs, "validade deve ser entre 1 e 90 dias")
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (req *ConvertQuoteRequest) Validate() error {
+	if req.DeliveryType == "" {
+		req.DeliveryType = schemas.PickupDelivery
+	}
+	if req.DeliveryType != schemas.PickupDelivery && req.DeliveryType != schemas.DeliveryDelivery {
+		return errors.New("delivery_type deve ser pickup ou delivery")
+	}
+	return nil
+}
+
+func isValidProductionStatus(s schemas.ProductionStatus) bool {
+	switch s {
+	case schemas.ProductionPending, schemas.ProductionCutting, schemas.ProductionEmbroidery,
+		schemas.ProductionFinishing, schemas.ProductionReady, schemas.ProductionDelivered:
+		return true
+	}
+	return false
+}
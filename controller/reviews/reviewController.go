@@ -0,0 +1,420 @@
+package controller
+
+import (
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/catalog"
+	"backend_camisaria_store/service/minio"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// CreateReview — avaliação de compra verificada: o item precisa ser de um pedido
+// entregue do próprio cliente. Entra na fila de moderação.
+func CreateReview(c *fiber.Ctx) error {
+	req := CreateReviewRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Erro ao processar dados da requisição",
+			"details": err.Error(),
+		})
+	}
+
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	userID := c.Locals("user_id").(uint64)
+
+	var item schemas.OrderItems
+	err := config.DB.Model(&schemas.OrderItems{}).
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Joins("JOIN clients ON clients.id = orders.client_id").
+		Where("order_items.id = ? AND clients.user_id = ? AND orders.delivered_at IS NOT NULL", req.OrderItemID, userID).
+		First(&item).Error
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":   "Compra não verificada",
+			"message": "Só é possível avaliar itens de pedidos entregues da sua conta",
+		})
+	}
+
+	var existing int64
+	if err := config.DB.Model(&schemas.ProductReviews{}).Where("order_item_id = ?", item.ID).
+		Count(&existing).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao verificar avaliação",
+		})
+	}
+	if existing > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Este item já foi avaliado",
+		})
+	}
+
+	review := schemas.ProductReviews{
+		ProductID:   item.ProductID,
+		UserID:      userID,
+		OrderItemID: item.ID,
+		Rating:      req.Rating,
+		Title:       strings.TrimSpace(req.Title),
+		Comment:     strings.TrimSpace(req.Comment),
+		Status:      schemas.ReviewPending,
+	}
+	if err := config.DB.Create(&review).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao salvar avaliação",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Avaliação enviada para moderação",
+		"review":  toReviewResponse(review, localUserName(c), true),
+	})
+}
+
+// UploadReviewPhotos envia fotos (campo "photos", até 5 por avaliação) enquanto a
+// avaliação aguarda moderação.
+func UploadReviewPhotos(c *fiber.Ctx) error {
+	review, ferr := findReview(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+	if review.UserID != c.Locals("user_id").(uint64) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Avaliação não encontrada"})
+	}
+	if review.Status != schemas.ReviewPending {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Fotos só podem ser enviadas antes da moderação",
+		})
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["photos"]) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Nenhum arquivo enviado",
+			"message": "Envie as fotos no campo 'photos'",
+		})
+	}
+	files := form.File["photos"]
+	if len(review.Photos)+len(files) > maxReviewPhotos {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Limite de " + strconv.Itoa(maxReviewPhotos) + " fotos por avaliação",
+		})
+	}
+	for _, file := range files {
+		if err := minio.ValidateImageFile(file); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":  err.Error(),
+				"reason": minio.RejectionReason(err),
+				"file":   file.Filename,
+			})
+		}
+	}
+
+	var product schemas.Products
+	if err := config.DB.Select("id", "name").First(&product, review.ProductID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao buscar produto da avaliação",
+			"details": err.Error(),
+		})
+	}
+
+	// Fotos reencodadas: descarta EXIF (localização do cliente) e aplica a orientação
+	photos := append([]string{}, review.Photos...)
+	for _, file := range files {
+		key, err := minio.UploadReencodedImage(file, product.Name, "reviews", review.ID, reviewPhotoMaxSide)
+		if err != nil {
+			if reason := minio.RejectionReason(err); reason != "" {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error":  err.Error(),
+					"reason": reason,
+					"file":   file.Filename,
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Erro ao enviar foto",
+				"details": err.Error(),
+			})
+		}
+		photos = append(photos, key)
+	}
+
+	encoded, _ := json.Marshal(photos)
+	if err := config.DB.Model(review).Update("photos", string(encoded)).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao salvar fotos",
+			"details": err.Error(),
+		})
+	}
+	review.Photos = photos
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Fotos enviadas com sucesso",
+		"review":  toReviewResponse(*review, localUserName(c), true),
+	})
+}
+
+// ListMyReviews — avaliações do cliente logado, com status da moderação.
+func ListMyReviews(c *fiber.Ctx) error {
+	var reviews []schemas.ProductReviews
+	if err := config.DB.Where("user_id = ?", c.Locals("user_id").(uint64)).
+		Order("id DESC").Limit(100).Find(&reviews).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar avaliações",
+		})
+	}
+
+	author := localUserName(c)
+	responses := make([]ReviewResponse, 0, len(reviews))
+	for _, r := range reviews {
+		responses = append(responses, toReviewResponse(r, author, true))
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"reviews": responses})
+}
+
+// ListReviewableItems — itens de pedidos entregues que o cliente ainda não avaliou.
+func ListReviewableItems(c *fiber.Ctx) error {
+	var items []EligibleItemResponse
+	err := config.DB.Table("order_items").
+		Select("order_items.id AS order_item_id, orders.order_number, products.id AS product_id, products.name AS product_name, orders.delivered_at").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Joins("JOIN clients ON clients.id = orders.client_id").
+		Joins("JOIN products ON products.id = order_items.product_id").
+		Joins("LEFT JOIN product_reviews ON product_reviews.order_item_id = order_items.id").
+		Where("clients.user_id = ? AND orders.delivered_at IS NOT NULL AND product_reviews.id IS NULL", c.Locals("user_id").(uint64)).
+		Order("orders.delivered_at DESC").
+		Limit(100).
+		Scan(&items).Error
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar itens para avaliar",
+		})
+	}
+	if items == nil {
+		items = []EligibleItemResponse{}
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"items": items})
+}
+
+// ListProductReviews — loja pública: avaliações aprovadas do produto publicado.
+// sort: recent (padrão), rating_desc, rating_asc, photos.
+func ListProductReviews(c *fiber.Ctx) error {
+	var product schemas.Products
+	if err := config.DB.Scopes(catalog.VisibleAt(time.Now())).
+		Where("slug = ?", strings.ToLower(c.Params("slug"))).First(&product).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produto não encontrado"})
+	}
+
+	query := config.DB.Model(&schemas.ProductReviews{}).
+		Where("product_id = ? AND status = ?", product.ID, schemas.ReviewApproved)
+	if rating, err := strconv.Atoi(c.Query("rating")); err == nil && rating >= 1 && rating <= 5 {
+		query = query.Where("rating = ?", rating)
+	}
+
+	switch c.Query("sort") {
+	case "rating_desc":
+		query = query.Order("rating DESC")
+	case "rating_asc":
+		query = query.Order("rating ASC")
+	case "photos":
+		query = query.Order("JSON_LENGTH(photos) DESC")
+	}
+
+	response, err := paginateReviews(c, query, false)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar avaliações",
+		})
+	}
+
+	var rows []struct {
+		Rating int
+		Total  int
+	}
+	if err := config.DB.Model(&schemas.ProductReviews{}).
+		Select("rating, COUNT(*) AS total").
+		Where("product_id = ? AND status = ?", product.ID, schemas.ReviewApproved).
+		Group("rating").Scan(&rows).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar distribuição das notas",
+		})
+	}
+	response.Distribution = map[int]int{1: 0, 2: 0, 3: 0, 4: 0, 5: 0}
+	for _, r := range rows {
+		response.Distribution[r.Rating] = r.Total
+	}
+	response.RatingAverage = &product.RatingAverage
+	response.RatingCount = &product.RatingCount
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// ListReviewQueue — admin: fila de moderação (status=pending por padrão).
+func ListReviewQueue(c *fiber.Ctx) error {
+	status := c.Query("status", string(schemas.ReviewPending))
+	query := config.DB.Model(&schemas.ProductReviews{}).Where("status = ?", status)
+	if productID := c.Query("product_id"); productID != "" {
+		query = query.Where("product_id = ?", productID)
+	}
+
+	response, err := paginateReviews(c, query.Order("id ASC"), true)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar avaliações",
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func ApproveReview(c *fiber.Ctx) error {
+	return moderateReview(c, schemas.ReviewApproved, "")
+}
+
+func RejectReview(c *fiber.Ctx) error {
+	req := ModerateReviewRequest{}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Erro ao processar dados da requisição",
+				"details": err.Error(),
+			})
+		}
+	}
+	if len(req.Reason) > 255 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": "motivo deve ter no máximo 255 caracteres",
+		})
+	}
+	return moderateReview(c, schemas.ReviewRejected, req.Reason)
+}
+
+// moderateReview muda o status e recalcula a nota do produto na mesma transação.
+func moderateReview(c *fiber.Ctx, status schemas.ReviewStatus, reason string) error {
+	review, ferr := findReview(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	moderator := c.Locals("user_id").(uint64)
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(review).Updates(map[string]interface{}{
+			"status":           status,
+			"moderated_by":     moderator,
+			"moderated_at":     time.Now(),
+			"rejection_reason": reason,
+		}).Error; err != nil {
+			return err
+		}
+		return catalog.RefreshProductRating(tx, review.ProductID)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao moderar avaliação",
+			"details": err.Error(),
+		})
+	}
+
+	review.Status = status
+	review.RejectionReason = reason
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Avaliação moderada",
+		"review":  toReviewResponse(*review, "", true),
+	})
+}
+
+// localUserName devolve o nome do usuário autenticado ("" se ausente no contexto).
+func localUserName(c *fiber.Ctx) string {
+	name, _ := c.Locals("user_name").(string)
+	return name
+}
+
+func findReview(c *fiber.Ctx) (*schemas.ProductReviews, *fiber.Error) {
+	reviewID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "ID inválido")
+	}
+	var review schemas.ProductReviews
+	if err := config.DB.First(&review, reviewID).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Avaliação não encontrada")
+	}
+	return &review, nil
+}
+
+// paginateReviews pagina a consulta e resolve autor e produto de cada avaliação.
+func paginateReviews(c *fiber.Ctx, query *gorm.DB, moderation bool) (*ReviewListResponse, error) {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var reviews []schemas.ProductReviews
+	if err := query.Order("created_at DESC").Offset((page - 1) * limit).Limit(limit).Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]uint64, 0, len(reviews))
+	productIDs := make([]uint64, 0, len(reviews))
+	for _, r := range reviews {
+		userIDs = append(userIDs, r.UserID)
+		productIDs = append(productIDs, r.ProductID)
+	}
+	names := make(map[uint64]string)
+	if len(userIDs) > 0 {
+		var users []schemas.Users
+		config.DB.Select("id", "name").Where("id IN ?", userIDs).Find(&users)
+		for _, u := range users {
+			names[u.ID] = u.Name
+		}
+	}
+	products := make(map[uint64]string)
+	if moderation && len(productIDs) > 0 {
+		var list []schemas.Products
+		config.DB.Unscoped().Select("id", "name").Where("id IN ?", productIDs).Find(&list)
+		for _, p := range list {
+			products[p.ID] = p.Name
+		}
+	}
+
+	responses := make([]ReviewResponse, 0, len(reviews))
+	for _, r := range reviews {
+		author := publicAuthor(names[r.UserID])
+		if moderation {
+			author = names[r.UserID]
+		}
+		response := toReviewResponse(r, author, moderation)
+		response.ProductName = products[r.ProductID]
+		responses = append(responses, response)
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	if totalPages < 1 {
+		totalPages = 1
+	}
+	return &ReviewListResponse{
+		Reviews: responses,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+		Pages:   totalPages,
+	}, nil
+}
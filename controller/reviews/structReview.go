@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/minio"
+)
+
+const maxReviewPhotos = 5
+
+// Lado maior das fotos de avaliação depois de reencodadas
+const reviewPhotoMaxSide = 1600
+
+type CreateReviewRequest struct {
+	OrderItemID uint64 `json:"order_item_id"`
+	Rating      int    `json:"rating"`
+	Title       string `json:"title"`
+	Comment     string `json:"comment"`
+}
+
+type ModerateReviewRequest struct {
+	Reason string `json:"reason"`
+}
+
+type ReviewResponse struct {
+	ID              uint64               `json:"id"`
+	ProductID       uint64               `json:"product_id"`
+	ProductName     string               `json:"product_name,omitempty"`
+	Author          string               `json:"author"`
+	Rating          int                  `json:"rating"`
+	Title           string               `json:"title"`
+	Comment         string               `json:"comment"`
+	Photos          []string             `json:"photos"`
+	VerifiedBuyer   bool                 `json:"verified_buyer"`
+	Status          schemas.ReviewStatus `json:"status,omitempty"`
+	RejectionReason string               `json:"rejection_reason,omitempty"`
+	CreatedAt       string               `json:"created_at"`
+}
+
+type ReviewListResponse struct {
+	Reviews []ReviewResponse `json:"reviews"`
+	Total   int64            `json:"total"`
+	Page    int              `json:"page"`
+	Limit   int              `json:"limit"`
+	Pages   int              `json:"pages"`
+
+	// Resumo do produto (listagem pública)
+	RatingAverage *float64    `json:"rating_average,omitempty"`
+	RatingCount   *int        `json:"rating_count,omitempty"`
+	Distribution  map[int]int `json:"distribution,omitempty"` // nota → quantidade
+}
+
+// EligibleItemResponse é um item entregue que o cliente ainda pode avaliar.
+type EligibleItemResponse struct {
+	OrderItemID uint64    `json:"order_item_id"`
+	OrderNumber string    `json:"order_number"`
+	ProductID   uint64    `json:"product_id"`
+	ProductName string    `json:"product_name"`
+	DeliveredAt time.Time `json:"delivered_at"`
+}
+
+// toReviewResponse monta a resposta; moderation inclui status e motivo (autor e admin).
+func toReviewResponse(r schemas.ProductReviews, author string, moderation bool) ReviewResponse {
+	photos := minio.MediaURLs(r.Photos)
+	response := ReviewResponse{
+		ID:            r.ID,
+		ProductID:     r.ProductID,
+		Author:        author,
+		Rating:        r.Rating,
+		Title:         r.Title,
+		Comment:       r.Comment,
+		Photos:        photos,
+		VerifiedBuyer: true,
+		CreatedAt:     r.CreatedAt.Format(time.RFC3339),
+	}
+	if moderation {
+		response.Status = r.Status
+		response.RejectionReason = r.RejectionReason
+	}
+	return response
+}
+
+// publicAuthor exibe só o primeiro nome e a inicial do sobrenome ("Maria S.").
+func publicAuthor(name string) string {
+	parts := strings.Fields(name)
+	if len(parts) == 0 {
+		return "Cliente"
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	last := []rune(parts[len(parts)-1])
+	return parts[0] + " " + string(last[0]) + "."
+}
+
+func (req *CreateReviewRequest) Validate() error {
+	var errs []string
+
+	if req.OrderItemID == 0 {
+		errs = append(errs, "order_item_id é obrigatório")
+	}
+	if req.Rating < 1 || req.Rating > 5 {
+		errs = append(errs, "nota deve ser entre 1 e 5")
+	}
+	if len(req.Title) > 120 {
+		errs = append(errs, "título deve ter no máximo 120 caracteres")
+	}
+	if len(req.Comment) > 2000 {
+		errs = append(errs, "comentário deve ter no máximo 2000 caracteres")
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
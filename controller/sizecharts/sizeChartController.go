@@ -0,0 +1,277 @@
+package controller
+
+import (
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/catalog"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func ListSizeCharts(c *fiber.Ctx) error {
+	query := config.DB.Order("name ASC")
+	if categoryID := c.Query("category_id"); categoryID != "" {
+		query = query.Where("category_id = ?", categoryID)
+	}
+
+	var charts []schemas.SizeCharts
+	if err := query.Find(&charts).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar tabelas de medidas",
+		})
+	}
+
+	responses := make([]SizeChartResponse, 0, len(charts))
+	for _, chart := range charts {
+		responses = append(responses, toSizeChartResponse(chart))
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"size_charts": responses})
+}
+
+func GetSizeChart(c *fiber.Ctx) error {
+	chart, ferr := findSizeChart(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"size_chart": toSizeChartResponse(*chart)})
+}
+
+func CreateSizeChart(c *fiber.Ctx) error {
+	req := SizeChartRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Erro ao processar dados da requisição",
+			"details": err.Error(),
+		})
+	}
+
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	if req.CategoryID != nil {
+		if err := config.DB.First(&schemas.Categories{}, *req.CategoryID).Error; err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Categoria não encontrada",
+			})
+		}
+	}
+
+	chart := schemas.SizeCharts{
+		Name:        strings.TrimSpace(req.Name),
+		CategoryID:  req.CategoryID,
+		Description: req.Description,
+		Rows:        req.Rows,
+		IsActive:    true,
+	}
+	if err := config.DB.Create(&chart).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao salvar tabela de medidas",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":    "Tabela de medidas criada com sucesso",
+		"size_chart": toSizeChartResponse(chart),
+	})
+}
+
+func UpdateSizeChart(c *fiber.Ctx) error {
+	req := UpdateSizeChartRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Erro ao processar dados da requisição",
+			"details": err.Error(),
+		})
+	}
+
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	chart, ferr := findSizeChart(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != nil {
+		updates["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.CategoryID != nil {
+		if err := config.DB.First(&schemas.Categories{}, *req.CategoryID).Error; err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Categoria não encontrada",
+			})
+		}
+		updates["category_id"] = *req.CategoryID
+	}
+	if req.NoCategory {
+		updates["category_id"] = nil
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Rows != nil {
+		rows, _ := json.Marshal(*req.Rows)
+		updates["rows"] = string(rows)
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+
+	if len(updates) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Nenhum campo para atualizar",
+		})
+	}
+
+	if err := config.DB.Model(chart).Updates(updates).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao atualizar tabela de medidas",
+			"details": err.Error(),
+		})
+	}
+	if err := config.DB.First(chart, chart.ID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar tabela atualizada",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":    "Tabela de medidas atualizada com sucesso",
+		"size_chart": toSizeChartResponse(*chart),
+	})
+}
+
+// DeleteSizeChart remove a tabela; produtos vinculados passam a usar a da categoria.
+func DeleteSizeChart(c *fiber.Ctx) error {
+	chart, ferr := findSizeChart(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&schemas.Products{}).Where("size_chart_id = ?", chart.ID).
+			Update("size_chart_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(chart).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao excluir tabela de medidas",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Tabela de medidas removida com sucesso",
+	})
+}
+
+// GetProductSizeChart — loja pública: tabela de medidas do produto publicado.
+func GetProductSizeChart(c *fiber.Ctx) error {
+	product, ferr := findPublishedProduct(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	chart, err := catalog.ProductSizeChart(config.DB, *product)
+	if errors.Is(err, catalog.ErrSizeChartNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produto sem tabela de medidas"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar tabela de medidas",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"size_chart": toSizeChartResponse(*chart)})
+}
+
+// RecommendProductSize sugere o tamanho do produto pelas medidas salvas no perfil.
+func RecommendProductSize(c *fiber.Ctx) error {
+	product, ferr := findPublishedProduct(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+
+	var user schemas.Users
+	if err := config.DB.First(&user, c.Locals("user_id").(uint64)).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Usuário não encontrado"})
+	}
+	if user.ChestCm == nil && user.HeightCm == nil && user.WeightKg == nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":   "Medidas não informadas",
+			"message": "Cadastre tórax, altura ou peso no perfil para receber uma recomendação",
+		})
+	}
+
+	chart, err := catalog.ProductSizeChart(config.DB, *product)
+	if errors.Is(err, catalog.ErrSizeChartNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produto sem tabela de medidas"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar tabela de medidas",
+		})
+	}
+
+	rec, ok := catalog.RecommendSize(*chart, catalog.Measurements{
+		ChestCm:  user.ChestCm,
+		HeightCm: user.HeightCm,
+		WeightKg: user.WeightKg,
+	})
+	if !ok {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": "A tabela deste produto não usa as medidas do seu perfil",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(RecommendationResponse{
+		Product:        product.Name,
+		Chart:          chart.Name,
+		Recommendation: rec,
+		Measurements: MeasurementsResponse{
+			ChestCm:  user.ChestCm,
+			HeightCm: user.HeightCm,
+			WeightKg: user.WeightKg,
+		},
+	})
+}
+
+func findSizeChart(c *fiber.Ctx) (*schemas.SizeCharts, *fiber.Error) {
+	chartID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "ID inválido")
+	}
+	var chart schemas.SizeCharts
+	if err := config.DB.First(&chart, chartID).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Tabela de medidas não encontrada")
+	}
+	return &chart, nil
+}
+
+func findPublishedProduct(c *fiber.Ctx) (*schemas.Products, *fiber.Error) {
+	slug := strings.ToLower(strings.TrimSpace(c.Params("slug")))
+	var product schemas.Products
+	if err := config.DB.Scopes(catalog.VisibleAt(time.Now())).Where("slug = ?", slug).
+		First(&product).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Produto não encontrado")
+	}
+	return &product, nil
+}
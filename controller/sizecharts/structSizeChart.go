@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/catalog"
+)
+
+type SizeChartRequest struct {
+	Name        string                 `json:"name"`
+	CategoryID  *uint64                `json:"category_id,omitempty"`
+	Description string                 `json:"description"`
+	Rows        []schemas.SizeChartRow `json:"rows"`
+}
+
+type UpdateSizeChartRequest struct {
+	Name        *string                 `json:"name,omitempty"`
+	CategoryID  *uint64                 `json:"category_id,omitempty"`
+	NoCategory  bool                    `json:"no_category,omitempty"` // desvincula da categoria
+	Description *string                 `json:"description,omitempty"`
+	Rows        *[]schemas.SizeChartRow `json:"rows,omitempty"`
+	IsActive    *bool                   `json:"is_active,omitempty"`
+}
+
+type SizeChartResponse struct {
+	ID          uint64                 `json:"id"`
+	Name        string                 `json:"name"`
+	CategoryID  *uint64                `json:"category_id,omitempty"`
+	Description string                 `json:"description"`
+	Rows        []schemas.SizeChartRow `json:"rows"`
+	IsActive    bool                   `json:"is_active"`
+	UpdatedAt   string                 `json:"updated_at"`
+}
+
+type RecommendationResponse struct {
+	Product        string                      `json:"product"`
+	Chart          string                      `json:"chart"`
+	Recommendation *catalog.SizeRecommendation `json:"recommendation"`
+	Measurements   MeasurementsResponse        `json:"measurements"`
+}
+
+type MeasurementsResponse struct {
+	ChestCm  *float64 `json:"chest_cm"`
+	HeightCm *float64 `json:"height_cm"`
+	WeightKg *float64 `json:"weight_kg"`
+}
+
+func toSizeChartResponse(c schemas.SizeCharts) SizeChartResponse {
+	rows := c.Rows
+	if rows == nil {
+		rows = []schemas.SizeChartRow{}
+	}
+	return SizeChartResponse{
+		ID:          c.ID,
+		Name:        c.Name,
+		CategoryID:  c.CategoryID,
+		Description: c.Description,
+		Rows:        rows,
+		IsActive:    c.IsActive,
+		UpdatedAt:   c.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func validateRows(rows []schemas.SizeChartRow) string {
+	if len(rows) == 0 {
+		return "tabela deve ter ao menos um tamanho"
+	}
+	seen := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		size := strings.TrimSpace(row.Size)
+		if size == "" {
+			return "tamanho é obrigatório em cada linha"
+		}
+		if seen[size] {
+			return "tamanho repetido: " + size
+		}
+		seen[size] = true
+		if len(row.Measurements) == 0 {
+			return "tamanho " + size + " sem medidas"
+		}
+		for key, r := range row.Measurements {
+			if r.Min < 0 || r.Max < r.Min {
+				return "faixa inválida em " + size + "/" + key
+			}
+		}
+	}
+	return ""
+}
+
+func (req *SizeChartRequest) Validate() error {
+	var errs []string
+
+	if strings.TrimSpace(req.Name) == "" {
+		errs = append(errs, "nome é obrigatório")
+	} else if len(req.Name) > 120 {
+		errs = append(errs, "nome deve ter no máximo 120 caracteres")
+	}
+	if err := validateRows(req.Rows); err != "" {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (req *UpdateSizeChartRequest) Validate() error {
+	var errs []string
+
+	if req.Name != nil && (strings.TrimSpace(*req.Name) == "" || len(*req.Name) > 120) {
+		errs = append(errs, "nome deve ter entre 1 e 120 caracteres")
+	}
+	if req.Rows != nil {
+		if err := validateRows(*req.Rows); err != "" {
+			errs = append(errs, err)
+		}
+	}
+	if req.NoCategory && req.CategoryID != nil {
+		errs = append(errs, "informe category_id ou no_category, não ambos")
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
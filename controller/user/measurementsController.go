@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetMyMeasurements — medidas do perfil usadas na recomendação de tamanho.
+func GetMyMeasurements(c *fiber.Ctx) error {
+	var user schemas.Users
+	if err := config.DB.First(&user, c.Locals("user_id").(uint64)).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Usuário não encontrado",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"measurements": toMeasurementsResponse(user),
+	})
+}
+
+// UpdateMyMeasurements atualiza as medidas do próprio perfil; 0 remove a medida.
+func UpdateMyMeasurements(c *fiber.Ctx) error {
+	req := UpdateMeasurementsRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Erro ao processar dados da requisição",
+			"details": err.Error(),
+		})
+	}
+
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	var user schemas.Users
+	if err := config.DB.First(&user, c.Locals("user_id").(uint64)).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Usuário não encontrado",
+		})
+	}
+
+	updates := make(map[string]interface{})
+	for column, value := range map[string]*float64{
+		"chest_cm":  req.ChestCm,
+		"height_cm": req.HeightCm,
+		"weight_kg": req.WeightKg,
+	} {
+		if value == nil {
+			continue
+		}
+		if *value == 0 {
+			updates[column] = nil
+		} else {
+			updates[column] = *value
+		}
+	}
+
+	if len(updates) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Nenhum campo para atualizar",
+		})
+	}
+
+	if err := config.DB.Model(&user).Updates(updates).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao atualizar medidas",
+			"details": err.Error(),
+		})
+	}
+	if err := config.DB.First(&user, user.ID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao buscar usuário atualizado",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":      "Medidas atualizadas com sucesso",
+		"measurements": toMeasurementsResponse(user),
+	})
+}
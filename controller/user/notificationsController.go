@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/notify"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetMyNotifications — canais em que o usuário aceita receber avisos da loja.
+func GetMyNotifications(c *fiber.Ctx) error {
+	var user schemas.Users
+	if err := config.DB.First(&user, c.Locals("user_id").(uint64)).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Usuário não encontrado",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"notifications": toNotificationsResponse(user),
+	})
+}
+
+// UpdateMyNotifications altera o opt-in; desligar um canal cancela os avisos pendentes nele.
+func UpdateMyNotifications(c *fiber.Ctx) error {
+	req := UpdateNotificationsRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Erro ao processar dados da requisição",
+			"details": err.Error(),
+		})
+	}
+
+	if req.WhatsApp == nil && req.Email == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Nenhum campo para atualizar",
+		})
+	}
+
+	var user schemas.Users
+	if err := config.DB.First(&user, c.Locals("user_id").(uint64)).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Usuário não encontrado",
+		})
+	}
+
+	if req.WhatsApp != nil && *req.WhatsApp && notify.NormalizePhone(user.Contact) == "" {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": "Cadastre um telefone válido para receber avisos por WhatsApp",
+		})
+	}
+
+	updates := make(map[string]interface{})
+	var disabled []schemas.AlertChannel
+	if req.WhatsApp != nil {
+		updates["notify_whatsapp"] = *req.WhatsApp
+		if !*req.WhatsApp {
+			disabled = append(disabled, schemas.AlertWhatsApp)
+		}
+	}
+	if req.Email != nil {
+		updates["notify_email"] = *req.Email
+		if !*req.Email {
+			disabled = append(disabled, schemas.AlertEmail)
+		}
+	}
+
+	if err := config.DB.Model(&user).Updates(updates).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao atualizar preferências de aviso",
+			"details": err.Error(),
+		})
+	}
+	if len(disabled) > 0 {
+		if err := config.DB.Where("user_id = ? AND status = ? AND channel IN ?", user.ID, schemas.AlertPending, disabled).
+			Delete(&schemas.StockSubscriptions{}).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Erro ao cancelar avisos pendentes",
+				"details": err.Error(),
+			})
+		}
+	}
+	if err := config.DB.First(&user, user.ID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao buscar usuário atualizado",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":       "Preferências de aviso atualizadas",
+		"notifications": toNotificationsResponse(user),
+	})
+}
@@ -4,6 +4,9 @@ import (
 	"errors"
 	"regexp"
 	"strings"
+	"time"
+
+	"backend_camisaria_store/schemas"
 )
 
 // CreateUserRequest representa o cadastro público na loja (sempre perfil cliente).
@@ -29,14 +32,43 @@ type UpdateUserRequest struct {
 	Password *string `json:"password,omitempty" validate:"omitempty,min=6"`
 }
 
+// UpdateMeasurementsRequest atualiza as medidas do perfil (0 remove a medida)
+type UpdateMeasurementsRequest struct {
+	ChestCm  *float64 `json:"chest_cm,omitempty"`
+	HeightCm *float64 `json:"height_cm,omitempty"`
+	WeightKg *float64 `json:"weight_kg,omitempty"`
+}
+
+// MeasurementsResponse representa as medidas do perfil
+type MeasurementsResponse struct {
+	ChestCm  *float64 `json:"chest_cm"`
+	HeightCm *float64 `json:"height_cm"`
+	WeightKg *float64 `json:"weight_kg"`
+}
+
+// UpdateNotificationsRequest liga ou desliga os avisos da loja por canal
+type UpdateNotificationsRequest struct {
+	WhatsApp *bool `json:"whatsapp,omitempty"`
+	Email    *bool `json:"email,omitempty"`
+}
+
+// NotificationsResponse representa o opt-in de avisos do perfil
+type NotificationsResponse struct {
+	WhatsApp bool   `json:"whatsapp"`
+	Email    bool   `json:"email"`
+	Contact  string `json:"contact,omitempty"`
+}
+
 // UserResponse representa a resposta da API para usuários
 type UserResponse struct {
-	ID        uint64 `json:"id"`
-	Name      string `json:"name"`
-	Email     string `json:"email"`
-	Contact   string `json:"contact,omitempty"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID              uint64     `json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	Contact         string     `json:"contact,omitempty"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at"`
+	CreatedAt       string     `json:"created_at"`
+	UpdatedAt       string     `json:"updated_at"`
 }
 
 // UserListResponse representa a resposta paginada para listagem de usuários
@@ -168,3 +200,40 @@ func (req *UpdateUserRequest) Validate() error {
 
 	return nil
 }
+
+// Validate confere se as medidas estão em faixas plausíveis
+func (req *UpdateMeasurementsRequest) Validate() error {
+	var errs []string
+
+	if req.ChestCm != nil && *req.ChestCm != 0 && (*req.ChestCm < 50 || *req.ChestCm > 200) {
+		errs = append(errs, "tórax deve estar entre 50 e 200 cm")
+	}
+	if req.HeightCm != nil && *req.HeightCm != 0 && (*req.HeightCm < 100 || *req.HeightCm > 230) {
+		errs = append(errs, "altura deve estar entre 100 e 230 cm")
+	}
+	if req.WeightKg != nil && *req.WeightKg != 0 && (*req.WeightKg < 30 || *req.WeightKg > 250) {
+		errs = append(errs, "peso deve estar entre 30 e 250 kg")
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+func toMeasurementsResponse(u schemas.Users) MeasurementsResponse {
+	return MeasurementsResponse{
+		ChestCm:  u.ChestCm,
+		HeightCm: u.HeightCm,
+		WeightKg: u.WeightKg,
+	}
+}
+
+func toNotificationsResponse(u schemas.Users) NotificationsResponse {
+	return NotificationsResponse{
+		WhatsApp: u.NotifyWhatsApp,
+		Email:    u.NotifyEmail,
+		Contact:  u.Contact,
+	}
+}
@@ -104,12 +104,14 @@ func CreateStaffUser(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message": "user created",
 		"user": UserResponse{
-			ID:        user.ID,
-			Name:      user.Name,
-			Email:     user.Email,
-			Contact:   user.Contact,
-			CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			ID:              user.ID,
+			Name:            user.Name,
+			Email:           user.Email,
+			Contact:         user.Contact,
+			EmailVerifiedAt: user.EmailVerifiedAt,
+			PhoneVerifiedAt: user.PhoneVerifiedAt,
+			CreatedAt:       user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			UpdatedAt:       user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		},
 	})
 }
@@ -157,12 +159,14 @@ func GetUsers(c *fiber.Ctx) error {
 	var userResponses []UserResponse
 	for _, user := range users {
 		userResponses = append(userResponses, UserResponse{
-			ID:        user.ID,
-			Name:      user.Name,
-			Email:     user.Email,
-			Contact:   user.Contact,
-			CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			ID:              user.ID,
+			Name:            user.Name,
+			Email:           user.Email,
+			Contact:         user.Contact,
+			EmailVerifiedAt: user.EmailVerifiedAt,
+			PhoneVerifiedAt: user.PhoneVerifiedAt,
+			CreatedAt:       user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			UpdatedAt:       user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		})
 	}
 
@@ -194,12 +198,14 @@ func GetUser(c *fiber.Ctx) error {
 	}
 
 	return c.JSON(UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		Contact:   user.Contact,
-		CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:              user.ID,
+		Name:            user.Name,
+		Email:           user.Email,
+		Contact:         user.Contact,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		PhoneVerifiedAt: user.PhoneVerifiedAt,
+		CreatedAt:       user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:       user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	})
 }
 
@@ -247,6 +253,9 @@ func UpdateUser(c *fiber.Ctx) error {
 	}
 	if req.Email != nil {
 		updates["email"] = *req.Email
+		if *req.Email != user.Email {
+			updates["email_verified_at"] = nil // novo e-mail precisa ser verificado
+		}
 	}
 	if req.Password != nil {
 		// Hash da nova senha
@@ -279,12 +288,14 @@ func UpdateUser(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"message": "Usuário atualizado com sucesso",
 		"user": UserResponse{
-			ID:        user.ID,
-			Name:      user.Name,
-			Email:     user.Email,
-			Contact:   user.Contact,
-			CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			ID:              user.ID,
+			Name:            user.Name,
+			Email:           user.Email,
+			Contact:         user.Contact,
+			EmailVerifiedAt: user.EmailVerifiedAt,
+			PhoneVerifiedAt: user.PhoneVerifiedAt,
+			CreatedAt:       user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			UpdatedAt:       user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		},
 	})
 }
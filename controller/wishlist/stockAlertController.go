@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/notify"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// CreateStockAlert inscreve o usuário no "avise-me" de um produto esgotado.
+// O canal escolhido precisa estar liberado no opt-in do perfil.
+func CreateStockAlert(c *fiber.Ctx) error {
+	req := CreateStockAlertRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Erro ao processar dados da requisição",
+			"details": err.Error(),
+		})
+	}
+
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	var user schemas.Users
+	if err := config.DB.First(&user, c.Locals("user_id").(uint64)).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Usuário não encontrado"})
+	}
+	switch req.Channel {
+	case schemas.AlertWhatsApp:
+		if !user.NotifyWhatsApp || notify.NormalizePhone(user.Contact) == "" {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error":   "Avisos por WhatsApp desativados",
+				"message": "Ative os avisos por WhatsApp e cadastre um telefone válido no perfil",
+			})
+		}
+	case schemas.AlertEmail:
+		if !user.NotifyEmail || user.Email == "" {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error":   "Avisos por e-mail desativados",
+				"message": "Ative os avisos por e-mail no perfil",
+			})
+		}
+	}
+
+	now := time.Now()
+	products, err := visibleProducts([]uint64{req.ProductID}, now)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar produto",
+		})
+	}
+	product, ok := products[req.ProductID]
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produto não encontrado"})
+	}
+	if product.StockQuantity > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":          "Produto disponível em estoque",
+			"stock_quantity": product.StockQuantity,
+		})
+	}
+
+	var sub schemas.StockSubscriptions
+	err = config.DB.Where("user_id = ? AND product_id = ?", user.ID, product.ID).First(&sub).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		sub = schemas.StockSubscriptions{
+			UserID:    user.ID,
+			ProductID: product.ID,
+			Channel:   req.Channel,
+			Status:    schemas.AlertPending,
+		}
+		err = config.DB.Create(&sub).Error
+	case err == nil:
+		// Inscrição antiga (já avisada ou com falha) volta a aguardar com o canal novo
+		err = config.DB.Model(&sub).Updates(map[string]interface{}{
+			"channel":     req.Channel,
+			"status":      schemas.AlertPending,
+			"attempts":    0,
+			"last_error":  "",
+			"notified_at": nil,
+		}).Error
+		if err == nil {
+			err = config.DB.First(&sub, sub.ID).Error
+		}
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao salvar aviso de estoque",
+			"details": err.Error(),
+		})
+	}
+
+	summary := toWishlistProduct(product, now)
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":     "Avisaremos quando o produto voltar ao estoque",
+		"stock_alert": toStockAlertResponse(sub, &summary),
+	})
+}
+
+// ListStockAlerts — inscrições "avise-me" do usuário, mais recentes primeiro.
+func ListStockAlerts(c *fiber.Ctx) error {
+	var subs []schemas.StockSubscriptions
+	if err := config.DB.Where("user_id = ?", c.Locals("user_id").(uint64)).
+		Order("id DESC").Find(&subs).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar avisos de estoque",
+		})
+	}
+
+	ids := make([]uint64, 0, len(subs))
+	for _, s := range subs {
+		ids = append(ids, s.ProductID)
+	}
+	now := time.Now()
+	products, err := visibleProducts(ids, now)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar produtos dos avisos",
+		})
+	}
+
+	responses := make([]StockAlertResponse, 0, len(subs))
+	for _, s := range subs {
+		var summary *WishlistProduct
+		if p, ok := products[s.ProductID]; ok {
+			wp := toWishlistProduct(p, now)
+			summary = &wp
+		}
+		responses = append(responses, toStockAlertResponse(s, summary))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"stock_alerts": responses})
+}
+
+// DeleteStockAlert cancela a inscrição do próprio usuário.
+func DeleteStockAlert(c *fiber.Ctx) error {
+	subID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+
+	result := config.DB.Where("id = ? AND user_id = ?", subID, c.Locals("user_id").(uint64)).
+		Delete(&schemas.StockSubscriptions{})
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao cancelar aviso de estoque",
+			"details": result.Error.Error(),
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Aviso de estoque não encontrado"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Aviso de estoque cancelado",
+	})
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/catalog"
+	"backend_camisaria_store/service/minio"
+)
+
+type AddWishlistRequest struct {
+	ProductID uint64 `json:"product_id"`
+}
+
+type CreateStockAlertRequest struct {
+	ProductID uint64               `json:"product_id"`
+	Channel   schemas.AlertChannel `json:"channel"`
+}
+
+// WishlistProduct é o resumo do produto exibido na lista de desejos e nos avisos.
+type WishlistProduct struct {
+	ID       uint64  `json:"id"`
+	Name     string  `json:"name"`
+	Slug     string  `json:"slug"`
+	Size     string  `json:"size"`
+	Color    string  `json:"color"`
+	Price    float64 `json:"price"`
+	Image    string  `json:"image,omitempty"`
+	InStock  bool    `json:"in_stock"`
+	Quantity int     `json:"stock_quantity"`
+}
+
+type WishlistItemResponse struct {
+	ID         uint64               `json:"id"`
+	Product    WishlistProduct      `json:"product"`
+	StockAlert *schemas.AlertStatus `json:"stock_alert,omitempty"` // inscrição "avise-me" do produto
+	CreatedAt  string               `json:"created_at"`
+}
+
+type StockAlertResponse struct {
+	ID         uint64               `json:"id"`
+	Product    *WishlistProduct     `json:"product,omitempty"`
+	Channel    schemas.AlertChannel `json:"channel"`
+	Status     schemas.AlertStatus  `json:"status"`
+	NotifiedAt *time.Time           `json:"notified_at,omitempty"`
+	CreatedAt  string               `json:"created_at"`
+}
+
+func toWishlistProduct(p schemas.Products, now time.Time) WishlistProduct {
+	product := WishlistProduct{
+		ID:       p.ID,
+		Name:     p.Name,
+		Size:     p.Size,
+		Color:    p.Color,
+		Price:    catalog.EffectivePrice(p, now),
+		InStock:  p.StockQuantity > 0,
+		Quantity: p.StockQuantity,
+	}
+	if p.Slug != nil {
+		product.Slug = *p.Slug
+	}
+	if images := minio.JsonToStringSlice(p.Images); len(images) > 0 {
+		product.Image = minio.MediaURL(images[0])
+	}
+	return product
+}
+
+func toStockAlertResponse(s schemas.StockSubscriptions, product *WishlistProduct) StockAlertResponse {
+	return StockAlertResponse{
+		ID:         s.ID,
+		Product:    product,
+		Channel:    s.Channel,
+		Status:     s.Status,
+		NotifiedAt: s.NotifiedAt,
+		CreatedAt:  s.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func (req *AddWishlistRequest) Validate() error {
+	if req.ProductID == 0 {
+		return errors.New("product_id é obrigatório")
+	}
+	return nil
+}
+
+func (req *CreateStockAlertRequest) Validate() error {
+	var errs []string
+
+	if req.ProductID == 0 {
+		errs = append(errs, "product_id é obrigatório")
+	}
+	req.Channel = schemas.AlertChannel(strings.ToLower(strings.TrimSpace(string(req.Channel))))
+	if req.Channel != schemas.AlertWhatsApp && req.Channel != schemas.AlertEmail {
+		errs = append(errs, "canal deve ser whatsapp ou email")
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
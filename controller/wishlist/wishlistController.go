@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/catalog"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm/clause"
+)
+
+// ListWishlist — produtos salvos pelo usuário; itens despublicados ficam de fora.
+func ListWishlist(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uint64)
+
+	var items []schemas.WishlistItems
+	if err := config.DB.Where("user_id = ?", userID).Order("id DESC").Find(&items).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar lista de desejos",
+		})
+	}
+
+	ids := make([]uint64, 0, len(items))
+	for _, it := range items {
+		ids = append(ids, it.ProductID)
+	}
+	now := time.Now()
+	products, err := visibleProducts(ids, now)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar produtos da lista",
+		})
+	}
+
+	var subs []schemas.StockSubscriptions
+	if len(ids) > 0 {
+		if err := config.DB.Where("user_id = ? AND product_id IN ?", userID, ids).Find(&subs).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro ao buscar avisos de estoque",
+			})
+		}
+	}
+	alerts := make(map[uint64]schemas.AlertStatus, len(subs))
+	for _, s := range subs {
+		alerts[s.ProductID] = s.Status
+	}
+
+	responses := make([]WishlistItemResponse, 0, len(items))
+	for _, it := range items {
+		p, ok := products[it.ProductID]
+		if !ok {
+			continue
+		}
+		response := WishlistItemResponse{
+			ID:        it.ID,
+			Product:   toWishlistProduct(p, now),
+			CreatedAt: it.CreatedAt.Format(time.RFC3339),
+		}
+		if status, ok := alerts[it.ProductID]; ok {
+			response.StockAlert = &status
+		}
+		responses = append(responses, response)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"items": responses})
+}
+
+// AddToWishlist salva um produto publicado; repetir o mesmo produto não duplica o item.
+func AddToWishlist(c *fiber.Ctx) error {
+	req := AddWishlistRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Erro ao processar dados da requisição",
+			"details": err.Error(),
+		})
+	}
+
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	now := time.Now()
+	products, err := visibleProducts([]uint64{req.ProductID}, now)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar produto",
+		})
+	}
+	product, ok := products[req.ProductID]
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produto não encontrado"})
+	}
+
+	item := schemas.WishlistItems{UserID: c.Locals("user_id").(uint64), ProductID: product.ID}
+	if err := config.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&item).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao salvar na lista de desejos",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Produto salvo na lista de desejos",
+		"product": toWishlistProduct(product, now),
+	})
+}
+
+// RemoveFromWishlist tira o produto da lista do usuário.
+func RemoveFromWishlist(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("productId"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ID inválido"})
+	}
+
+	result := config.DB.Where("user_id = ? AND product_id = ?", c.Locals("user_id").(uint64), productID).
+		Delete(&schemas.WishlistItems{})
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao remover da lista de desejos",
+			"details": result.Error.Error(),
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Produto não está na lista de desejos"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Produto removido da lista de desejos",
+	})
+}
+
+// visibleProducts carrega os produtos visíveis na loja, com o estoque dos kits calculado.
+func visibleProducts(ids []uint64, now time.Time) (map[uint64]schemas.Products, error) {
+	result := make(map[uint64]schemas.Products, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+	var products []schemas.Products
+	if err := config.DB.Scopes(catalog.VisibleAt(now)).Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	if err := catalog.ApplyBundleStock(config.DB, products); err != nil {
+		return nil, err
+	}
+	for _, p := range products {
+		result[p.ID] = p
+	}
+	return result, nil
+}
@@ -3,14 +3,25 @@ package main
 import (
 	"backend_camisaria_store/config"
 	"backend_camisaria_store/router"
+	"backend_camisaria_store/service/minio"
+	"backend_camisaria_store/service/scheduler"
 	"fmt"
+	"os"
 )
 
 func main() {
 	err := config.Init()
 	if err != nil {
-		fmt.Printf("config initialize error %v", err)
+		// Sem banco/armazenamento os jobs e as rotas só falhariam
+		fmt.Printf("config initialize error %v\n", err)
+		os.Exit(1)
 	}
+	// Imagens gravadas com a URL completa passam a guardar só a key do objeto
+	if err := minio.MigrateImageKeys(config.DB); err != nil {
+		fmt.Printf("image keys migration error %v\n", err)
+		os.Exit(1)
+	}
+	scheduler.Start(config.DB)
 	router.Initialize()
 
 }
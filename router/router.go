@@ -3,14 +3,22 @@ package router
 import (
 	"fmt"
 	"log"
+	"os"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 )
 
 func Initialize() {
+	// c.IP() só lê o cabeçalho do proxy em requisições vindas de TRUSTED_PROXIES; nas demais
+	// vale o IP da conexão (o cabeçalho enviado pelo cliente é ignorado)
 	app := fiber.New(fiber.Config{
-		AppName: "Santiago store backend",
+		AppName:                 "Santiago store backend",
+		ProxyHeader:             proxyHeader(),
+		EnableTrustedProxyCheck: true,
+		EnableIPValidation:      true,
+		TrustedProxies:          trustedProxies(),
 	})
 
 	app.Use(cors.New(cors.Config{
@@ -50,3 +58,24 @@ func Initialize() {
 
 	app.Listen(":4041")
 }
+
+// proxyHeader é o cabeçalho com o IP do cliente definido pelo proxy reverso
+// (PROXY_HEADER, padrão X-Real-IP). Precisa ter um único valor, gravado pelo proxy:
+// no X-Forwarded-For o proxy acrescenta ao que o cliente enviou e o primeiro IP é forjável.
+func proxyHeader() string {
+	if header := strings.TrimSpace(os.Getenv("PROXY_HEADER")); header != "" {
+		return header
+	}
+	return "X-Real-IP"
+}
+
+// trustedProxies lê TRUSTED_PROXIES: IPs ou faixas CIDR separados por vírgula.
+func trustedProxies() []string {
+	var proxies []string
+	for _, p := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			proxies = append(proxies, p)
+		}
+	}
+	return proxies
+}
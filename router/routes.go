@@ -1,12 +1,22 @@
 package router
 
 import (
+	"strings"
+
+	"backend_camisaria_store/config"
 	authcontroller "backend_camisaria_store/controller/auth"
+	categoryController "backend_camisaria_store/controller/categories"
 	clientController "backend_camisaria_store/controller/clients"
+	orderController "backend_camisaria_store/controller/orders"
 	controller "backend_camisaria_store/controller/products"
+	quoteController "backend_camisaria_store/controller/quotes"
+	reviewController "backend_camisaria_store/controller/reviews"
+	sizeChartController "backend_camisaria_store/controller/sizecharts"
 	userController "backend_camisaria_store/controller/user"
 	instanceController "backend_camisaria_store/controller/whatsapp/instance"
+	wishlistController "backend_camisaria_store/controller/wishlist"
 	"backend_camisaria_store/service/minio"
+	"backend_camisaria_store/service/storage"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -17,6 +27,18 @@ func InitializeRoutes(app *fiber.App) {
 
 	// Rotas públicas - não precisam de autenticação
 
+	app.Get("/sitemap.xml", controller.Sitemap)
+
+	// Arquivos do armazenamento em disco (STORAGE_DRIVER=local); artes privadas e envios
+	// pendentes ficam de fora
+	if local, ok := storage.Unwrap(config.Storage).(*storage.LocalStorage); ok {
+		app.Static("/media", local.Root(), fiber.Static{
+			Next: func(c *fiber.Ctx) bool {
+				return !minio.IsPublicMediaKey(strings.TrimPrefix(c.Path(), "/media/"))
+			},
+		})
+	}
+
 	public := app.Group("/public")
 	public.Get("/", func(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -24,22 +46,113 @@ func InitializeRoutes(app *fiber.App) {
 		})
 	})
 
+	public.Get("/media/*", minio.ServeMedia)      // proxy de imagens com cache longo (MEDIA_PUBLIC_URL)
+	public.Get("/files/*", minio.ServeSignedFile) // arquivos privados por link assinado
 	public.Post("/login", authcontroller.LoginUser)
+	public.Post("/refresh", authcontroller.RefreshSession)         // troca o refresh token (rotativo)
+	public.Post("/password/forgot", authcontroller.ForgotPassword) // código por e-mail ou WhatsApp
+	public.Post("/password/reset", authcontroller.ResetPassword)
+	public.Post("/verify-email", authcontroller.VerifyEmail) // token do link enviado por e-mail
 	public.Post("/register", userController.CreateUser)
 
 	// Loja pública — produtos publicados na página principal
 	public.Get("/store/products", controller.ListPublishedProducts)
+	public.Get("/store/products/suggest", controller.SuggestProducts)   // autocomplete
+	public.Get("/store/products/:slug", controller.GetPublishedProduct) // página do produto (slugs antigos redirecionam)
+	public.Get("/store/products/:slug/size-chart", sizeChartController.GetProductSizeChart)
+	public.Get("/store/products/:slug/reviews", reviewController.ListProductReviews)
+	public.Get("/store/products/:slug/related", controller.GetRelatedProducts) // relacionados e comprados juntos
+	public.Get("/store/categories", categoryController.ListCategoryTree)
+	public.Get("/store/collections", categoryController.ListPublicCollections)
+	public.Get("/store/collections/:slug/products", controller.ListCollectionProducts)
 
 	// Rotas protegidas - requerem autenticação
 	admin := app.Group("/api/admin", authcontroller.AuthMiddleware, authcontroller.AdminMiddlware)
 	admin.Post("/users", userController.CreateStaffUser) // Criar admin/user interno (Postman)
 	admin.Delete("/:id", userController.DeleteUser)      // Apenas admins podem deletar
 
+	// Categorias (árvore) e coleções
+	categories := admin.Group("/categories")
+	categories.Get("/", categoryController.ListCategories)
+	categories.Post("/", categoryController.CreateCategory)
+	categories.Put("/:id", categoryController.UpdateCategory)
+	categories.Delete("/:id", categoryController.DeleteCategory)
+	categories.Post("/:id/image", categoryController.UploadCategoryImage)
+
+	collections := admin.Group("/collections")
+	collections.Get("/", categoryController.ListCollections)
+	collections.Post("/", categoryController.CreateCollection)
+	collections.Get("/:id", categoryController.GetCollection)
+	collections.Put("/:id", categoryController.UpdateCollection)
+	collections.Delete("/:id", categoryController.DeleteCollection)
+	collections.Put("/:id/products", categoryController.SetCollectionProducts)
+
+	// Reconciliação do bucket: GET só relata (dry run), POST apaga os órfãos
+	admin.Get("/storage/orphans", minio.StorageReconcileReport)
+	admin.Post("/storage/orphans/cleanup", minio.CleanupStorageOrphans)
+
+	// Histórico de preços (gráfico do admin)
+	admin.Get("/products/:id/price-history", controller.GetProductPriceHistory)
+
+	// Operações de catálogo restritas à equipe
+	adminProducts := admin.Group("/products")
+	adminProducts.Get("/trash", controller.ListTrashedProducts) // lixeira
+	adminProducts.Post("/:id/restore", controller.RestoreProduct)
+	adminProducts.Delete("/:id/purge", controller.PurgeProduct) // Excluir definitivamente
+	adminProducts.Get("/:id/revisions", controller.ListProductRevisions)
+	adminProducts.Get("/:id/revisions/:revisionId", controller.GetProductRevision)
+	adminProducts.Post("/:id/revisions/:revisionId/restore", controller.RestoreProductRevision)
+	adminProducts.Put("/:id/bundle", controller.SetProductBundle)
+	adminProducts.Delete("/:id/bundle", controller.RemoveProductBundle)
+	adminProducts.Get("/:id/related", controller.GetProductRelations)
+	adminProducts.Put("/:id/related", controller.SetProductRelations)
+	adminProducts.Post("/:id/images/presign", minio.PresignProductImage) // envio direto ao bucket
+	adminProducts.Post("/:id/images/confirm", minio.ConfirmProductImage)
+	adminProducts.Put("/:id/images/order", controller.ReorderProductImages)
+	adminProducts.Put("/:id/images/:imageId", controller.UpdateProductImage)
+	adminProducts.Delete("/:id/images/:imageId", controller.DeleteProductImage)
+	// Rota para deletar múltiplas imagens (envia lista de URLs no body); antes de /:id
+	adminProducts.Post("/delete-images", minio.DeleteImagesMinio)
+	adminProducts.Post("/:id", minio.UploadImgesProduct)
+
+	// Tabelas de medidas
+	sizeCharts := admin.Group("/size-charts")
+	sizeCharts.Get("/", sizeChartController.ListSizeCharts)
+	sizeCharts.Post("/", sizeChartController.CreateSizeChart)
+	sizeCharts.Get("/:id", sizeChartController.GetSizeChart)
+	sizeCharts.Put("/:id", sizeChartController.UpdateSizeChart)
+	sizeCharts.Delete("/:id", sizeChartController.DeleteSizeChart)
+
+	// Orçamentos B2B (fardamentos) e produção dos pedidos sob encomenda
+	adminQuotes := admin.Group("/quotes")
+	adminQuotes.Get("/", quoteController.ListQuotes)
+	adminQuotes.Get("/:id", quoteController.GetQuote)
+	adminQuotes.Put("/:id/price", quoteController.PriceQuote)
+	adminQuotes.Post("/:id/convert", quoteController.ConvertQuote)
+	admin.Put("/orders/:id/production", quoteController.UpdateOrderProduction)
+	admin.Get("/orders/:id/production-sheet", orderController.GetProductionSheet)
+	admin.Post("/orders/:id/delivered", orderController.MarkOrderDelivered)
+
+	// Moderação de avaliações
+	adminReviews := admin.Group("/reviews")
+	adminReviews.Get("/", reviewController.ListReviewQueue)
+	adminReviews.Post("/:id/approve", reviewController.ApproveReview)
+	adminReviews.Post("/:id/reject", reviewController.RejectReview)
+
 	// Grupo geral para /api/* (exceto /api/auth/* que já foi definido acima)
 	protected := app.Group("/api", authcontroller.AuthMiddleware, authcontroller.UserMiddleware)
+	protected.Post("/logout", authcontroller.Logout)
 
 	// Rotas de usuários protegidas
 	usersProtected := protected.Group("/users")
+	usersProtected.Get("/me/measurements", userController.GetMyMeasurements)
+	usersProtected.Put("/me/measurements", userController.UpdateMyMeasurements)
+	usersProtected.Get("/me/notifications", userController.GetMyNotifications)
+	usersProtected.Put("/me/notifications", userController.UpdateMyNotifications)
+	usersProtected.Get("/me/verification", authcontroller.GetMyVerification)
+	usersProtected.Post("/me/verification/email", authcontroller.SendEmailVerification)
+	usersProtected.Post("/me/verification/phone", authcontroller.SendPhoneVerification)
+	usersProtected.Post("/me/verification/phone/confirm", authcontroller.ConfirmPhoneVerification)
 	usersProtected.Get("/", userController.GetUsers)      // Apenas usuários autenticados
 	usersProtected.Get("/:id", userController.GetUser)    // Apenas usuários autenticados
 	usersProtected.Put("/:id", userController.UpdateUser) // Apenas usuários autenticados
@@ -51,13 +164,46 @@ func InitializeRoutes(app *fiber.App) {
 	products.Post("/", controller.CreateProduct)
 	products.Get("/", controller.ListProducts)
 	products.Get("/:id", controller.GetProduct)
+	products.Get("/:id/bundle", controller.GetProductBundle)
+	products.Get("/:id/images", controller.ListProductImages)
 	products.Put("/:id", controller.UpdateProduct)    // Atualizar produto
-	products.Delete("/:id", controller.DeleteProduct) // Deletar produto
-
-	products.Post("/:id", minio.UploadImgesProduct)
-
-	// Rota para deletar múltiplas imagens (envia lista de URLs no body)
-	products.Post("/delete-images", minio.DeleteImagesMinio)
+	products.Delete("/:id", controller.DeleteProduct) // Mover para a lixeira
+
+	// Recomendação de tamanho pelas medidas do perfil
+	protected.Get("/store/products/:slug/size-recommendation", sizeChartController.RecommendProductSize)
+
+	// Pedidos da loja
+	orders := protected.Group("/orders")
+	orders.Post("/", authcontroller.VerifiedMiddleware, orderController.CreateOrder)
+	orders.Get("/", orderController.ListMyOrders)
+	orders.Get("/:id", orderController.GetOrder)
+
+	// Avaliações de compra verificada
+	reviews := protected.Group("/reviews")
+	reviews.Post("/", reviewController.CreateReview)
+	reviews.Get("/", reviewController.ListMyReviews)
+	reviews.Get("/pending-items", reviewController.ListReviewableItems)
+	reviews.Post("/:id/photos", reviewController.UploadReviewPhotos)
+
+	// Lista de desejos e avisos de volta ao estoque
+	wishlist := protected.Group("/wishlist")
+	wishlist.Get("/", wishlistController.ListWishlist)
+	wishlist.Post("/", wishlistController.AddToWishlist)
+	wishlist.Delete("/:productId", wishlistController.RemoveFromWishlist)
+
+	stockAlerts := protected.Group("/stock-alerts")
+	stockAlerts.Post("/", wishlistController.CreateStockAlert)
+	stockAlerts.Get("/", wishlistController.ListStockAlerts)
+	stockAlerts.Delete("/:id", wishlistController.DeleteStockAlert)
+
+	// Orçamentos da empresa logada
+	quotes := protected.Group("/quotes")
+	quotes.Post("/", quoteController.CreateQuote)
+	quotes.Get("/", quoteController.ListMyQuotes)
+	quotes.Get("/:id", quoteController.GetQuote)
+	quotes.Post("/:id/artworks", quoteController.UploadQuoteArtwork)
+	quotes.Post("/:id/approve", quoteController.ApproveQuote)
+	quotes.Post("/:id/reject", quoteController.RejectQuote)
 
 	// Rotas de clientes
 	clients := protected.Group("/clients")
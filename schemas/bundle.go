@@ -0,0 +1,13 @@
+package schemas
+
+import "time"
+
+// BundleItems compõe um produto kit (Products.IsBundle) a partir de outros produtos.
+// O estoque do kit é calculado pelos componentes; o kit não tem estoque próprio.
+type BundleItems struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement"`
+	BundleID    uint64    `gorm:"not null;uniqueIndex:uni_bundle_component"`
+	ComponentID uint64    `gorm:"not null;uniqueIndex:uni_bundle_component;index"`
+	Quantity    int       `gorm:"not null;default:1"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,18 @@
+package schemas
+
+import "time"
+
+// Categories forma a árvore de categorias do catálogo (ex.: Masculino → Social → Manga longa).
+type Categories struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement"`
+	ParentID    *uint64   `gorm:"index"`
+	Name        string    `gorm:"type:varchar(100);not null"`
+	Slug        string    `gorm:"type:varchar(120);uniqueIndex:uni_categories_slug;not null"`
+	Description string    `gorm:"type:text"`
+	Gender      string    `gorm:"type:varchar(1)"` // M, F ou U; vazio herda da categoria pai
+	Position    int       `gorm:"default:0"`
+	ImageURL    string    `gorm:"type:varchar(500)"` // key do upload (categories/) ou URL externa
+	IsActive    bool      `gorm:"default:true"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,44 @@
+package schemas
+
+import "time"
+
+type CollectionType string
+
+const (
+	CollectionManual CollectionType = "manual" // produtos escolhidos um a um
+	CollectionRule   CollectionType = "rule"   // produtos selecionados pelas regras
+)
+
+// CollectionRules define a seleção automática de produtos de uma coleção por regra.
+type CollectionRules struct {
+	CategoryID        *uint64  `json:"category_id,omitempty"`
+	Tags              []string `json:"tags,omitempty"`
+	OnlyPromotional   bool     `json:"only_promotional,omitempty"`
+	MinPrice          *float64 `json:"min_price,omitempty"`
+	MaxPrice          *float64 `json:"max_price,omitempty"`
+	CreatedWithinDays int      `json:"created_within_days,omitempty"`
+}
+
+// Collections agrupa produtos para campanhas da loja ("Lançamentos", "Dia dos Pais").
+type Collections struct {
+	ID          uint64          `gorm:"primaryKey;autoIncrement"`
+	Name        string          `gorm:"type:varchar(100);not null"`
+	Slug        string          `gorm:"type:varchar(120);uniqueIndex:uni_collections_slug;not null"`
+	Description string          `gorm:"type:text"`
+	Type        CollectionType  `gorm:"type:varchar(20);not null;default:'manual'"`
+	Rules       CollectionRules `gorm:"type:json;serializer:json"`
+	Position    int             `gorm:"default:0"`
+	ImageURL    string          `gorm:"type:varchar(500)"`
+	IsActive    bool            `gorm:"default:true"`
+	CreatedAt   time.Time       `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time       `gorm:"autoUpdateTime"`
+}
+
+// CollectionProducts liga produtos às coleções manuais, na ordem de exibição.
+type CollectionProducts struct {
+	ID           uint64    `gorm:"primaryKey;autoIncrement"`
+	CollectionID uint64    `gorm:"not null;uniqueIndex:uni_collection_product"`
+	ProductID    uint64    `gorm:"not null;uniqueIndex:uni_collection_product"`
+	Position     int       `gorm:"default:0"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
@@ -17,14 +17,29 @@ const (
 	DeliveryDelivery DeliveryType = "delivery"
 )
 
+// ProductionStatus acompanha a confecção de pedidos sob encomenda (orçamentos B2B).
+type ProductionStatus string
+
+const (
+	ProductionPending    ProductionStatus = "pending"
+	ProductionCutting    ProductionStatus = "cutting"
+	ProductionEmbroidery ProductionStatus = "embroidery"
+	ProductionFinishing  ProductionStatus = "finishing"
+	ProductionReady      ProductionStatus = "ready"
+	ProductionDelivered  ProductionStatus = "delivered"
+)
+
 type Orders struct {
-	ID            uint64        `gorm:"primaryKey;autoIncrement"`
-	ClientID      uint64        `gorm:"not null"`
-	OrderNumber   string        `gorm:"unique;not null"`
-	Value         float64       `gorm:"type:decimal(10,2);not null"`
-	Originalvalue float64       `gorm:"type:decimal(10,2);not null"`
-	StatusPayment StatusPayment `gorm:"type:enum('pending','paid','failed');default:'pending'"`
-	DeliveryType  DeliveryType  `gorm:"type:enum('pickup','delivery');default:'pickup'"`
-	CreatedAt     time.Time     `gorm:"autoCreateTime"`
-	UpdatedAt     time.Time     `gorm:"autoUpdateTime"`
+	ID               uint64            `gorm:"primaryKey;autoIncrement"`
+	ClientID         uint64            `gorm:"not null"`
+	OrderNumber      string            `gorm:"unique;not null"`
+	Value            float64           `gorm:"type:decimal(10,2);not null"`
+	Originalvalue    float64           `gorm:"type:decimal(10,2);not null"`
+	StatusPayment    StatusPayment     `gorm:"type:enum('pending','paid','failed');default:'pending'"`
+	DeliveryType     DeliveryType      `gorm:"type:enum('pickup','delivery');default:'pickup'"`
+	QuoteID          *uint64           `gorm:"index"`                                                                       // pedido gerado a partir de orçamento
+	ProductionStatus *ProductionStatus `gorm:"type:enum('pending','cutting','embroidery','finishing','ready','delivered')"` // só pedidos sob encomenda; nil nos demais
+	DeliveredAt      *time.Time        // entrega confirmada; libera avaliações dos itens
+	CreatedAt        time.Time         `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time         `gorm:"autoUpdateTime"`
 }
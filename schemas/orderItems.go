@@ -5,11 +5,15 @@ import "time"
 type OrderItems struct {
 	ID            uint64  `gorm:"primaryKey;autoIncrement"`
 	OrderID       uint64  `gorm:"not null"`
+	ParentItemID  *uint64 `gorm:"index"` // item do kit ao qual este componente pertence
 	ProductID     uint64  `gorm:"not null"`
 	Quantity      int     `gorm:"not null"`
 	Price         float64 `gorm:"type:decimal(10,2);not null"`
 	OriginalPrice float64 `gorm:"type:decimal(10,2);not null"`
 
+	Personalization      []PersonalizationValue `gorm:"type:json;serializer:json"`
+	PersonalizationPrice float64                `gorm:"type:decimal(10,2);default:0"` // incluído em Price
+
 	CreatedAt time.Time `gorm:"autoCreateTime"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime"`
 }
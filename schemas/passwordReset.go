@@ -0,0 +1,18 @@
+package schemas
+
+import "time"
+
+// PasswordResets registra cada pedido de redefinição de senha. Só o hash do código vai para
+// o banco; o código vale uma vez, até ExpiresAt ou MaxAttempts tentativas erradas.
+// Pedidos para e-mails sem conta ficam com UserID nulo e contam só no limite por IP.
+type PasswordResets struct {
+	ID        uint64     `gorm:"primaryKey;autoIncrement"`
+	UserID    *uint64    `gorm:"index"`
+	Channel   string     `gorm:"type:varchar(20)"` // email ou whatsapp
+	CodeHash  string     `gorm:"type:char(64)"`
+	IP        string     `gorm:"type:varchar(45);index"`
+	Attempts  int        `gorm:"not null;default:0"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time // usado ou invalidado por um pedido mais novo
+	CreatedAt time.Time  `gorm:"autoCreateTime;index"`
+}
@@ -0,0 +1,20 @@
+package schemas
+
+import "time"
+
+// PendingUploads registra as URLs pré-assinadas emitidas para envio direto ao bucket.
+// O objeto fica em uploads/ até a confirmação, que gera as versões e cria a ProductImages;
+// uploads não confirmados são apagados pelo job pending-uploads.
+type PendingUploads struct {
+	ID          uint64     `gorm:"primaryKey;autoIncrement"`
+	ProductID   uint64     `gorm:"not null;index"`
+	UserID      uint64     `gorm:"not null;index"`
+	ObjectKey   string     `gorm:"type:varchar(255);uniqueIndex:uni_pending_upload_key;not null"`
+	ContentType string     `gorm:"type:varchar(50);not null"`
+	Size        int64      `gorm:"not null"` // declarado no pedido; o objeto enviado precisa ter o mesmo tamanho
+	AltText     string     `gorm:"type:varchar(255)"`
+	Color       string     `gorm:"type:varchar(50)"`
+	ExpiresAt   time.Time  `gorm:"not null;index"` // validade da URL de envio
+	ConfirmedAt *time.Time // confirmação em andamento (reserva contra confirmações concorrentes)
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,22 @@
+package schemas
+
+// PersonalizationField é uma opção de personalização declarada no produto
+// (ex.: iniciais bordadas no punho da camisa social).
+type PersonalizationField struct {
+	Key        string   `json:"key"`   // identificador enviado no pedido (ex.: "iniciais")
+	Label      string   `json:"label"` // texto exibido na loja
+	MaxLength  int      `json:"max_length"`
+	Required   bool     `json:"required"`
+	Fonts      []string `json:"fonts,omitempty"`  // vazio: fonte padrão da produção
+	Colors     []string `json:"colors,omitempty"` // cores de linha disponíveis
+	ExtraPrice float64  `json:"extra_price"`      // acréscimo por peça quando preenchido
+}
+
+// PersonalizationValue é o valor escolhido pelo cliente para um campo, por item do pedido.
+type PersonalizationValue struct {
+	Key   string `json:"key"`
+	Label string `json:"label,omitempty"` // copiado do produto para a ficha de produção
+	Value string `json:"value"`
+	Font  string `json:"font,omitempty"`
+	Color string `json:"color,omitempty"`
+}
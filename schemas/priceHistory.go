@@ -0,0 +1,27 @@
+package schemas
+
+import "time"
+
+type PriceSource string
+
+const (
+	PriceSourceCreate   PriceSource = "create"
+	PriceSourceUpdate   PriceSource = "update"
+	PriceSourceRestore  PriceSource = "restore"
+	PriceSourceSchedule PriceSource = "schedule" // promoção iniciada/encerrada pela janela
+	PriceSourceBackfill PriceSource = "backfill" // preço vigente quando o histórico começou
+)
+
+// ProductPriceHistory registra cada mudança de preço do produto: o preço cheio,
+// o promocional e o efetivamente cobrado a partir de CreatedAt.
+type ProductPriceHistory struct {
+	ID               uint64      `gorm:"primaryKey;autoIncrement"`
+	ProductID        uint64      `gorm:"not null;index:idx_price_history_product_date"`
+	Price            float64     `gorm:"type:decimal(10,2);not null"`
+	PromotionalPrice *float64    `gorm:"type:decimal(10,2)"`
+	PromotionActive  bool        `gorm:"default:false"`
+	EffectivePrice   float64     `gorm:"type:decimal(10,2);not null"`
+	Source           PriceSource `gorm:"type:varchar(20);not null"`
+	UserID           *uint64
+	CreatedAt        time.Time `gorm:"autoCreateTime;index:idx_price_history_product_date"`
+}
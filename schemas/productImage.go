@@ -0,0 +1,31 @@
+package schemas
+
+import "time"
+
+// ProductImages é a galeria do produto. Products.Images continua com as keys na ordem
+// de exibição (capa primeiro) e é regravado a partir desta tabela (catalog.SyncProductImages).
+type ProductImages struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement"`
+	ProductID uint64    `gorm:"not null;index:idx_product_images_position"`
+	URL       string    `gorm:"type:varchar(512);not null"` // key do objeto; URL completa só em imagens externas
+	ObjectKey string    `gorm:"type:varchar(512)"`
+	Position  int       `gorm:"default:0;index:idx_product_images_position"`
+	IsCover   bool      `gorm:"default:false"`
+	AltText   string    `gorm:"type:varchar(255)"`
+	Color     string    `gorm:"type:varchar(50)"` // vazio = vale para todas as cores
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+
+	// Versões redimensionadas geradas no upload (thumb, card, zoom); URL é o JPEG do zoom.
+	// Vazio em imagens anteriores ao processamento.
+	Renditions map[string]ImageRendition `gorm:"type:json;serializer:json"`
+}
+
+// ImageRendition é uma versão redimensionada da imagem. Hoje só o JPEG é gerado; WebP
+// fica vazio (preenchido apenas em imagens enviadas quando havia versão WebP).
+type ImageRendition struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	JPEG   string `json:"jpeg"`
+	WebP   string `json:"webp,omitempty"`
+}
@@ -0,0 +1,25 @@
+package schemas
+
+import "time"
+
+type RevisionAction string
+
+const (
+	RevisionCreate  RevisionAction = "create"
+	RevisionUpdate  RevisionAction = "update"
+	RevisionDelete  RevisionAction = "delete"
+	RevisionRestore RevisionAction = "restore"
+)
+
+// ProductRevisions registra cada alteração de produto feita pela API: quem, quando,
+// os campos alterados (antes/depois) e o estado completo do produto após a alteração.
+type ProductRevisions struct {
+	ID        uint64         `gorm:"primaryKey;autoIncrement"`
+	ProductID uint64         `gorm:"not null;index"`
+	Action    RevisionAction `gorm:"type:varchar(20);not null"`
+	UserID    *uint64        `gorm:"index"`
+	UserName  string         `gorm:"type:varchar(255)"`
+	Changes   []byte         `gorm:"type:json"` // {"coluna": {"old": ..., "new": ...}}
+	Snapshot  []byte         `gorm:"type:json"` // Products serializado após a alteração
+	CreatedAt time.Time      `gorm:"autoCreateTime;index"`
+}
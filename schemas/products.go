@@ -2,8 +2,12 @@ package schemas
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
+// Category guarda o slug da categoria do produto (ver Categories); as constantes
+// são as categorias raiz criadas na primeira inicialização.
 type Category string
 
 const (
@@ -21,28 +25,54 @@ const (
 )
 
 type Products struct {
-	ID               uint64        `gorm:"primaryKey;autoIncrement"`
-	SKU              string        `gorm:"type:varchar(100);uniqueIndex:uni_products_sku,size:100;not null"`
-	Name             string        `gorm:"type:varchar(255);not null"`
-	Description      string        `gorm:"type:text"`
-	Categorys        Category      `gorm:"not null;default:'masculino'"`
-	Size             string        `gorm:"type:varchar(10);not null"`
-	Color            string        `gorm:"type:varchar(50);not null"`
-	Material         string        `gorm:"type:varchar(100)"`
-	Gender           string        `gorm:"type:varchar(1)"`
-	Price            float64       `gorm:"type:decimal(10,2);not null"`
-	PromotionalPrice *float64      `gorm:"type:decimal(10,2)"`
-	StockQuantity    int           `gorm:"default:0"`
-	MinStock         int           `gorm:"default:0"`
-	Weight           float64       `gorm:"type:decimal(5,2)"`
-	Dimensions       string        `gorm:"type:varchar(100)"`
-	Images           []byte        `gorm:"type:json"`
-	Status           ProductStatus `gorm:"type:varchar(20);not null;default:'draft';index"`
-	IsActive         bool          `gorm:"default:true"`
-	IsPromotional    bool          `gorm:"default:false"`
-	Tags             string        `gorm:"type:text"`
-	SEODescription   string        `gorm:"type:text"`
-	SEOKeywords      string        `gorm:"type:text"`
-	CreatedAt        time.Time     `gorm:"autoCreateTime"`
-	UpdatedAt        time.Time     `gorm:"autoUpdateTime"`
+	ID                uint64        `gorm:"primaryKey;autoIncrement"`
+	SKU               string        `gorm:"type:varchar(100);uniqueIndex:uni_products_sku,size:100;not null"`
+	Name              string        `gorm:"type:varchar(255);not null"`
+	Slug              *string       `gorm:"type:varchar(160);uniqueIndex:uni_products_slug"` // nulo só em registros anteriores aos slugs
+	Description       string        `gorm:"type:text"`
+	CategoryID        *uint64       `gorm:"index"`
+	Categorys         Category      `gorm:"not null;default:'masculino'"`
+	Size              string        `gorm:"type:varchar(10);not null"`
+	Color             string        `gorm:"type:varchar(50);not null"`
+	Material          string        `gorm:"type:varchar(100)"`
+	Gender            string        `gorm:"type:varchar(1)"`
+	Price             float64       `gorm:"type:decimal(10,2);not null"`
+	PromotionalPrice  *float64      `gorm:"type:decimal(10,2)"`
+	PromotionStartsAt *time.Time    `gorm:"index"` // janela da promoção; sem janela vale IsPromotional
+	PromotionEndsAt   *time.Time    `gorm:"index"`
+	StockQuantity     int           `gorm:"default:0"`
+	MinStock          int           `gorm:"default:0"`
+	Weight            float64       `gorm:"type:decimal(5,2)"`
+	Dimensions        string        `gorm:"type:varchar(100)"`
+	Images            []byte        `gorm:"type:json"`
+	Status            ProductStatus `gorm:"type:varchar(20);not null;default:'draft';index"`
+	PublishAt         *time.Time    `gorm:"index"` // publicação agendada (service/scheduler)
+	UnpublishAt       *time.Time    `gorm:"index"` // despublicação agendada
+	IsActive          bool          `gorm:"default:true"`
+	IsPromotional     bool          `gorm:"default:false"`
+	IsBundle          bool          `gorm:"default:false"` // kit: estoque calculado pelos BundleItems
+	Tags              string        `gorm:"type:text"`
+	SEODescription    string        `gorm:"type:text"`
+	SEOKeywords       string        `gorm:"type:text"`
+	SearchText        string        `gorm:"type:text;index:idx_products_search,class:FULLTEXT"` // texto normalizado para busca (service/search)
+
+	// Campos de bordado/gravação aceitos no pedido (ex.: iniciais no punho)
+	PersonalizationOptions []PersonalizationField `gorm:"type:json;serializer:json"`
+	SizeChartID            *uint64                `gorm:"index"` // sem tabela própria, vale a da categoria
+
+	// Nota agregada das avaliações aprovadas (catalog.RefreshProductRating)
+	RatingAverage float64 `gorm:"type:decimal(3,2);default:0;index"`
+	RatingCount   int     `gorm:"default:0"`
+
+	CreatedAt time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"` // lixeira: fora das listagens até restaurar ou excluir de vez
+}
+
+// ProductSlugHistory guarda slugs antigos de um produto para redirecionar links antigos.
+type ProductSlugHistory struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement"`
+	ProductID uint64    `gorm:"not null;index"`
+	Slug      string    `gorm:"type:varchar(160);uniqueIndex:uni_product_slug_history;not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
 }
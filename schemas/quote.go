@@ -0,0 +1,81 @@
+package schemas
+
+import "time"
+
+type QuoteStatus string
+
+const (
+	QuoteSubmitted QuoteStatus = "submitted" // enviado pela empresa, aguardando preço
+	QuotePriced    QuoteStatus = "priced"    // precificado pela equipe, aguardando aprovação
+	QuoteApproved  QuoteStatus = "approved"
+	QuoteRejected  QuoteStatus = "rejected"
+	QuoteConverted QuoteStatus = "converted" // virou pedido (Orders.QuoteID)
+)
+
+// Quotes é o orçamento B2B de fardamentos: grade de tamanhos, bordados e arte da empresa.
+type Quotes struct {
+	ID              uint64      `gorm:"primaryKey;autoIncrement"`
+	Number          string      `gorm:"type:varchar(32);unique;not null"`
+	UserID          uint64      `gorm:"not null;index"` // quem solicitou
+	CompanyName     string      `gorm:"type:varchar(255);not null"`
+	CNPJ            string      `gorm:"type:varchar(18)"`
+	ContactName     string      `gorm:"type:varchar(255)"`
+	ContactEmail    string      `gorm:"type:varchar(255)"`
+	ContactPhone    string      `gorm:"type:varchar(32)"`
+	Notes           string      `gorm:"type:text"`
+	Status          QuoteStatus `gorm:"type:enum('submitted','priced','approved','rejected','converted');default:'submitted';index"`
+	StaffNotes      string      `gorm:"type:text"`
+	Subtotal        float64     `gorm:"type:decimal(10,2);default:0"`
+	Discount        float64     `gorm:"type:decimal(10,2);default:0"`
+	Total           float64     `gorm:"type:decimal(10,2);default:0"`
+	ValidUntil      *time.Time
+	PricedBy        *uint64
+	PricedAt        *time.Time
+	ApprovedAt      *time.Time
+	RejectedAt      *time.Time
+	RejectionReason string  `gorm:"type:varchar(500)"`
+	OrderID         *uint64 `gorm:"index"`
+
+	Items    []QuoteItems    `gorm:"foreignKey:QuoteID"`
+	Artworks []QuoteArtworks `gorm:"foreignKey:QuoteID"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// QuotePersonalization é o nome/número bordado em uma peça específica da grade.
+type QuotePersonalization struct {
+	Size   string `json:"size"`
+	Name   string `json:"name"`
+	Number string `json:"number,omitempty"`
+}
+
+type QuoteItems struct {
+	ID                  uint64                 `gorm:"primaryKey;autoIncrement"`
+	QuoteID             uint64                 `gorm:"not null;index"`
+	ProductID           uint64                 `gorm:"not null"` // modelo base do catálogo
+	Description         string                 `gorm:"type:varchar(500)"`
+	Color               string                 `gorm:"type:varchar(50)"`
+	SizeGrid            map[string]int         `gorm:"type:json;serializer:json"` // tamanho → quantidade
+	Quantity            int                    `gorm:"not null"`                  // soma da grade
+	EmbroideryPositions []string               `gorm:"type:json;serializer:json"`
+	Personalization     []QuotePersonalization `gorm:"type:json;serializer:json"`
+	ArtworkID           *uint64
+	UnitPrice           float64 `gorm:"type:decimal(10,2);default:0"`
+	LineTotal           float64 `gorm:"type:decimal(10,2);default:0"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// QuoteArtworks são os arquivos de logo/arte enviados ao MinIO (prefixo quotes/).
+type QuoteArtworks struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement"`
+	QuoteID     uint64    `gorm:"not null;index"`
+	FileName    string    `gorm:"type:varchar(255)"`
+	ObjectName  string    `gorm:"type:varchar(500);not null"`
+	URL         string    `gorm:"type:varchar(1000)"`
+	ContentType string    `gorm:"type:varchar(100)"`
+	Size        int64     `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
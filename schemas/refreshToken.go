@@ -0,0 +1,20 @@
+package schemas
+
+import "time"
+
+// RefreshTokens guarda o hash (SHA-256) de cada refresh token emitido. Os tokens de um mesmo
+// login formam uma família: cada uso troca o token por outro (UsedAt + ReplacedByID), e
+// reapresentar um token já usado revoga a família inteira.
+type RefreshTokens struct {
+	ID           uint64     `gorm:"primaryKey;autoIncrement"`
+	UserID       uint64     `gorm:"not null;index"`
+	FamilyID     string     `gorm:"type:varchar(32);not null;index"`
+	TokenHash    string     `gorm:"type:char(64);uniqueIndex:uni_refresh_token_hash;not null"`
+	ExpiresAt    time.Time  `gorm:"not null;index"`
+	UsedAt       *time.Time // trocado por ReplacedByID
+	ReplacedByID *uint64
+	RevokedAt    *time.Time // logout, reuso detectado ou revogação da conta
+	UserAgent    string     `gorm:"type:varchar(255)"`
+	IP           string     `gorm:"type:varchar(45)"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,23 @@
+package schemas
+
+import "time"
+
+type RelationType string
+
+const (
+	RelationManual         RelationType = "manual"          // vínculo feito pelo admin
+	RelationBoughtTogether RelationType = "bought_together" // calculado pelos pedidos (job bought-together)
+)
+
+// ProductRelations liga um produto a outro exibido na página dele. Relações manuais
+// seguem Position; as automáticas seguem Score (pedidos em que os dois aparecem juntos).
+type ProductRelations struct {
+	ID        uint64       `gorm:"primaryKey;autoIncrement"`
+	ProductID uint64       `gorm:"not null;uniqueIndex:uni_product_relation"`
+	RelatedID uint64       `gorm:"not null;uniqueIndex:uni_product_relation;index"`
+	Type      RelationType `gorm:"type:enum('manual','bought_together');not null;uniqueIndex:uni_product_relation"`
+	Position  int          `gorm:"default:0"`
+	Score     int          `gorm:"default:0"`
+	CreatedAt time.Time    `gorm:"autoCreateTime"`
+	UpdatedAt time.Time    `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,30 @@
+package schemas
+
+import "time"
+
+type ReviewStatus string
+
+const (
+	ReviewPending  ReviewStatus = "pending" // aguardando moderação
+	ReviewApproved ReviewStatus = "approved"
+	ReviewRejected ReviewStatus = "rejected"
+)
+
+// ProductReviews são avaliações de compra verificada: cada item de pedido entregue
+// pode ser avaliado uma vez. Só avaliações aprovadas entram na nota do produto.
+type ProductReviews struct {
+	ID              uint64       `gorm:"primaryKey;autoIncrement"`
+	ProductID       uint64       `gorm:"not null;index:idx_reviews_product_status"`
+	Status          ReviewStatus `gorm:"type:enum('pending','approved','rejected');default:'pending';index:idx_reviews_product_status"`
+	UserID          uint64       `gorm:"not null;index"`
+	OrderItemID     uint64       `gorm:"not null;uniqueIndex"`
+	Rating          int          `gorm:"not null"` // 1 a 5
+	Title           string       `gorm:"type:varchar(120)"`
+	Comment         string       `gorm:"type:text"`
+	Photos          []string     `gorm:"type:json;serializer:json"` // keys no armazenamento (reviews/)
+	ModeratedBy     *uint64
+	ModeratedAt     *time.Time
+	RejectionReason string    `gorm:"type:varchar(255)"`
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,29 @@
+package schemas
+
+import "time"
+
+// SizeRange é a faixa de uma medida para um tamanho (cm para medidas, kg para peso).
+type SizeRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// SizeChartRow traz as medidas de um tamanho. Chaves usadas na recomendação:
+// "chest" (tórax), "height" (altura) e "weight" (peso); outras (ex.: "length") são só exibidas.
+type SizeChartRow struct {
+	Size         string               `json:"size"`
+	Measurements map[string]SizeRange `json:"measurements"`
+}
+
+// SizeCharts é a tabela de medidas de uma categoria ou modelo.
+// Produtos usam a tabela própria (Products.SizeChartID) ou a da categoria (ou ancestrais).
+type SizeCharts struct {
+	ID          uint64         `gorm:"primaryKey;autoIncrement"`
+	Name        string         `gorm:"type:varchar(120);not null"`
+	CategoryID  *uint64        `gorm:"index"` // tabela padrão da categoria
+	Description string         `gorm:"type:text"`
+	Rows        []SizeChartRow `gorm:"type:json;serializer:json"` // na ordem do menor para o maior
+	IsActive    bool           `gorm:"default:true"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,21 @@
+package schemas
+
+import "time"
+
+// StoredObjects registra os arquivos de imagem de produto no bucket, endereçados pelo
+// SHA-256 do arquivo enviado: o mesmo conteúdo reaproveita os objetos existentes.
+// RefCount é o número de imagens de galeria que usam o objeto; em zero o arquivo é apagado.
+type StoredObjects struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement"`
+	ObjectKey   string    `gorm:"type:varchar(255);uniqueIndex:uni_stored_objects_key;not null"`
+	Hash        string    `gorm:"type:char(64);not null;index"` // SHA-256 do arquivo original
+	Variant     string    `gorm:"type:varchar(20);not null"`    // thumb, card, zoom
+	Format      string    `gorm:"type:varchar(10);not null"`    // jpeg, webp
+	ContentType string    `gorm:"type:varchar(50)"`
+	Size        int64     `gorm:"not null;default:0"`
+	Width       int       `gorm:"default:0"`
+	Height      int       `gorm:"default:0"`
+	RefCount    int       `gorm:"not null;default:0"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
@@ -19,4 +19,21 @@ type Users struct {
 	Role      UserRole  `json:"role" gorm:"type:enum('admin','user','client');default:'client'"`
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Medidas do perfil para recomendação de tamanho
+	ChestCm  *float64 `json:"chest_cm" gorm:"type:decimal(5,1)"`
+	HeightCm *float64 `json:"height_cm" gorm:"type:decimal(5,1)"`
+	WeightKg *float64 `json:"weight_kg" gorm:"type:decimal(5,1)"`
+
+	// Opt-in para avisos da loja (ex.: produto de volta ao estoque)
+	NotifyWhatsApp bool `json:"notify_whatsapp" gorm:"default:false"`
+	NotifyEmail    bool `json:"notify_email" gorm:"default:false"`
+
+	// Verificação do cadastro (exigível para fechar pedidos: REQUIRE_EMAIL_VERIFICATION /
+	// REQUIRE_PHONE_VERIFICATION)
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at"`
+
+	// Access tokens emitidos antes disso são recusados (logout geral, troca de senha)
+	TokensRevokedAt *time.Time `json:"-"`
 }
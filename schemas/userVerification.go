@@ -0,0 +1,23 @@
+package schemas
+
+import "time"
+
+// Canais de verificação do cadastro.
+const (
+	VerifyEmail = "email" // link com token enviado por e-mail
+	VerifyPhone = "phone" // código enviado por WhatsApp para Users.Contact
+)
+
+// UserVerifications guarda os links/códigos de verificação emitidos (só o hash). Target é o
+// e-mail ou telefone no momento do envio: se o cadastro mudar, o código deixa de valer.
+type UserVerifications struct {
+	ID        uint64     `gorm:"primaryKey;autoIncrement"`
+	UserID    uint64     `gorm:"not null;index"`
+	Channel   string     `gorm:"type:varchar(10);not null"`
+	Target    string     `gorm:"type:varchar(255);not null"`
+	TokenHash string     `gorm:"type:char(64);not null;index"`
+	Attempts  int        `gorm:"not null;default:0"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time // confirmado ou substituído por um envio mais novo
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
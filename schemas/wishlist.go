@@ -0,0 +1,41 @@
+package schemas
+
+import "time"
+
+// WishlistItems são os produtos salvos pelo usuário da loja.
+type WishlistItems struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement"`
+	UserID    uint64    `gorm:"not null;uniqueIndex:uni_wishlist_user_product"`
+	ProductID uint64    `gorm:"not null;uniqueIndex:uni_wishlist_user_product;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+type AlertChannel string
+
+const (
+	AlertWhatsApp AlertChannel = "whatsapp"
+	AlertEmail    AlertChannel = "email"
+)
+
+type AlertStatus string
+
+const (
+	AlertPending  AlertStatus = "pending" // aguardando reposição
+	AlertNotified AlertStatus = "notified"
+	AlertFailed   AlertStatus = "failed" // esgotou as tentativas de envio
+)
+
+// StockSubscriptions é o "avise-me" de um produto esgotado; o job stock-alerts
+// envia a mensagem quando o estoque volta e marca a inscrição como notificada.
+type StockSubscriptions struct {
+	ID         uint64       `gorm:"primaryKey;autoIncrement"`
+	UserID     uint64       `gorm:"not null;uniqueIndex:uni_stock_sub_user_product"`
+	ProductID  uint64       `gorm:"not null;uniqueIndex:uni_stock_sub_user_product;index:idx_stock_sub_status_product,priority:2"`
+	Channel    AlertChannel `gorm:"type:enum('whatsapp','email');not null"`
+	Status     AlertStatus  `gorm:"type:enum('pending','notified','failed');default:'pending';index:idx_stock_sub_status_product,priority:1"`
+	Attempts   int          `gorm:"not null;default:0"`
+	LastError  string       `gorm:"type:varchar(255)"`
+	NotifiedAt *time.Time
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,132 @@
+package catalog
+
+import (
+	"backend_camisaria_store/schemas"
+
+	"gorm.io/gorm"
+)
+
+// BundleComponent é um componente do kit já com os dados do produto.
+type BundleComponent struct {
+	Item    schemas.BundleItems
+	Product schemas.Products
+}
+
+// LoadBundleComponents carrega os componentes do kit com seus produtos.
+func LoadBundleComponents(db *gorm.DB, bundleID uint64) ([]BundleComponent, error) {
+	var items []schemas.BundleItems
+	if err := db.Where("bundle_id = ?", bundleID).Order("id ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return []BundleComponent{}, nil
+	}
+
+	ids := make([]uint64, 0, len(items))
+	for _, it := range items {
+		ids = append(ids, it.ComponentID)
+	}
+	var products []schemas.Products
+	if err := db.Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint64]schemas.Products, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	components := make([]BundleComponent, 0, len(items))
+	for _, it := range items {
+		p, ok := byID[it.ComponentID]
+		if !ok {
+			// componente removido: o kit fica indisponível
+			p = schemas.Products{ID: it.ComponentID}
+		}
+		components = append(components, BundleComponent{Item: it, Product: p})
+	}
+	return components, nil
+}
+
+// BundleAvailability calcula quantos kits podem ser montados com o estoque atual:
+// o menor estoque/quantidade entre os componentes. Componente inativo zera o kit.
+func BundleAvailability(components []BundleComponent) int {
+	if len(components) == 0 {
+		return 0
+	}
+	available := -1
+	for _, c := range components {
+		if !c.Product.IsActive || c.Item.Quantity <= 0 {
+			return 0
+		}
+		n := c.Product.StockQuantity / c.Item.Quantity
+		if available < 0 || n < available {
+			available = n
+		}
+	}
+	if available < 0 {
+		return 0
+	}
+	return available
+}
+
+// BundleAvailabilities calcula a disponibilidade de vários kits de uma vez (listagens).
+func BundleAvailabilities(db *gorm.DB, bundleIDs []uint64) (map[uint64]int, error) {
+	result := make(map[uint64]int, len(bundleIDs))
+	if len(bundleIDs) == 0 {
+		return result, nil
+	}
+
+	var items []schemas.BundleItems
+	if err := db.Where("bundle_id IN ?", bundleIDs).Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	componentIDs := make([]uint64, 0, len(items))
+	for _, it := range items {
+		componentIDs = append(componentIDs, it.ComponentID)
+	}
+	var products []schemas.Products
+	if len(componentIDs) > 0 {
+		if err := db.Select("id", "stock_quantity", "is_active").
+			Where("id IN ?", componentIDs).Find(&products).Error; err != nil {
+			return nil, err
+		}
+	}
+	byID := make(map[uint64]schemas.Products, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	grouped := make(map[uint64][]BundleComponent)
+	for _, it := range items {
+		grouped[it.BundleID] = append(grouped[it.BundleID], BundleComponent{Item: it, Product: byID[it.ComponentID]})
+	}
+	for _, id := range bundleIDs {
+		result[id] = BundleAvailability(grouped[id])
+	}
+	return result, nil
+}
+
+// ApplyBundleStock substitui o estoque dos kits da lista pela disponibilidade calculada.
+func ApplyBundleStock(db *gorm.DB, products []schemas.Products) error {
+	var bundleIDs []uint64
+	for _, p := range products {
+		if p.IsBundle {
+			bundleIDs = append(bundleIDs, p.ID)
+		}
+	}
+	if len(bundleIDs) == 0 {
+		return nil
+	}
+
+	availability, err := BundleAvailabilities(db, bundleIDs)
+	if err != nil {
+		return err
+	}
+	for i := range products {
+		if products[i].IsBundle {
+			products[i].StockQuantity = availability[products[i].ID]
+		}
+	}
+	return nil
+}
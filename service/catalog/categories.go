@@ -0,0 +1,106 @@
+package catalog
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"backend_camisaria_store/schemas"
+
+	"gorm.io/gorm"
+)
+
+var ErrCategoryNotFound = errors.New("categoria não encontrada")
+
+// FindCategory busca uma categoria pelo ID numérico ou pelo slug.
+func FindCategory(db *gorm.DB, ref string) (*schemas.Categories, error) {
+	ref = strings.ToLower(strings.TrimSpace(ref))
+	if ref == "" {
+		return nil, ErrCategoryNotFound
+	}
+
+	var category schemas.Categories
+	query := db.Where("slug = ?", ref)
+	if id, err := strconv.ParseUint(ref, 10, 64); err == nil {
+		query = db.Where("id = ?", id)
+	}
+	if err := query.First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// LoadCategories carrega todas as categorias (a tabela é pequena) na ordem de exibição.
+func LoadCategories(db *gorm.DB, onlyActive bool) ([]schemas.Categories, error) {
+	query := db.Order("position ASC, name ASC")
+	if onlyActive {
+		query = query.Where("is_active = ?", true)
+	}
+	var categories []schemas.Categories
+	if err := query.Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// DescendantIDs devolve o ID da categoria e de todas as subcategorias abaixo dela.
+func DescendantIDs(categories []schemas.Categories, rootID uint64) []uint64 {
+	children := make(map[uint64][]uint64)
+	for _, c := range categories {
+		if c.ParentID != nil {
+			children[*c.ParentID] = append(children[*c.ParentID], c.ID)
+		}
+	}
+
+	ids := []uint64{rootID}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids
+}
+
+// CategoryWithDescendants busca a categoria (ID ou slug) e os IDs da sua subárvore.
+func CategoryWithDescendants(db *gorm.DB, ref string) (*schemas.Categories, []uint64, error) {
+	category, err := FindCategory(db, ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	categories, err := LoadCategories(db, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return category, DescendantIDs(categories, category.ID), nil
+}
+
+// ResolveGender sobe na árvore até encontrar uma categoria com gênero definido ("U" se nenhuma tiver).
+func ResolveGender(db *gorm.DB, category *schemas.Categories) (string, error) {
+	current := category
+	for depth := 0; current != nil && depth < 10; depth++ {
+		if current.Gender != "" {
+			return current.Gender, nil
+		}
+		if current.ParentID == nil {
+			break
+		}
+		var parent schemas.Categories
+		if err := db.First(&parent, *current.ParentID).Error; err != nil {
+			return "", err
+		}
+		current = &parent
+	}
+	return "U", nil
+}
+
+// IsDescendant indica se candidateID está na subárvore de rootID (inclusive).
+// Usado para impedir ciclos ao mover uma categoria.
+func IsDescendant(categories []schemas.Categories, rootID, candidateID uint64) bool {
+	for _, id := range DescendantIDs(categories, rootID) {
+		if id == candidateID {
+			return true
+		}
+	}
+	return false
+}
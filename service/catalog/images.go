@@ -0,0 +1,151 @@
+package catalog
+
+import (
+	"encoding/json"
+	"sort"
+
+	"backend_camisaria_store/schemas"
+
+	"gorm.io/gorm"
+)
+
+// MaxProductImages é o limite de imagens na galeria de um produto.
+const MaxProductImages = 5
+
+// ProductGallery devolve as imagens do produto na ordem de exibição: capa primeiro,
+// depois por posição.
+func ProductGallery(db *gorm.DB, productID uint64) ([]schemas.ProductImages, error) {
+	var images []schemas.ProductImages
+	if err := db.Where("product_id = ?", productID).Order("position ASC, id ASC").Find(&images).Error; err != nil {
+		return nil, err
+	}
+	sortGallery(images)
+	return images, nil
+}
+
+// ProductGalleries carrega a galeria de vários produtos (listagens).
+func ProductGalleries(db *gorm.DB, productIDs []uint64) (map[uint64][]schemas.ProductImages, error) {
+	result := make(map[uint64][]schemas.ProductImages, len(productIDs))
+	if len(productIDs) == 0 {
+		return result, nil
+	}
+	var images []schemas.ProductImages
+	if err := db.Where("product_id IN ?", productIDs).Order("position ASC, id ASC").Find(&images).Error; err != nil {
+		return nil, err
+	}
+	for _, img := range images {
+		result[img.ProductID] = append(result[img.ProductID], img)
+	}
+	for id := range result {
+		sortGallery(result[id])
+	}
+	return result, nil
+}
+
+func sortGallery(images []schemas.ProductImages) {
+	sort.SliceStable(images, func(i, j int) bool {
+		return images[i].IsCover && !images[j].IsCover
+	})
+}
+
+// SyncProductImages normaliza a galeria (posições 1..n, exatamente uma capa quando houver
+// imagens) e regrava Products.Images com as keys na ordem de exibição.
+func SyncProductImages(tx *gorm.DB, productID uint64) error {
+	var images []schemas.ProductImages
+	if err := tx.Where("product_id = ?", productID).Order("position ASC, id ASC").Find(&images).Error; err != nil {
+		return err
+	}
+
+	hasCover := false
+	for _, img := range images {
+		if img.IsCover {
+			hasCover = true
+			break
+		}
+	}
+	for i := range images {
+		position := i + 1
+		isCover := images[i].IsCover
+		if !hasCover && i == 0 {
+			isCover = true
+			hasCover = true
+		}
+		if images[i].Position != position || images[i].IsCover != isCover {
+			images[i].Position = position
+			images[i].IsCover = isCover
+			if err := tx.Model(&images[i]).UpdateColumns(map[string]interface{}{
+				"position": position,
+				"is_cover": isCover,
+			}).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	sortGallery(images)
+	urls := make([]string, 0, len(images))
+	for _, img := range images {
+		urls = append(urls, img.URL)
+	}
+	urlsJSON, err := json.Marshal(urls)
+	if err != nil {
+		return err
+	}
+	return tx.Model(&schemas.Products{}).Where("id = ?", productID).
+		UpdateColumn("images", urlsJSON).Error
+}
+
+// BackfillProductImages cria a galeria dos produtos que só têm URLs em Products.Images
+// (cadastros anteriores à tabela product_images). A primeira imagem vira capa.
+func BackfillProductImages(db *gorm.DB) error {
+	var products []schemas.Products
+	if err := db.Unscoped().Select("id", "images").
+		Where("images IS NOT NULL AND JSON_LENGTH(images) > 0").
+		Where("id NOT IN (?)", db.Model(&schemas.ProductImages{}).Distinct("product_id")).
+		Find(&products).Error; err != nil {
+		return err
+	}
+
+	for _, p := range products {
+		var urls []string
+		if err := json.Unmarshal(p.Images, &urls); err != nil || len(urls) == 0 {
+			continue
+		}
+		images := make([]schemas.ProductImages, 0, len(urls))
+		for i, u := range urls {
+			images = append(images, schemas.ProductImages{
+				ProductID: p.ID,
+				URL:       u,
+				Position:  i + 1,
+				IsCover:   i == 0,
+			})
+		}
+		if err := db.Create(&images).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImageObjectRefs devolve a key da imagem e as de todas as versões geradas (remoção no bucket).
+func ImageObjectRefs(img schemas.ProductImages) []string {
+	seen := map[string]bool{}
+	var urls []string
+	add := func(u string) {
+		if u != "" && !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	add(img.URL)
+	names := make([]string, 0, len(img.Renditions))
+	for name := range img.Renditions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add(img.Renditions[name].JPEG)
+		add(img.Renditions[name].WebP)
+	}
+	return urls
+}
@@ -0,0 +1,103 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"backend_camisaria_store/schemas"
+)
+
+const maxPersonalizationLength = 60
+
+// ValidatePersonalizationOptions confere os campos de personalização declarados no produto.
+func ValidatePersonalizationOptions(fields []schemas.PersonalizationField) error {
+	var errs []string
+	seen := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		key := strings.TrimSpace(f.Key)
+		if key == "" {
+			errs = append(errs, "personalização: key é obrigatória")
+			continue
+		}
+		if seen[key] {
+			errs = append(errs, "personalização: key repetida "+key)
+		}
+		seen[key] = true
+		if f.MaxLength <= 0 || f.MaxLength > maxPersonalizationLength {
+			errs = append(errs, fmt.Sprintf("personalização %s: max_length deve ser entre 1 e %d", key, maxPersonalizationLength))
+		}
+		if f.ExtraPrice < 0 {
+			errs = append(errs, "personalização "+key+": extra_price não pode ser negativo")
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// ApplyPersonalization valida os valores enviados no pedido contra as opções do produto.
+// Devolve os valores normalizados (com o rótulo do campo para a ficha de produção)
+// e o acréscimo por peça.
+func ApplyPersonalization(fields []schemas.PersonalizationField, values []schemas.PersonalizationValue) ([]schemas.PersonalizationValue, float64, error) {
+	// Chaves comparadas sem espaços nas pontas, como na validação das opções do produto
+	byKey := make(map[string]schemas.PersonalizationField, len(fields))
+	for _, f := range fields {
+		byKey[strings.TrimSpace(f.Key)] = f
+	}
+
+	provided := make(map[string]schemas.PersonalizationValue, len(values))
+	for _, v := range values {
+		key := strings.TrimSpace(v.Key)
+		if _, ok := byKey[key]; !ok {
+			return nil, 0, fmt.Errorf("personalização %q não disponível para este produto", key)
+		}
+		if _, dup := provided[key]; dup {
+			return nil, 0, fmt.Errorf("personalização %q informada mais de uma vez", key)
+		}
+		provided[key] = v
+	}
+
+	var result []schemas.PersonalizationValue
+	price := 0.0
+	for _, f := range fields {
+		key := strings.TrimSpace(f.Key)
+		v, ok := provided[key]
+		text := strings.TrimSpace(v.Value)
+		if !ok || text == "" {
+			if f.Required {
+				return nil, 0, fmt.Errorf("personalização %q é obrigatória", f.Label)
+			}
+			continue
+		}
+		if utf8.RuneCountInString(text) > f.MaxLength {
+			return nil, 0, fmt.Errorf("personalização %q deve ter no máximo %d caracteres", f.Label, f.MaxLength)
+		}
+		if len(f.Fonts) > 0 && !contains(f.Fonts, v.Font) {
+			return nil, 0, fmt.Errorf("fonte inválida para %q", f.Label)
+		}
+		if len(f.Colors) > 0 && !contains(f.Colors, v.Color) {
+			return nil, 0, fmt.Errorf("cor inválida para %q", f.Label)
+		}
+		result = append(result, schemas.PersonalizationValue{
+			Key:   key,
+			Label: f.Label,
+			Value: text,
+			Font:  v.Font,
+			Color: v.Color,
+		})
+		price += f.ExtraPrice
+	}
+	return result, price, nil
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
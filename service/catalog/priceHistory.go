@@ -0,0 +1,152 @@
+package catalog
+
+import (
+	"errors"
+	"time"
+
+	"backend_camisaria_store/schemas"
+
+	"gorm.io/gorm"
+)
+
+// LowestPriceWindow é o período usado na divulgação do menor preço anterior.
+const LowestPriceWindow = 30 * 24 * time.Hour
+
+// RecordPriceChange grava uma entrada no histórico quando preço, preço promocional
+// ou o preço efetivo mudaram. before nil (criação) sempre grava.
+func RecordPriceChange(tx *gorm.DB, before *schemas.Products, after schemas.Products, source schemas.PriceSource, actor Actor, now time.Time) error {
+	if before != nil && !priceChanged(*before, after, now) {
+		return nil
+	}
+	return tx.Create(priceEntry(after, source, actor.UserID, now)).Error
+}
+
+func priceChanged(before, after schemas.Products, now time.Time) bool {
+	if before.Price != after.Price {
+		return true
+	}
+	if (before.PromotionalPrice == nil) != (after.PromotionalPrice == nil) {
+		return true
+	}
+	if before.PromotionalPrice != nil && *before.PromotionalPrice != *after.PromotionalPrice {
+		return true
+	}
+	return PromotionActive(before, now) != PromotionActive(after, now)
+}
+
+func priceEntry(p schemas.Products, source schemas.PriceSource, userID *uint64, now time.Time) *schemas.ProductPriceHistory {
+	return &schemas.ProductPriceHistory{
+		ProductID:        p.ID,
+		Price:            p.Price,
+		PromotionalPrice: p.PromotionalPrice,
+		PromotionActive:  PromotionActive(p, now),
+		EffectivePrice:   EffectivePrice(p, now),
+		Source:           source,
+		UserID:           userID,
+		CreatedAt:        now,
+	}
+}
+
+// BackfillPriceHistory grava o preço vigente dos produtos que ainda não têm histórico.
+func BackfillPriceHistory(db *gorm.DB) error {
+	var products []schemas.Products
+	if err := db.Where("id NOT IN (?)", db.Model(&schemas.ProductPriceHistory{}).Distinct("product_id")).
+		Find(&products).Error; err != nil {
+		return err
+	}
+	if len(products) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	entries := make([]schemas.ProductPriceHistory, 0, len(products))
+	for _, p := range products {
+		entries = append(entries, *priceEntry(p, schemas.PriceSourceBackfill, nil, now))
+	}
+	return db.CreateInBatches(entries, 500).Error
+}
+
+// PriceHistory devolve as mudanças de preço desde since, precedidas do preço que
+// estava em vigor naquele instante (para o gráfico começar no início do período).
+func PriceHistory(db *gorm.DB, productID uint64, since time.Time) ([]schemas.ProductPriceHistory, error) {
+	var entries []schemas.ProductPriceHistory
+	var previous schemas.ProductPriceHistory
+	err := db.Where("product_id = ? AND created_at < ?", productID, since).
+		Order("created_at DESC, id DESC").First(&previous).Error
+	switch {
+	case err == nil:
+		entries = append(entries, previous)
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	var recent []schemas.ProductPriceHistory
+	if err := db.Where("product_id = ? AND created_at >= ?", productID, since).
+		Order("created_at ASC, id ASC").Find(&recent).Error; err != nil {
+		return nil, err
+	}
+	return append(entries, recent...), nil
+}
+
+type lowestPrice struct {
+	ProductID uint64
+	Price     float64
+}
+
+// LowestPrices devolve, para cada produto, o menor preço efetivo praticado nos 30 dias
+// anteriores à entrada em vigor do preço atual (a última entrada do histórico até now),
+// sem contar o próprio preço atual e incluindo o que já vigorava no início do período.
+// Produtos sem preço anterior ao atual ficam de fora.
+func LowestPrices(db *gorm.DB, productIDs []uint64, now time.Time) (map[uint64]float64, error) {
+	result := make(map[uint64]float64, len(productIDs))
+	if len(productIDs) == 0 {
+		return result, nil
+	}
+	window := int64(LowestPriceWindow / time.Second)
+
+	// Entrada atual de cada produto: a janela termina quando ela passou a valer
+	currentEntry := `
+		SELECT c.product_id, c.id, c.created_at
+		FROM product_price_histories c
+		JOIN (
+			SELECT product_id, MAX(id) AS id
+			FROM product_price_histories
+			WHERE product_id IN ? AND created_at <= ?
+			GROUP BY product_id
+		) last ON last.id = c.id`
+
+	var inWindow []lowestPrice
+	if err := db.Raw(`
+		SELECT h.product_id AS product_id, MIN(h.effective_price) AS price
+		FROM product_price_histories h
+		JOIN (`+currentEntry+`) cur ON cur.product_id = h.product_id
+		WHERE h.id <> cur.id
+			AND h.created_at <= cur.created_at
+			AND h.created_at >= DATE_SUB(cur.created_at, INTERVAL ? SECOND)
+		GROUP BY h.product_id`, productIDs, now, window).Scan(&inWindow).Error; err != nil {
+		return nil, err
+	}
+
+	var atStart []lowestPrice
+	if err := db.Raw(`
+		SELECT h.product_id AS product_id, h.effective_price AS price
+		FROM product_price_histories h
+		JOIN (
+			SELECT p.product_id, MAX(p.id) AS id
+			FROM product_price_histories p
+			JOIN (`+currentEntry+`) cur ON cur.product_id = p.product_id
+			WHERE p.id <> cur.id AND p.created_at < DATE_SUB(cur.created_at, INTERVAL ? SECOND)
+			GROUP BY p.product_id
+		) opening ON opening.id = h.id`, productIDs, now, window).Scan(&atStart).Error; err != nil {
+		return nil, err
+	}
+
+	for _, rows := range [][]lowestPrice{inWindow, atStart} {
+		for _, r := range rows {
+			if current, ok := result[r.ProductID]; !ok || r.Price < current {
+				result[r.ProductID] = r.Price
+			}
+		}
+	}
+	return result, nil
+}
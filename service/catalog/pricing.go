@@ -0,0 +1,33 @@
+package catalog
+
+import (
+	"time"
+
+	"backend_camisaria_store/schemas"
+)
+
+// PromotionActive indica se o preço promocional vale no instante informado.
+// Com janela (promotion_starts_at/promotion_ends_at) a janela manda; sem janela vale o flag manual.
+func PromotionActive(p schemas.Products, now time.Time) bool {
+	if p.PromotionalPrice == nil || *p.PromotionalPrice <= 0 {
+		return false
+	}
+	if p.PromotionStartsAt == nil && p.PromotionEndsAt == nil {
+		return p.IsPromotional
+	}
+	if p.PromotionStartsAt != nil && now.Before(*p.PromotionStartsAt) {
+		return false
+	}
+	if p.PromotionEndsAt != nil && !now.Before(*p.PromotionEndsAt) {
+		return false
+	}
+	return true
+}
+
+// EffectivePrice devolve o preço cobrado no instante informado.
+func EffectivePrice(p schemas.Products, now time.Time) float64 {
+	if PromotionActive(p, now) {
+		return *p.PromotionalPrice
+	}
+	return p.Price
+}
@@ -0,0 +1,123 @@
+package catalog
+
+import (
+	"time"
+
+	"backend_camisaria_store/schemas"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// boughtTogetherMinOrders é o mínimo de pedidos em comum para sugerir o par.
+	boughtTogetherMinOrders = 2
+	// boughtTogetherPerProduct limita as sugestões automáticas guardadas por produto.
+	boughtTogetherPerProduct = 12
+	// boughtTogetherWindow considera apenas pedidos recentes.
+	boughtTogetherWindow = 365 * 24 * time.Hour
+)
+
+type coOccurrence struct {
+	ProductID uint64
+	RelatedID uint64
+	Orders    int
+}
+
+// RecomputeBoughtTogether recalcula as relações "comprados juntos" a partir da
+// coocorrência de produtos nos itens de pedido. Componentes de kit não contam
+// (o kit já aparece como item próprio) e pedidos com pagamento recusado são ignorados.
+// Retorna quantas relações foram gravadas.
+func RecomputeBoughtTogether(db *gorm.DB, now time.Time) (int, error) {
+	var pairs []coOccurrence
+	err := db.Raw(`
+		SELECT a.product_id AS product_id, b.product_id AS related_id, COUNT(DISTINCT a.order_id) AS orders
+		FROM order_items a
+		JOIN order_items b ON b.order_id = a.order_id AND b.product_id <> a.product_id AND b.parent_item_id IS NULL
+		JOIN orders o ON o.id = a.order_id
+		WHERE a.parent_item_id IS NULL AND o.status_payment <> ? AND o.created_at >= ?
+		GROUP BY a.product_id, b.product_id
+		HAVING COUNT(DISTINCT a.order_id) >= ?
+		ORDER BY a.product_id ASC, orders DESC, b.product_id ASC`,
+		schemas.FailedPayment, now.Add(-boughtTogetherWindow), boughtTogetherMinOrders,
+	).Scan(&pairs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	relations := make([]schemas.ProductRelations, 0, len(pairs))
+	perProduct := make(map[uint64]int)
+	for _, p := range pairs {
+		if perProduct[p.ProductID] >= boughtTogetherPerProduct {
+			continue
+		}
+		perProduct[p.ProductID]++
+		relations = append(relations, schemas.ProductRelations{
+			ProductID: p.ProductID,
+			RelatedID: p.RelatedID,
+			Type:      schemas.RelationBoughtTogether,
+			Position:  perProduct[p.ProductID],
+			Score:     p.Orders,
+		})
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("type = ?", schemas.RelationBoughtTogether).
+			Delete(&schemas.ProductRelations{}).Error; err != nil {
+			return err
+		}
+		if len(relations) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(relations, 500).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(relations), nil
+}
+
+// RelatedProducts devolve os produtos relacionados visíveis na loja, na ordem de exibição,
+// com o estoque dos kits calculado.
+func RelatedProducts(db *gorm.DB, productID uint64, relType schemas.RelationType, limit int, now time.Time) ([]schemas.Products, error) {
+	var relations []schemas.ProductRelations
+	order := "position ASC"
+	if relType == schemas.RelationBoughtTogether {
+		order = "score DESC, position ASC"
+	}
+	if err := db.Where("product_id = ? AND type = ?", productID, relType).
+		Order(order).Find(&relations).Error; err != nil {
+		return nil, err
+	}
+	if len(relations) == 0 {
+		return []schemas.Products{}, nil
+	}
+
+	ids := make([]uint64, 0, len(relations))
+	for _, r := range relations {
+		ids = append(ids, r.RelatedID)
+	}
+	var products []schemas.Products
+	if err := db.Scopes(VisibleAt(now)).Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	if err := ApplyBundleStock(db, products); err != nil {
+		return nil, err
+	}
+	byID := make(map[uint64]schemas.Products, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	result := make([]schemas.Products, 0, len(ids))
+	for _, id := range ids {
+		p, ok := byID[id]
+		if !ok {
+			continue
+		}
+		result = append(result, p)
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,29 @@
+package catalog
+
+import (
+	"math"
+
+	"backend_camisaria_store/schemas"
+
+	"gorm.io/gorm"
+)
+
+// RefreshProductRating recalcula média e total das avaliações aprovadas do produto.
+func RefreshProductRating(db *gorm.DB, productID uint64) error {
+	var agg struct {
+		Average float64
+		Count   int
+	}
+	if err := db.Model(&schemas.ProductReviews{}).
+		Select("COALESCE(AVG(rating), 0) AS average, COUNT(*) AS count").
+		Where("product_id = ? AND status = ?", productID, schemas.ReviewApproved).
+		Scan(&agg).Error; err != nil {
+		return err
+	}
+
+	return db.Model(&schemas.Products{}).Where("id = ?", productID).
+		UpdateColumns(map[string]interface{}{
+			"rating_average": math.Round(agg.Average*100) / 100,
+			"rating_count":   agg.Count,
+		}).Error
+}
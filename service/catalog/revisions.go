@@ -0,0 +1,115 @@
+package catalog
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"backend_camisaria_store/schemas"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Actor identifica quem fez a alteração (usuário autenticado da requisição).
+type Actor struct {
+	UserID *uint64
+	Name   string
+}
+
+// SystemActor assina as alterações feitas sem requisição de usuário (agendador, baixa de
+// estoque dos pedidos, sincronização de categorias).
+var SystemActor = Actor{Name: "sistema"}
+
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// Campos que não entram no diff: controle interno ou derivados de outros campos.
+var revisionIgnoredFields = map[string]bool{
+	"ID":         true,
+	"CreatedAt":  true,
+	"UpdatedAt":  true,
+	"SearchText": true,
+	// Nota agregada muda pela moderação de avaliações, não por edição do produto
+	"RatingAverage": true,
+	"RatingCount":   true,
+}
+
+// ProductDiff compara dois estados do produto e devolve as colunas alteradas.
+// before nil (criação) ou after nil (remoção definitiva) tratam o outro lado como vazio.
+func ProductDiff(before, after *schemas.Products) map[string]FieldChange {
+	naming := schema.NamingStrategy{}
+	changes := make(map[string]FieldChange)
+
+	t := reflect.TypeOf(schemas.Products{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if revisionIgnoredFields[field.Name] || !field.IsExported() {
+			continue
+		}
+
+		var oldValue, newValue interface{}
+		if before != nil {
+			oldValue = revisionValue(reflect.ValueOf(*before).Field(i))
+		}
+		if after != nil {
+			newValue = revisionValue(reflect.ValueOf(*after).Field(i))
+		}
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		changes[naming.ColumnName("", field.Name)] = FieldChange{Old: oldValue, New: newValue}
+	}
+	return changes
+}
+
+// revisionValue normaliza o valor para o JSON da revisão (ponteiros nulos viram null
+// e o JSON de imagens é mantido como array em vez de base64).
+func revisionValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if b, ok := v.Interface().([]byte); ok {
+		if len(b) == 0 {
+			return nil
+		}
+		return json.RawMessage(b)
+	}
+	return v.Interface()
+}
+
+// RecordProductRevision grava a revisão na mesma transação da alteração.
+// Sem mudanças (update que não alterou nada) nenhuma revisão é criada.
+func RecordProductRevision(tx *gorm.DB, action schemas.RevisionAction, before, after *schemas.Products, actor Actor) error {
+	changes := ProductDiff(before, after)
+	if action == schemas.RevisionUpdate && len(changes) == 0 {
+		return nil
+	}
+
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	current := after
+	if current == nil {
+		current = before
+	}
+	snapshotJSON, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&schemas.ProductRevisions{
+		ProductID: current.ID,
+		Action:    action,
+		UserID:    actor.UserID,
+		UserName:  actor.Name,
+		Changes:   changesJSON,
+		Snapshot:  snapshotJSON,
+	}).Error
+}
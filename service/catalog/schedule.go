@@ -0,0 +1,136 @@
+package catalog
+
+import (
+	"time"
+
+	"backend_camisaria_store/schemas"
+
+	"gorm.io/gorm"
+)
+
+// VisibleAt restringe a consulta aos produtos visíveis na loja no instante informado:
+// publicados (ou com publicação agendada já vencida), ativos e fora da janela de despublicação.
+// Não depende do agendador ter rodado, então a troca acontece no horário exato.
+func VisibleAt(now time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Where("is_active = ?", true).
+			Where("(status = ? OR publish_at IS NOT NULL)", schemas.ProductStatusPublished).
+			Where("(publish_at IS NULL OR publish_at <= ?)", now).
+			Where("(unpublish_at IS NULL OR unpublish_at > ?)", now)
+	}
+}
+
+// ScheduleResult resume as alterações feitas por ApplySchedules.
+type ScheduleResult struct {
+	Published        int64
+	Unpublished      int64
+	PromotionStarted int64
+	PromotionEnded   int64
+}
+
+// ApplySchedules grava no produto as mudanças de estado cujo horário já passou:
+// publica/despublica (limpando o agendamento aplicado) e liga/desliga o flag de promoção
+// conforme a janela promotion_starts_at/promotion_ends_at.
+func ApplySchedules(db *gorm.DB, now time.Time) (ScheduleResult, error) {
+	var result ScheduleResult
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var publishing []schemas.Products
+		if err := tx.Where("publish_at IS NOT NULL AND publish_at <= ?", now).
+			Where("(unpublish_at IS NULL OR unpublish_at > ?)", now).
+			Find(&publishing).Error; err != nil {
+			return err
+		}
+		n, err := applyScheduledChanges(tx, publishing, map[string]interface{}{
+			"status":     schemas.ProductStatusPublished,
+			"publish_at": nil,
+		})
+		if err != nil {
+			return err
+		}
+		result.Published = n
+
+		var unpublishing []schemas.Products
+		if err := tx.Where("unpublish_at IS NOT NULL AND unpublish_at <= ?", now).
+			Find(&unpublishing).Error; err != nil {
+			return err
+		}
+		n, err = applyScheduledChanges(tx, unpublishing, map[string]interface{}{
+			"status":       schemas.ProductStatusDraft,
+			"publish_at":   nil,
+			"unpublish_at": nil,
+		})
+		if err != nil {
+			return err
+		}
+		result.Unpublished = n
+
+		var starting []schemas.Products
+		if err := tx.Where("is_promotional = ? AND promotional_price > 0", false).
+			Where("(promotion_starts_at IS NOT NULL OR promotion_ends_at IS NOT NULL)").
+			Where("(promotion_starts_at IS NULL OR promotion_starts_at <= ?)", now).
+			Where("(promotion_ends_at IS NULL OR promotion_ends_at > ?)", now).
+			Find(&starting).Error; err != nil {
+			return err
+		}
+		n, err = togglePromotion(tx, starting, true, now)
+		if err != nil {
+			return err
+		}
+		result.PromotionStarted = n
+
+		var ending []schemas.Products
+		if err := tx.Where("is_promotional = ?", true).
+			Where("promotion_ends_at IS NOT NULL AND promotion_ends_at <= ?", now).
+			Find(&ending).Error; err != nil {
+			return err
+		}
+		n, err = togglePromotion(tx, ending, false, now)
+		if err != nil {
+			return err
+		}
+		result.PromotionEnded = n
+
+		return nil
+	})
+	return result, err
+}
+
+// applyScheduledChanges aplica as mudanças produto a produto, gravando a revisão de cada um
+// em nome do sistema.
+func applyScheduledChanges(tx *gorm.DB, products []schemas.Products, updates map[string]interface{}) (int64, error) {
+	var affected int64
+	for i := range products {
+		before := products[i]
+		after := &products[i]
+		res := tx.Model(after).Updates(updates)
+		if res.Error != nil {
+			return 0, res.Error
+		}
+		affected += res.RowsAffected
+		if err := tx.First(after, before.ID).Error; err != nil {
+			return 0, err
+		}
+		if err := RecordProductRevision(tx, schemas.RevisionUpdate, &before, after, SystemActor); err != nil {
+			return 0, err
+		}
+	}
+	return affected, nil
+}
+
+// togglePromotion liga/desliga o flag de promoção e registra o novo preço efetivo no histórico.
+func togglePromotion(tx *gorm.DB, products []schemas.Products, active bool, now time.Time) (int64, error) {
+	affected, err := applyScheduledChanges(tx, products, map[string]interface{}{
+		"is_promotional": active,
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range products {
+		if err := tx.Create(priceEntry(p, schemas.PriceSourceSchedule, nil, now)).Error; err != nil {
+			return 0, err
+		}
+	}
+	return affected, nil
+}
@@ -0,0 +1,153 @@
+package catalog
+
+import (
+	"errors"
+	"math"
+
+	"backend_camisaria_store/schemas"
+
+	"gorm.io/gorm"
+)
+
+var ErrSizeChartNotFound = errors.New("tabela de medidas não encontrada")
+
+// Peso de cada medida na recomendação: o tórax define o caimento da camisa,
+// altura e peso só desempatam.
+var fitWeights = map[string]float64{
+	"chest":  2,
+	"height": 1,
+	"weight": 1,
+}
+
+// Measurements são as medidas do cliente (nil quando não informadas).
+type Measurements struct {
+	ChestCm  *float64
+	HeightCm *float64
+	WeightKg *float64
+}
+
+func (m Measurements) values() map[string]float64 {
+	values := make(map[string]float64, 3)
+	if m.ChestCm != nil {
+		values["chest"] = *m.ChestCm
+	}
+	if m.HeightCm != nil {
+		values["height"] = *m.HeightCm
+	}
+	if m.WeightKg != nil {
+		values["weight"] = *m.WeightKg
+	}
+	return values
+}
+
+// SizeRecommendation é o tamanho sugerido e como cada medida se encaixa nele.
+type SizeRecommendation struct {
+	Size  string            `json:"size"`
+	Exact bool              `json:"exact"` // todas as medidas dentro das faixas do tamanho
+	Fit   map[string]string `json:"fit"`   // medida → "ok", "abaixo" ou "acima"
+}
+
+// ProductSizeChart devolve a tabela do produto ou, na falta dela, a da categoria
+// mais próxima subindo na árvore.
+func ProductSizeChart(db *gorm.DB, product schemas.Products) (*schemas.SizeCharts, error) {
+	var chart schemas.SizeCharts
+	if product.SizeChartID != nil {
+		err := db.Where("id = ? AND is_active = ?", *product.SizeChartID, true).First(&chart).Error
+		if err == nil {
+			return &chart, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	categoryID := product.CategoryID
+	for depth := 0; categoryID != nil && depth < 10; depth++ {
+		err := db.Where("category_id = ? AND is_active = ?", *categoryID, true).
+			Order("id ASC").First(&chart).Error
+		if err == nil {
+			return &chart, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		var category schemas.Categories
+		if err := db.Select("id", "parent_id").First(&category, *categoryID).Error; err != nil {
+			break
+		}
+		categoryID = category.ParentID
+	}
+	return nil, ErrSizeChartNotFound
+}
+
+// RecommendSize escolhe o tamanho cujas faixas ficam mais próximas das medidas.
+// A distância fora da faixa é normalizada pela largura da faixa e a nota é a média
+// ponderada das medidas comparadas, para linhas com menos medidas não levarem vantagem.
+// Em empate vence a linha que compara mais medidas e, persistindo, a primeira da tabela.
+func RecommendSize(chart schemas.SizeCharts, m Measurements) (*SizeRecommendation, bool) {
+	values := m.values()
+	if len(values) == 0 || len(chart.Rows) == 0 {
+		return nil, false
+	}
+
+	bestIndex, bestCompared := -1, 0
+	bestScore := math.Inf(1)
+	for i, row := range chart.Rows {
+		score, weights, compared := 0.0, 0.0, 0
+		for key, value := range values {
+			r, ok := row.Measurements[key]
+			if !ok {
+				continue
+			}
+			compared++
+			weights += fitWeights[key]
+			score += fitWeights[key] * rangeDistance(r, value)
+		}
+		if compared == 0 {
+			continue
+		}
+		score /= weights
+		if score < bestScore || (score == bestScore && compared > bestCompared) {
+			bestScore = score
+			bestIndex = i
+			bestCompared = compared
+		}
+	}
+	if bestIndex < 0 {
+		return nil, false
+	}
+
+	row := chart.Rows[bestIndex]
+	rec := &SizeRecommendation{Size: row.Size, Exact: true, Fit: make(map[string]string)}
+	for key, value := range values {
+		r, ok := row.Measurements[key]
+		if !ok {
+			continue
+		}
+		switch {
+		case value < r.Min:
+			rec.Fit[key] = "abaixo"
+			rec.Exact = false
+		case value > r.Max:
+			rec.Fit[key] = "acima"
+			rec.Exact = false
+		default:
+			rec.Fit[key] = "ok"
+		}
+	}
+	return rec, true
+}
+
+func rangeDistance(r schemas.SizeRange, value float64) float64 {
+	width := r.Max - r.Min
+	if width <= 0 {
+		width = 1
+	}
+	switch {
+	case value < r.Min:
+		return (r.Min - value) / width
+	case value > r.Max:
+		return (value - r.Max) / width
+	}
+	return 0
+}
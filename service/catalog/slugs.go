@@ -0,0 +1,105 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/search"
+
+	"gorm.io/gorm"
+)
+
+// maxSlugLen mantém espaço para o sufixo numérico dentro de varchar(160).
+const maxSlugLen = 150
+
+// Slugify gera um identificador seguro para URL ("Camisa Social Algodão" → "camisa-social-algodao").
+func Slugify(s string) string {
+	slug := strings.Join(search.Tokenize(s), "-")
+	if len(slug) > maxSlugLen {
+		slug = strings.TrimRight(slug[:maxSlugLen], "-")
+	}
+	return slug
+}
+
+// UniqueProductSlug gera um slug a partir do texto que não colide com outro produto
+// nem com slugs antigos (histórico) de outros produtos. productID é o produto que
+// receberá o slug (0 na criação); slugs antigos dele mesmo podem ser reaproveitados.
+func UniqueProductSlug(db *gorm.DB, text string, productID uint64) (string, error) {
+	base := Slugify(text)
+	if base == "" {
+		base = "produto"
+	}
+
+	for i := 1; i < 1000; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, i)
+		}
+
+		var products, history int64
+		if err := db.Unscoped().Model(&schemas.Products{}).
+			Where("slug = ? AND id <> ?", candidate, productID).
+			Count(&products).Error; err != nil {
+			return "", err
+		}
+		if err := db.Model(&schemas.ProductSlugHistory{}).
+			Where("slug = ? AND product_id <> ?", candidate, productID).
+			Count(&history).Error; err != nil {
+			return "", err
+		}
+		if products == 0 && history == 0 {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("não foi possível gerar slug único para %q", text)
+}
+
+// ChangeProductSlug grava o novo slug do produto e guarda o anterior no histórico
+// para que links antigos continuem redirecionando.
+func ChangeProductSlug(tx *gorm.DB, product *schemas.Products, newSlug string) error {
+	if product.Slug != nil && *product.Slug == newSlug {
+		return nil
+	}
+
+	// O slug volta a ser o atual: sai do histórico
+	if err := tx.Where("product_id = ? AND slug = ?", product.ID, newSlug).
+		Delete(&schemas.ProductSlugHistory{}).Error; err != nil {
+		return err
+	}
+
+	if product.Slug != nil && *product.Slug != "" {
+		if err := tx.Create(&schemas.ProductSlugHistory{
+			ProductID: product.ID,
+			Slug:      *product.Slug,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Model(&schemas.Products{}).Where("id = ?", product.ID).
+		UpdateColumn("slug", newSlug).Error; err != nil {
+		return err
+	}
+	product.Slug = &newSlug
+	return nil
+}
+
+// BackfillProductSlugs gera slug para produtos cadastrados antes da existência do campo.
+func BackfillProductSlugs(db *gorm.DB) error {
+	var products []schemas.Products
+	if err := db.Select("id", "name").Where("slug IS NULL OR slug = ''").Find(&products).Error; err != nil {
+		return err
+	}
+	for _, p := range products {
+		slug, err := UniqueProductSlug(db, p.Name, p.ID)
+		if err != nil {
+			return err
+		}
+		if err := db.Model(&schemas.Products{}).Where("id = ?", p.ID).
+			UpdateColumn("slug", slug).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package catalog
+
+import (
+	"os"
+	"strings"
+)
+
+// Caminhos das páginas no front da loja (STORE_PUBLIC_URL + caminho + slug).
+const (
+	ProductPath    = "/produtos/"
+	CategoryPath   = "/categorias/"
+	CollectionPath = "/colecoes/"
+)
+
+// StoreURL monta a URL pública da página no front; vazio se STORE_PUBLIC_URL não estiver configurada.
+func StoreURL(path, slug string) string {
+	base := strings.TrimSuffix(strings.TrimSpace(os.Getenv("STORE_PUBLIC_URL")), "/")
+	if base == "" || slug == "" {
+		return ""
+	}
+	return base + path + slug
+}
@@ -0,0 +1,187 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// reuseRenditions devolve as versões já gravadas para o hash, somando uma referência.
+// ok é false quando falta alguma versão no registro ou no bucket (aí elas são regeradas).
+// Os registros ficam travados (FOR UPDATE) da consulta até o incremento, como em
+// ReleaseObjects: uma remoção concorrente não apaga os arquivos que estão sendo reaproveitados.
+func reuseRenditions(hash string) (*ProcessedImage, bool) {
+	var processed *ProcessedImage
+	errMissing := errors.New("versões incompletas")
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var objects []schemas.StoredObjects
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("hash = ? AND ref_count > 0", hash).Find(&objects).Error; err != nil {
+			return err
+		}
+		processed = processedFromObjects(objects)
+		if processed == nil {
+			return errMissing
+		}
+
+		// O arquivo pode ter sido apagado (storage-gc) com o registro ainda ativo
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		for _, obj := range objects {
+			if _, err := config.Storage.Stat(ctx, obj.ObjectKey); err != nil {
+				return errMissing
+			}
+		}
+		return retainObjects(tx, objects)
+	})
+	if err != nil {
+		return nil, false
+	}
+	return processed, true
+}
+
+// retainObjects soma uma referência a cada objeto, criando o registro quando não existe.
+// O upsert trava o registro até o fim da transação de tx.
+func retainObjects(tx *gorm.DB, objects []schemas.StoredObjects) error {
+	for _, obj := range objects {
+		row := obj
+		row.ID = 0
+		row.RefCount = 1
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "object_key"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"ref_count": gorm.Expr("ref_count + 1"),
+				"size":      row.Size,
+			}),
+		}).Create(&row).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processedFromObjects monta as versões (com as keys) a partir dos registros; nil quando falta o JPEG
+// de alguma versão.
+func processedFromObjects(objects []schemas.StoredObjects) *ProcessedImage {
+	renditions := make(map[string]schemas.ImageRendition, len(productRenditions))
+	keys := make(map[string]string)
+	for _, obj := range objects {
+		r := renditions[obj.Variant]
+		switch obj.Format {
+		case "jpeg":
+			r.JPEG = obj.ObjectKey
+			r.Width, r.Height = obj.Width, obj.Height
+			keys[obj.Variant] = obj.ObjectKey
+		case "webp":
+			r.WebP = obj.ObjectKey
+		}
+		renditions[obj.Variant] = r
+	}
+	for _, spec := range productRenditions {
+		if renditions[spec.Name].JPEG == "" {
+			return nil
+		}
+	}
+	return &ProcessedImage{
+		ObjectName: keys[RenditionZoom],
+		Renditions: renditions,
+	}
+}
+
+// ReleaseObjects devolve uma referência de cada URL. O arquivo só sai do bucket quando
+// nenhuma imagem o usa mais (Retained indica que ficou); URLs sem registro em
+// stored_objects (anteriores à deduplicação) são apagadas direto.
+func ReleaseObjects(urls []string) []DeleteImageResult {
+	var retained []DeleteImageResult
+	var toDelete, released []string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		retained, toDelete, released = nil, nil, nil
+		for _, u := range urls {
+			key, err := ObjectKeyFormUrl(u)
+			if err != nil || key == "" {
+				toDelete = append(toDelete, u) // DeleteObjects relata a URL inválida
+				continue
+			}
+
+			var obj schemas.StoredObjects
+			err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("object_key = ?", key).First(&obj).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				toDelete = append(toDelete, u)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			if obj.RefCount > 1 {
+				if err := tx.Model(&obj).UpdateColumn("ref_count", gorm.Expr("ref_count - 1")).Error; err != nil {
+					return err
+				}
+				retained = append(retained, DeleteImageResult{ImageURL: u, Success: true, Key: key, Retained: true})
+				continue
+			}
+			if err := tx.Delete(&obj).Error; err != nil {
+				return err
+			}
+			released = append(released, u)
+		}
+		return nil
+	})
+	if err != nil {
+		results := make([]DeleteImageResult, 0, len(urls))
+		for _, u := range urls {
+			results = append(results, DeleteImageResult{ImageURL: u, Error: "falha ao liberar referência: " + err.Error()})
+		}
+		return results
+	}
+
+	// Arquivos saem depois do commit; os liberados aqui só se ninguém voltou a usá-los
+	results := append(retained, DeleteObjects(toDelete)...)
+	for _, u := range released {
+		results = append(results, deleteUnreferenced(u))
+	}
+	return results
+}
+
+// deleteUnreferenced apaga o arquivo cujo registro foi removido por ReleaseObjects, desde que
+// o registro continue ausente (ou zerado). A trava vale até o fim da remoção: um envio
+// concorrente do mesmo conteúdo espera no upsert de retainObjects, que uploadRenditions
+// faz antes de gravar os arquivos; se o envio travou primeiro, o arquivo fica.
+func deleteUnreferenced(u string) DeleteImageResult {
+	key, _ := ObjectKeyFormUrl(u)
+	var result DeleteImageResult
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var objects []schemas.StoredObjects
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("object_key = ?", key).Find(&objects).Error; err != nil {
+			return err
+		}
+		if len(objects) > 0 && objects[0].RefCount > 0 {
+			result = DeleteImageResult{ImageURL: u, Success: true, Key: key, Retained: true}
+			return nil
+		}
+		result = DeleteObjects([]string{u})[0]
+		if len(objects) > 0 && result.Success {
+			return tx.Delete(&objects[0]).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return DeleteImageResult{ImageURL: u, Error: "falha ao liberar referência: " + err.Error()}
+	}
+	return result
+}
+
+// isTrackedObject indica se a key é de um objeto deduplicado (com contagem de referências).
+func isTrackedObject(key string) (bool, error) {
+	var count int64
+	err := config.DB.Model(&schemas.StoredObjects{}).Where("object_key = ?", key).Count(&count).Error
+	return count > 0, err
+}
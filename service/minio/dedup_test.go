@@ -0,0 +1,115 @@
+package minio
+
+import (
+	"context"
+	"testing"
+
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/catalog"
+)
+
+func TestUploadRenditionsAndRelease(t *testing.T) {
+	memory := setupTestEnv(t)
+	ctx := context.Background()
+	data := testJPEG(t, 300, 200, 7)
+
+	first, err := uploadRenditions(data)
+	if err != nil {
+		t.Fatalf("primeiro envio: %v", err)
+	}
+	if len(first.Renditions) != len(productRenditions) {
+		t.Fatalf("versões = %d, want %d", len(first.Renditions), len(productRenditions))
+	}
+	for name, r := range first.Renditions {
+		if r.JPEG == "" || isAbsoluteURL(r.JPEG) {
+			t.Errorf("versão %s: JPEG = %q, want key do objeto", name, r.JPEG)
+		}
+	}
+	if first.ObjectName != first.Renditions[RenditionZoom].JPEG {
+		t.Errorf("ObjectName = %q, want o JPEG do zoom %q", first.ObjectName, first.Renditions[RenditionZoom].JPEG)
+	}
+
+	// O mesmo conteúdo reaproveita os objetos e soma uma referência
+	second, err := uploadRenditions(data)
+	if err != nil {
+		t.Fatalf("segundo envio: %v", err)
+	}
+	if second.ObjectName != first.ObjectName {
+		t.Errorf("segundo envio gravou %q, want reaproveitar %q", second.ObjectName, first.ObjectName)
+	}
+
+	refs := catalog.ImageObjectRefs(schemas.ProductImages{URL: first.ObjectName, Renditions: first.Renditions})
+	assertRefCounts(t, refs, 2)
+
+	steps := []struct {
+		name         string
+		wantRetained bool
+		wantRefCount int // 0 = registro removido
+		wantPresent  bool
+	}{
+		{"primeira liberação mantém os arquivos", true, 1, true},
+		{"última liberação apaga os arquivos", false, 0, false},
+	}
+	for _, step := range steps {
+		results := ReleaseObjects(refs)
+		if len(results) != len(refs) {
+			t.Fatalf("%s: %d resultados, want %d", step.name, len(results), len(refs))
+		}
+		for _, r := range results {
+			if !r.Success || r.Retained != step.wantRetained {
+				t.Errorf("%s: %s = %+v", step.name, r.ImageURL, r)
+			}
+		}
+		assertRefCounts(t, refs, step.wantRefCount)
+		for _, key := range refs {
+			if _, err := memory.Stat(ctx, key); (err == nil) != step.wantPresent {
+				t.Errorf("%s: objeto %s presente = %v, want %v", step.name, key, err == nil, step.wantPresent)
+			}
+		}
+	}
+}
+
+func TestReleaseObjectsKeepsReacquiredFile(t *testing.T) {
+	memory := setupTestEnv(t)
+	processed, err := uploadRenditions(testJPEG(t, 120, 120, 3))
+	if err != nil {
+		t.Fatalf("envio: %v", err)
+	}
+
+	// Registro recriado (novo envio) entre a liberação e a remoção do arquivo
+	key := processed.ObjectName
+	if err := config.DB.Model(&schemas.StoredObjects{}).Where("object_key = ?", key).
+		Update("ref_count", 2).Error; err != nil {
+		t.Fatalf("ajustar referência: %v", err)
+	}
+	result := deleteUnreferenced(key)
+	if !result.Retained {
+		t.Errorf("deleteUnreferenced com referência ativa = %+v, want Retained", result)
+	}
+	if _, err := memory.Stat(context.Background(), key); err != nil {
+		t.Errorf("arquivo com referência ativa foi apagado: %v", err)
+	}
+}
+
+func assertRefCounts(t *testing.T, keys []string, want int) {
+	t.Helper()
+	var objects []schemas.StoredObjects
+	if err := config.DB.Where("object_key IN ?", keys).Find(&objects).Error; err != nil {
+		t.Fatalf("consultar stored_objects: %v", err)
+	}
+	if want == 0 {
+		if len(objects) != 0 {
+			t.Errorf("%d registro(s) em stored_objects, want nenhum", len(objects))
+		}
+		return
+	}
+	if len(objects) != len(keys) {
+		t.Fatalf("%d registro(s) em stored_objects, want %d", len(objects), len(keys))
+	}
+	for _, obj := range objects {
+		if obj.RefCount != want {
+			t.Errorf("%s: ref_count = %d, want %d", obj.ObjectKey, obj.RefCount, want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package minio
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/storage"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const testStorageURL = "http://storage.local"
+
+// setupTestEnv troca o banco por um SQLite em memória e o armazenamento por
+// storage.NewMemory, restaurando os globais ao fim do teste.
+func setupTestEnv(t *testing.T) *storage.MemoryStorage {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("abrir banco: %v", err)
+	}
+	if err := db.AutoMigrate(&schemas.StoredObjects{}, &schemas.ProductImages{}); err != nil {
+		t.Fatalf("migrar banco: %v", err)
+	}
+	// products tem índice FULLTEXT (só MySQL): basta a coluna de imagens usada aqui
+	if err := db.Exec("CREATE TABLE products (id INTEGER PRIMARY KEY, images JSON, deleted_at DATETIME)").Error; err != nil {
+		t.Fatalf("criar products: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("conexão do banco: %v", err)
+	}
+	// Uma conexão: o banco em memória compartilhado não aceita escritas concorrentes
+	sqlDB.SetMaxOpenConns(1)
+
+	memory := storage.NewMemory(testStorageURL)
+	prevDB, prevStorage, prevBucket := config.DB, config.Storage, config.BunkedName
+	config.DB, config.Storage, config.BunkedName = db, memory, ""
+	t.Cleanup(func() {
+		config.DB, config.Storage, config.BunkedName = prevDB, prevStorage, prevBucket
+		sqlDB.Close()
+	})
+	return memory
+}
+
+// testImage gera uma imagem com ruído (não comprime abaixo do tamanho mínimo aceito).
+func testImage(width, height int, seed uint8) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := uint8(x*31+y*17) ^ seed
+			img.Set(x, y, color.RGBA{R: v, G: v * 3, B: v * 7, A: 255})
+		}
+	}
+	return img
+}
+
+func testJPEG(t *testing.T, width, height int, seed uint8) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(width, height, seed), &jpeg.Options{Quality: 90}); err != nil {
+		t.Fatalf("gerar JPEG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(width, height, 0)); err != nil {
+		t.Fatalf("gerar PNG: %v", err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,274 @@
+package minio
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"time"
+
+	// Formatos aceitos no upload (image.Decode)
+	_ "image/gif"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+
+	"golang.org/x/image/draw"
+	"gorm.io/gorm"
+)
+
+// Versões geradas para cada imagem de produto: lado maior em pixels, sem ampliar.
+const (
+	RenditionThumb = "thumb"
+	RenditionCard  = "card"
+	RenditionZoom  = "zoom"
+)
+
+type renditionSpec struct {
+	Name    string
+	MaxSide int
+	Quality int // JPEG
+}
+
+var productRenditions = []renditionSpec{
+	{Name: RenditionThumb, MaxSide: 240, Quality: 78},
+	{Name: RenditionCard, MaxSide: 720, Quality: 80},
+	{Name: RenditionZoom, MaxSide: 1600, Quality: 85},
+}
+
+// ProcessedImage é o resultado do upload com as versões geradas.
+type ProcessedImage struct {
+	ObjectName string // key do JPEG do zoom (maior versão)
+	Renditions map[string]schemas.ImageRendition
+}
+
+// uploadRenditions decodifica a imagem, corrige a orientação pelo EXIF e grava no bucket
+// as versões thumb, card e zoom em JPEG (sem metadados). Não há versão WebP: o único
+// codificador disponível em Go puro (nativewebp) é sem perdas e gera arquivos maiores que
+// o JPEG. O arquivo original não é guardado; data já passou por validateImageBytes.
+//
+// Os objetos são nomeados pelo SHA-256 do arquivo enviado: o mesmo conteúdo (outra cor do
+// produto, reenvio após erro) reaproveita as versões já gravadas. Cada chamada bem-sucedida
+// soma uma referência aos objetos; quem não usar o resultado devolve com ReleaseObjects.
+func uploadRenditions(data []byte) (*ProcessedImage, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	if processed, ok := reuseRenditions(hash); ok {
+		return processed, nil
+	}
+
+	objects, files, err := encodeRenditions(data, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	// As keys são fixas pelo hash: a referência é gravada (e o registro travado) antes do
+	// envio, e uma liberação concorrente do mesmo conteúdo espera o commit em
+	// deleteUnreferenced. Falhas no envio desfazem as referências; arquivos gravados pela
+	// metade ficam sem registro e são recolhidos pelo job storage-gc.
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := retainObjects(tx, objects); err != nil {
+			return err
+		}
+		for i, obj := range objects {
+			if err := putObject(obj.ObjectKey, files[i], obj.ContentType); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return processedFromObjects(objects), nil
+}
+
+// encodeRenditions gera em memória o JPEG de cada versão; files[i] é o conteúdo de objects[i].
+// A vaga de decodificação fica ocupada só durante a geração, não durante o envio ao bucket.
+func encodeRenditions(data []byte, hash string) ([]schemas.StoredObjects, [][]byte, error) {
+	release := acquireDecodeSlot()
+	defer release()
+	img, err := decodeImage(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	base := productObjectsPrefix + hash
+	objects := make([]schemas.StoredObjects, 0, len(productRenditions))
+	files := make([][]byte, 0, len(productRenditions))
+	for _, spec := range productRenditions {
+		resized := resizeToFit(img, spec.MaxSide)
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: spec.Quality}); err != nil {
+			return nil, nil, err
+		}
+		objects = append(objects, schemas.StoredObjects{
+			ObjectKey: base + "-" + spec.Name + ".jpg", Hash: hash, Variant: spec.Name, Format: "jpeg",
+			ContentType: "image/jpeg", Size: int64(buf.Len()),
+			Width: resized.Bounds().Dx(), Height: resized.Bounds().Dy(),
+		})
+		files = append(files, buf.Bytes())
+	}
+	return objects, files, nil
+}
+
+// decodeImage decodifica JPEG/PNG/GIF/WebP, aplica a orientação do EXIF e achata a
+// transparência sobre fundo branco (as versões são JPEG).
+func decodeImage(data []byte) (*image.RGBA, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, reject(RejectCorrupt, "Imagem corrompida ou inválida")
+	}
+
+	bounds := img.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, bounds.Min, draw.Over)
+
+	if format == "jpeg" {
+		return applyOrientation(flat, exifOrientation(data)), nil
+	}
+	return flat, nil
+}
+
+func resizeToFit(img *image.RGBA, maxSide int) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if w <= maxSide && h <= maxSide {
+		return img
+	}
+	if w >= h {
+		h = h * maxSide / w
+		w = maxSide
+	} else {
+		w = w * maxSide / h
+		h = maxSide
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+	return dst
+}
+
+// applyOrientation gira/espelha a imagem conforme a tag Orientation (1–8) do EXIF.
+func applyOrientation(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			dst.SetRGBA(x, y, img.RGBAAt(sx, sy))
+		}
+	}
+	return dst
+}
+
+// exifOrientation lê a tag Orientation (0x0112) do segmento APP1 de um JPEG; 1 quando ausente.
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 { // início dos dados da imagem
+			return 1
+		}
+		segLen := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + segLen
+		if segLen < 2 || end > len(data) {
+			return 1
+		}
+		if marker == 0xE1 && segLen >= 8 && string(data[i+4:i+10]) == "Exif\x00\x00" {
+			return tiffOrientation(data[i+10 : end])
+		}
+		i = end
+	}
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < entries; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			value := int(order.Uint16(tiff[entry+8:]))
+			if value < 1 || value > 8 {
+				return 1
+			}
+			return value
+		}
+	}
+	return 1
+}
+
+func putObject(name string, data []byte, contentType string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	return config.Storage.Put(ctx, name, bytes.NewReader(data), int64(len(data)), contentType)
+}
+
+// removeObjects apaga objetos temporários; falhas aqui só deixam arquivos órfãos.
+func removeObjects(names []string) {
+	for _, name := range names {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		_ = config.Storage.Delete(ctx, name)
+		cancel()
+	}
+}
@@ -0,0 +1,175 @@
+package minio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+)
+
+// Motivos de recusa devolvidos por arquivo (campo "reason" nas respostas de upload).
+const (
+	RejectUnsupportedType   = "unsupported_type"
+	RejectExtensionMismatch = "extension_mismatch"
+	RejectFileTooLarge      = "file_too_large"
+	RejectFileTooSmall      = "file_too_small"
+	RejectSizeMismatch      = "size_mismatch" // envio direto com tamanho diferente do declarado
+	RejectDimensions        = "dimensions_too_large"
+	RejectCorrupt           = "corrupt_image"
+)
+
+// Limites de dimensão: evitam "bombas" de descompressão (arquivo pequeno, imagem enorme).
+// 25 MP decodificados ocupam ~100MB em RGBA; decodeSlots limita quantos ficam em memória.
+const (
+	maxImageSide    = 10000
+	maxDecodePixels = 25_000_000
+)
+
+// Decodificações simultâneas em todo o processo (uploads multipart, confirmações e fotos
+// de avaliação), independente de quantos workers ou requisições estejam ativos.
+const maxConcurrentDecodes = 2
+
+var decodeSlots = make(chan struct{}, maxConcurrentDecodes)
+
+// acquireDecodeSlot espera uma vaga para decodificar; chame a função devolvida ao terminar
+// de usar a imagem decodificada.
+func acquireDecodeSlot() func() {
+	decodeSlots <- struct{}{}
+	return func() { <-decodeSlots }
+}
+
+// ImageRejection é a recusa de um arquivo enviado, com o motivo em formato fixo.
+type ImageRejection struct {
+	Reason  string
+	Message string
+}
+
+func (e *ImageRejection) Error() string {
+	return e.Message
+}
+
+func reject(reason, message string) *ImageRejection {
+	return &ImageRejection{Reason: reason, Message: message}
+}
+
+// RejectionReason devolve o motivo da recusa ou "" quando o erro não é de validação.
+func RejectionReason(err error) string {
+	var rejection *ImageRejection
+	if errors.As(err, &rejection) {
+		return rejection.Reason
+	}
+	return ""
+}
+
+// Extensões aceitas para cada tipo detectado pelo conteúdo.
+var imageExtensions = map[string][]string{
+	"image/jpeg": {".jpg", ".jpeg"},
+	"image/png":  {".png"},
+	"image/gif":  {".gif"},
+	"image/webp": {".webp"},
+}
+
+// sniffImageType identifica o formato pelos primeiros bytes (assinatura do arquivo),
+// ignorando o Content-Type e a extensão informados pelo cliente.
+func sniffImageType(data []byte) string {
+	switch {
+	case len(data) >= 3 && data[0] == 0xFF && data[1] == 0xD8 && data[2] == 0xFF:
+		return "image/jpeg"
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return "image/png"
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return "image/gif"
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return "image/webp"
+	}
+	return ""
+}
+
+// readValidatedImage lê o arquivo do multipart e valida conteúdo, extensão e dimensões.
+// Com fullDecode a imagem é decodificada por inteiro para confirmar que não está corrompida;
+// sem ele a decodificação fica para quem processa os bytes (ex.: geração das versões).
+func readValidatedImage(file *multipart.FileHeader, fullDecode bool) ([]byte, error) {
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if _, ok := presignContentTypes[mimeForExtension(ext)]; !ok {
+		return nil, reject(RejectUnsupportedType, "Extensão de arquivo não permitida. Use JPEG, PNG, WebP ou GIF")
+	}
+	if file.Size > maxImageSize {
+		return nil, reject(RejectFileTooLarge, "Arquivo muito grande. Tamanho máximo: 5MB")
+	}
+	if file.Size < minImageSize {
+		return nil, reject(RejectFileTooSmall, "Arquivo muito pequeno. Tamanho mínimo: 1KB")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	data, err := io.ReadAll(io.LimitReader(src, maxImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := validateImageBytes(data, ext, fullDecode); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// validateImageBytes confere tamanho real, assinatura, coerência com a extensão (quando
+// informada) e dimensões declaradas no cabeçalho. Devolve o tipo detectado.
+func validateImageBytes(data []byte, ext string, fullDecode bool) (string, error) {
+	if len(data) > maxImageSize {
+		return "", reject(RejectFileTooLarge, "Arquivo muito grande. Tamanho máximo: 5MB")
+	}
+	if len(data) < minImageSize {
+		return "", reject(RejectFileTooSmall, "Arquivo muito pequeno. Tamanho mínimo: 1KB")
+	}
+
+	detected := sniffImageType(data)
+	if detected == "" {
+		return "", reject(RejectUnsupportedType, "O conteúdo do arquivo não é uma imagem JPEG, PNG, WebP ou GIF")
+	}
+	if ext != "" && mimeForExtension(ext) != detected {
+		return "", reject(RejectExtensionMismatch,
+			fmt.Sprintf("A extensão %s não corresponde ao conteúdo do arquivo (%s)", ext, detected))
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", reject(RejectCorrupt, "Imagem corrompida ou inválida")
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return "", reject(RejectCorrupt, "Imagem corrompida ou inválida")
+	}
+	if cfg.Width > maxImageSide || cfg.Height > maxImageSide || cfg.Width*cfg.Height > maxDecodePixels {
+		return "", reject(RejectDimensions,
+			fmt.Sprintf("Imagem de %dx%d pixels excede o limite (%d px por lado, %d megapixels)",
+				cfg.Width, cfg.Height, maxImageSide, maxDecodePixels/1_000_000))
+	}
+
+	if fullDecode {
+		release := acquireDecodeSlot()
+		_, _, err := image.Decode(bytes.NewReader(data))
+		release()
+		if err != nil {
+			return "", reject(RejectCorrupt, "Imagem corrompida ou inválida")
+		}
+	}
+	return detected, nil
+}
+
+func mimeForExtension(ext string) string {
+	for mime, exts := range imageExtensions {
+		for _, e := range exts {
+			if e == ext {
+				return mime
+			}
+		}
+	}
+	return ""
+}
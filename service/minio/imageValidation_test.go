@@ -0,0 +1,71 @@
+package minio
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSniffImageType(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"jpeg", []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00}, "image/jpeg"},
+		{"png", []byte("\x89PNG\r\n\x1a\n\x00\x00"), "image/png"},
+		{"gif87a", []byte("GIF87a..."), "image/gif"},
+		{"gif89a", []byte("GIF89a..."), "image/gif"},
+		{"webp", []byte("RIFF\x00\x00\x00\x00WEBPVP8 "), "image/webp"},
+		{"riff sem webp", []byte("RIFF\x00\x00\x00\x00WAVEfmt "), ""},
+		{"html", []byte("<html><body>"), ""},
+		{"curto", []byte{0xFF, 0xD8}, ""},
+		{"vazio", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sniffImageType(tt.data); got != tt.want {
+				t.Errorf("sniffImageType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateImageBytes(t *testing.T) {
+	validJPEG := testJPEG(t, 64, 64, 0)
+	validPNG := testPNG(t, 64, 64)
+	// Cabeçalho íntegro com o restante do arquivo corrompido: só a decodificação completa recusa
+	truncated := append(append([]byte{}, validJPEG[:len(validJPEG)/2]...), bytes.Repeat([]byte{0x00}, 2048)...)
+
+	tests := []struct {
+		name       string
+		data       []byte
+		ext        string
+		fullDecode bool
+		wantType   string
+		wantReason string
+	}{
+		{"jpeg válido", validJPEG, ".jpg", true, "image/jpeg", ""},
+		{"png sem extensão", validPNG, "", true, "image/png", ""},
+		{"extensão divergente", validPNG, ".jpg", false, "", RejectExtensionMismatch},
+		{"muito pequeno", validJPEG[:512], ".jpg", false, "", RejectFileTooSmall},
+		{"muito grande", bytes.Repeat([]byte{0xFF}, maxImageSize+1), ".jpg", false, "", RejectFileTooLarge},
+		{"não é imagem", bytes.Repeat([]byte("<html>"), 400), "", false, "", RejectUnsupportedType},
+		{"dimensões acima do limite", testPNG(t, maxImageSide+1, 16), ".png", false, "", RejectDimensions},
+		{"corrompido sem decodificação completa", truncated, ".jpg", false, "image/jpeg", ""},
+		{"corrompido com decodificação completa", truncated, ".jpg", true, "", RejectCorrupt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateImageBytes(tt.data, tt.ext, tt.fullDecode)
+			if reason := RejectionReason(err); reason != tt.wantReason {
+				t.Fatalf("motivo = %q (erro %v), want %q", reason, err, tt.wantReason)
+			}
+			if tt.wantReason == "" && err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if got != tt.wantType {
+				t.Errorf("tipo = %q, want %q", got, tt.wantType)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend_camisaria_store/common"
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/service/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Prefixos com arquivos de clientes (artes de uniformes): só saem por link assinado.
+var privatePrefixes = []string{"quotes/"}
+
+// Envios diretos ainda não confirmados: não passaram pela validação e nunca são servidos.
+const pendingUploadsPrefix = "uploads/"
+
+// Os links assinados vencem em janelas fixas para a mesma URL poder ser cacheada
+// pelo navegador durante a janela.
+const signedURLWindow = 15 * time.Minute
+
+// ServeMedia — GET /public/media/* — entrega imagens públicas do bucket pela API, com
+// cache longo: as keys de produtos são nomeadas pelo hash do conteúdo e nunca mudam.
+func ServeMedia(c *fiber.Ctx) error {
+	key, ok := mediaKey(c.Params("*"))
+	if !ok || !IsPublicMediaKey(key) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Arquivo não encontrado"})
+	}
+	return sendObject(c, key, "public, max-age=31536000, immutable")
+}
+
+// ServeSignedFile — GET /public/files/*?expires=&sig= — entrega arquivos privados a partir
+// de um link gerado por SignedURL, enquanto a assinatura for válida.
+func ServeSignedFile(c *fiber.Ctx) error {
+	key, ok := mediaKey(c.Params("*"))
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Arquivo não encontrado"})
+	}
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	now := time.Now()
+	if err != nil || !storage.VerifySignature(mediaSigningKey(), key, expires, c.Query("sig"), now) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Link inválido ou expirado"})
+	}
+	return sendObject(c, key, fmt.Sprintf("private, max-age=%d", expires-now.Unix()))
+}
+
+// SignedURL gera o link temporário (rota /public/files) para um arquivo privado.
+// API_PUBLIC_URL é a base pública da API (padrão http://localhost:4041).
+func SignedURL(key string, ttl time.Duration) string {
+	if key == "" {
+		return ""
+	}
+	window := int64(signedURLWindow / time.Second)
+	expires := time.Now().Add(ttl).Unix()
+	expires = (expires + window - 1) / window * window
+
+	base := strings.TrimRight(os.Getenv("API_PUBLIC_URL"), "/")
+	if base == "" {
+		base = "http://localhost:4041"
+	}
+	escaped := (&url.URL{Path: key}).EscapedPath()
+	return fmt.Sprintf("%s/public/files/%s?expires=%d&sig=%s",
+		base, escaped, expires, storage.SignKey(mediaSigningKey(), key, expires))
+}
+
+// mediaSigningKey usa MEDIA_SIGNING_KEY e, sem ela, o segredo do JWT.
+func mediaSigningKey() []byte {
+	if key := os.Getenv("MEDIA_SIGNING_KEY"); key != "" {
+		return []byte(key)
+	}
+	return common.GetJWTSecret()
+}
+
+func sendObject(c *fiber.Ctx, key, cacheControl string) error {
+	statCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	info, err := config.Storage.Stat(statCtx, key)
+	cancel()
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Arquivo não encontrado"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "Erro ao acessar o armazenamento"})
+	}
+
+	etag := fmt.Sprintf(`"%x-%x"`, info.Size, info.LastModified.UnixNano())
+	c.Set(fiber.HeaderCacheControl, cacheControl)
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderLastModified, info.LastModified.UTC().Format(time.RFC1123))
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	// Arquivos enviados por clientes (SVG, PDF) não executam scripts no domínio da API
+	c.Set(fiber.HeaderContentSecurityPolicy, "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	if c.Get(fiber.HeaderIfNoneMatch) == etag {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	// O stream é lido depois do handler retornar: o contexto não pode ser cancelado aqui
+	body, err := config.Storage.Get(context.Background(), key)
+	if err != nil {
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "Erro ao acessar o armazenamento"})
+	}
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = fiber.MIMEOctetStream
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.SendStream(body, int(info.Size))
+}
+
+// mediaKey normaliza a key do path e recusa tentativas de sair do bucket ("..").
+func mediaKey(raw string) (string, bool) {
+	if unescaped, err := url.PathUnescape(raw); err == nil {
+		raw = unescaped
+	}
+	if raw == "" || strings.Contains(raw, "..") || strings.Contains(raw, "\\") {
+		return "", false
+	}
+	key := strings.TrimPrefix(path.Clean("/"+raw), "/")
+	return key, key != "" && key != "."
+}
+
+// IsPrivateKey indica se a key só pode sair por link assinado.
+func IsPrivateKey(key string) bool {
+	for _, prefix := range privatePrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPublicMediaKey indica se a key pode ser servida sem assinatura: nem arquivo privado,
+// nem envio direto pendente de confirmação.
+func IsPublicMediaKey(key string) bool {
+	return !IsPrivateKey(key) && !strings.HasPrefix(key, pendingUploadsPrefix)
+}
@@ -0,0 +1,113 @@
+package minio
+
+import (
+	"encoding/json"
+	"strings"
+
+	"backend_camisaria_store/schemas"
+
+	"gorm.io/gorm"
+)
+
+// MigrateImageKeys troca pelas keys dos objetos as URLs completas gravadas na galeria, em
+// Products.Images, nas fotos das avaliações e nas imagens das categorias antes de o banco
+// guardar só keys. URLs fora desses diretórios (imagens externas) ficam como estão.
+// Idempotente: roda a cada inicialização.
+func MigrateImageKeys(db *gorm.DB) error {
+	var images []schemas.ProductImages
+	err := db.Where("url LIKE ?", "%://%").FindInBatches(&images, 500, func(tx *gorm.DB, batch int) error {
+		for _, img := range images {
+			row := img
+			row.URL = storedImageKey(img.URL)
+			row.Renditions = mapRenditions(img.Renditions, storedImageKey)
+			if row.ObjectKey == "" && row.URL != img.URL {
+				row.ObjectKey = row.URL
+			}
+			if err := tx.Model(&row).Select("url", "object_key", "renditions").Updates(&row).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	var products []schemas.Products
+	err = db.Unscoped().Select("id", "images").Where("images LIKE ?", "%://%").
+		FindInBatches(&products, 500, func(tx *gorm.DB, batch int) error {
+			for _, p := range products {
+				refs := JsonToStringSlice(p.Images)
+				for i, ref := range refs {
+					refs[i] = storedImageKey(ref)
+				}
+				data, err := json.Marshal(refs)
+				if err != nil {
+					return err
+				}
+				if err := tx.Unscoped().Model(&schemas.Products{}).Where("id = ?", p.ID).
+					UpdateColumn("images", data).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	var reviews []schemas.ProductReviews
+	err = db.Select("id", "photos").Where("photos LIKE ?", "%://%").
+		FindInBatches(&reviews, 500, func(tx *gorm.DB, batch int) error {
+			for _, r := range reviews {
+				for i, ref := range r.Photos {
+					r.Photos[i] = keyUnder(ref, "reviews/")
+				}
+				data, err := json.Marshal(r.Photos)
+				if err != nil {
+					return err
+				}
+				if err := tx.Model(&schemas.ProductReviews{}).Where("id = ?", r.ID).
+					UpdateColumn("photos", string(data)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	var categories []schemas.Categories
+	if err := db.Select("id", "image_url").Where("image_url LIKE ?", "%://%").Find(&categories).Error; err != nil {
+		return err
+	}
+	for _, c := range categories {
+		if key := keyUnder(c.ImageURL, "categories/"); key != c.ImageURL {
+			if err := db.Model(&schemas.Categories{}).Where("id = ?", c.ID).
+				UpdateColumn("image_url", key).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// storedImageKey devolve a key de uma URL de imagem de produto, ou a própria referência
+// quando ela não aponta para products/.
+func storedImageKey(ref string) string {
+	return keyUnder(ref, productObjectsPrefix)
+}
+
+// keyUnder devolve a key de uma URL do armazenamento quando ela fica sob prefix; nos
+// demais casos (key já gravada, imagem externa) devolve ref.
+func keyUnder(ref, prefix string) string {
+	if !isAbsoluteURL(ref) {
+		return ref
+	}
+	key, err := ObjectKeyFormUrl(ref)
+	if err != nil || !strings.HasPrefix(key, prefix) {
+		return ref
+	}
+	return key
+}
@@ -3,18 +3,18 @@ package minio
 import (
 	"backend_camisaria_store/config"
 	"backend_camisaria_store/schemas"
-	"context"
+	"backend_camisaria_store/service/catalog"
+	"backend_camisaria_store/service/storage"
 	"encoding/json"
 	"fmt"
 	"mime/multipart"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/minio/minio-go/v7"
 	"gorm.io/gorm"
 )
 
@@ -35,8 +35,8 @@ func JsonToStringSlice(jsonData []byte) []string {
 // Estrutura para resultado do upload de imagem
 type ImageUploadResult struct {
 	FileHeader *multipart.FileHeader
-	PublicURL  string
 	ObjectName string
+	Renditions map[string]schemas.ImageRendition
 	Error      error
 	Order      int
 }
@@ -46,8 +46,9 @@ func processImageUpload(jobChan <-chan ImageUploadJob, resultChan chan<- ImageUp
 	defer wg.Done()
 
 	for job := range jobChan {
-		// Validar arquivo
-		if err := ValidateImageFile(job.FileHeader); err != nil {
+		// Validar arquivo pelo conteúdo (a decodificação completa acontece ao gerar as versões)
+		data, err := readValidatedImage(job.FileHeader, false)
+		if err != nil {
 			resultChan <- ImageUploadResult{
 				FileHeader: job.FileHeader,
 				Error:      err,
@@ -56,14 +57,21 @@ func processImageUpload(jobChan <-chan ImageUploadJob, resultChan chan<- ImageUp
 			continue
 		}
 
-		// Fazer upload para MinIO
-		publicURL, objectName, err := UploadProductImage(job.FileHeader, job.ProductName, "products", job.ProductID)
+		// Gerar as versões (thumb, card, zoom) e enviar para o MinIO
+		processed, err := uploadRenditions(data)
+		if err != nil {
+			resultChan <- ImageUploadResult{
+				FileHeader: job.FileHeader,
+				Error:      err,
+				Order:      job.Order,
+			}
+			continue
+		}
 
 		resultChan <- ImageUploadResult{
 			FileHeader: job.FileHeader,
-			PublicURL:  publicURL,
-			ObjectName: objectName,
-			Error:      err,
+			ObjectName: processed.ObjectName,
+			Renditions: processed.Renditions,
 			Order:      job.Order,
 		}
 	}
@@ -136,10 +144,17 @@ func UploadImgesProduct(c *fiber.Ctx) error {
 	// ====================
 
 	// Contar imagens já existentes
-	existingImages := JsonToStringSlice(produto.Images)
-	currentImageCount := len(existingImages)
+	var existingCount int64
+	if err := config.DB.Model(&schemas.ProductImages{}).Where("product_id = ?", productID).
+		Count(&existingCount).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro interno do servidor",
+			"message": "Erro ao consultar imagens do produto",
+		})
+	}
+	currentImageCount := int(existingCount)
 
-	const maxImagesPerProduct = 5
+	const maxImagesPerProduct = catalog.MaxProductImages
 	if currentImageCount >= maxImagesPerProduct {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error":   "Limite de imagens excedido",
@@ -180,10 +195,15 @@ func UploadImgesProduct(c *fiber.Ctx) error {
 	// Processar imagens em paralelo (alta performance)
 	// ====================
 
-	// Preparar imagens existentes
-	existingImages = JsonToStringSlice(produto.Images)
-	newImageURLs := make([]string, len(existingImages))
-	copy(newImageURLs, existingImages)
+	// Metadados opcionais aplicados às imagens deste envio
+	altText := strings.TrimSpace(c.FormValue("alt_text"))
+	color := strings.TrimSpace(c.FormValue("color"))
+	if len(altText) > 255 || len(color) > 50 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"message": "alt_text deve ter no máximo 255 caracteres e color no máximo 50",
+		})
+	}
 
 	// Configurar concorrência baseada no número de CPUs disponíveis
 	numWorkers := runtime.NumCPU()
@@ -213,7 +233,7 @@ func UploadImgesProduct(c *fiber.Ctx) error {
 				FileHeader:  fileHeader,
 				ProductName: produto.Name,
 				ProductID:   productID,
-				Order:       len(existingImages) + i,
+				Order:       currentImageCount + i,
 			}
 		}
 	}()
@@ -226,6 +246,7 @@ func UploadImgesProduct(c *fiber.Ctx) error {
 
 	// Coletar resultados
 	var uploadedImages []fiber.Map
+	var newImages []schemas.ProductImages
 	var errors []fiber.Map
 	resultsCount := 0
 
@@ -233,21 +254,34 @@ func UploadImgesProduct(c *fiber.Ctx) error {
 		resultsCount++
 
 		if result.Error != nil {
-			errors = append(errors, fiber.Map{
+			fileError := fiber.Map{
 				"file":  result.FileHeader.Filename,
 				"error": result.Error.Error(),
-			})
+			}
+			if reason := RejectionReason(result.Error); reason != "" {
+				fileError["reason"] = reason
+			}
+			errors = append(errors, fileError)
 			continue
 		}
 
 		// Upload bem-sucedido
-		newImageURLs = append(newImageURLs, result.PublicURL)
+		newImages = append(newImages, schemas.ProductImages{
+			ProductID:  productID,
+			URL:        result.ObjectName,
+			ObjectKey:  result.ObjectName,
+			Position:   result.Order + 1,
+			AltText:    altText,
+			Color:      color,
+			Renditions: result.Renditions,
+		})
 		uploadedImages = append(uploadedImages, fiber.Map{
-			"url":        result.PublicURL,
+			"url":        MediaURL(result.ObjectName),
 			"filename":   result.FileHeader.Filename,
 			"size":       result.FileHeader.Size,
 			"order":      result.Order,
 			"object_key": result.ObjectName,
+			"renditions": RenditionURLs(result.Renditions),
 		})
 	}
 
@@ -255,30 +289,24 @@ func UploadImgesProduct(c *fiber.Ctx) error {
 	// Atualizar produto com novas imagens
 	// ====================
 
-	if len(uploadedImages) > 0 {
-		// Converter slice de strings para JSON
-		imagesJSON, err := json.Marshal(newImageURLs)
+	if len(newImages) > 0 {
+		// Mantém a ordem de envio; a galeria é renumerada e Products.Images regravado
+		sort.Slice(newImages, func(a, b int) bool { return newImages[a].Position < newImages[b].Position })
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&newImages).Error; err != nil {
+				return err
+			}
+			return catalog.SyncProductImages(tx, productID)
+		})
 		if err != nil {
+			// As imagens não entraram na galeria: devolve as referências aos arquivos
+			for _, img := range newImages {
+				ReleaseObjects(catalog.ImageObjectRefs(img))
+			}
+			uploadedImages = nil
 			errors = append(errors, fiber.Map{
-				"error": "Erro ao converter imagens para JSON: " + err.Error(),
+				"error": "Erro ao atualizar produto com novas imagens: " + err.Error(),
 			})
-		} else {
-			// Usar SQL direto para atualizar apenas o campo Images
-			result := config.DB.Model(&schemas.Products{}).
-				Where("id = ?", productID).
-				Update("images", imagesJSON)
-
-			if result.Error != nil {
-				// Se falhar ao atualizar, adicionar erro
-				errors = append(errors, fiber.Map{
-					"error": "Erro ao atualizar produto com novas imagens: " + result.Error.Error(),
-				})
-			} else if result.RowsAffected == 0 {
-				// Se não afetou nenhuma linha, produto não foi encontrado
-				errors = append(errors, fiber.Map{
-					"error": "Produto não encontrado para atualização",
-				})
-			}
 		}
 	}
 
@@ -289,6 +317,7 @@ func UploadImgesProduct(c *fiber.Ctx) error {
 	message := "Upload processado"
 	response := fiber.Map{
 		"message": message,
+		"images":  uploadedImages,
 	}
 
 	if len(errors) > 0 {
@@ -332,53 +361,97 @@ func DeleteImagesMinio(c *fiber.Ctx) error {
 	}
 
 	// ====================
-	// Processamento Paralelo
+	// Validação das URLs
 	// ====================
 
-	// Configurar concorrência
-	numWorkers := runtime.NumCPU()
-	if len(req.ImageURLs) < numWorkers {
-		numWorkers = len(req.ImageURLs)
-	}
-	if numWorkers > 4 {
-		numWorkers = 4 // Máximo 4 workers simultâneos
+	results := make([]DeleteImageResult, len(req.ImageURLs))
+	keys := make(map[string]string, len(req.ImageURLs))
+	tracked := make(map[string]bool, len(req.ImageURLs))
+	var galleryRefs []string
+	for i, imageURL := range req.ImageURLs {
+		results[i].ImageURL = imageURL
+		key, err := productObjectKey(imageURL)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		// Arquivos deduplicados podem estar na galeria de vários produtos: sem product_id
+		// não há como saber de qual tirar
+		isTracked, err := isTrackedObject(key)
+		if err != nil {
+			results[i].Error = fmt.Sprintf("falha ao consultar referências: %v", err)
+			continue
+		}
+		if isTracked && req.ProductID == 0 {
+			results[i].Error = "product_id é obrigatório para imagens compartilhadas entre produtos"
+			continue
+		}
+		keys[imageURL] = key
+		tracked[key] = isTracked
+		// A galeria guarda a key; imagens ainda não migradas guardam a URL completa
+		galleryRefs = append(galleryRefs, key)
+		if imageURL != key {
+			galleryRefs = append(galleryRefs, imageURL)
+		}
 	}
 
-	// Canais para comunicação
-	jobChan := make(chan DeleteImageJob, len(req.ImageURLs))
-	resultChan := make(chan DeleteImageJobResult, len(req.ImageURLs))
+	// ====================
+	// Galerias e referências
+	// ====================
 
-	// Iniciar workers
-	var wg sync.WaitGroup
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go deleteImageWorker(jobChan, resultChan, &wg)
+	// Tira as imagens das galerias (só do produto informado, quando houver product_id;
+	// sem ele só chegam aqui arquivos antigos, sem contagem de referências)
+	removed, err := removeFromGalleries(galleryRefs, req.ProductID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao atualizar a galeria dos produtos",
+			"message": err.Error(),
+		})
 	}
 
-	// Enviar jobs
-	go func() {
-		defer close(jobChan)
-		for i, imageURL := range req.ImageURLs {
-			jobChan <- DeleteImageJob{
-				ImageURL: imageURL,
-				Index:    i,
+	// Cada imagem removida devolve sua referência às versões; o arquivo só sai do bucket
+	// quando nenhum outro produto usa o mesmo conteúdo
+	removedKeys := map[string]bool{}
+	var releaseURLs []string
+	for _, img := range removed {
+		refs := catalog.ImageObjectRefs(img)
+		for _, ref := range refs {
+			if key, err := ObjectKeyFormUrl(ref); err == nil {
+				removedKeys[key] = true
 			}
 		}
-	}()
-
-	// Fechar resultChan quando workers terminarem
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
+		releaseURLs = append(releaseURLs, refs...)
+	}
+	released := map[string]DeleteImageResult{}
+	for _, r := range ReleaseObjects(releaseURLs) {
+		key, err := ObjectKeyFormUrl(r.ImageURL)
+		if err != nil {
+			continue
+		}
+		if prev, ok := released[key]; !ok || prev.Retained {
+			released[key] = r
+		}
+	}
 
-	// Coletar resultados
-	results := make([]DeleteImageResult, len(req.ImageURLs))
-	processedCount := 0
+	for i := range results {
+		imageURL := results[i].ImageURL
+		if results[i].Error != "" {
+			continue
+		}
+		key := keys[imageURL]
+		if removedKeys[key] {
+			results[i] = released[key]
+			results[i].ImageURL = imageURL
+			continue
+		}
 
-	for jobResult := range resultChan {
-		results[jobResult.Index] = jobResult.Result
-		processedCount++
+		// Fora das galerias: arquivos antigos (sem contagem) saem direto; deduplicados só
+		// saem pelas referências (ReleaseObjects), nunca direto
+		if tracked[key] {
+			results[i].Error = "imagem não pertence à galeria do produto informado"
+			continue
+		}
+		results[i] = DeleteObjects([]string{imageURL})[0]
 	}
 
 	// ====================
@@ -411,75 +484,120 @@ func DeleteImagesMinio(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(response)
 }
 
-// Worker para processamento paralelo de deleções
-func deleteImageWorker(jobChan <-chan DeleteImageJob, resultChan chan<- DeleteImageJobResult, wg *sync.WaitGroup) {
-	defer wg.Done()
+// productObjectKey extrai a key de uma imagem de produto (key ou URL), recusando URLs de
+// outro armazenamento ou fora do diretório de produtos.
+func productObjectKey(imageURL string) (string, error) {
+	if imageURL == "" {
+		return "", fmt.Errorf("URL da imagem é obrigatória")
+	}
 
-	for job := range jobChan {
-		result := DeleteImageJobResult{
-			Index: job.Index,
-			Result: DeleteImageResult{
-				ImageURL: job.ImageURL,
-				Success:  false,
-			},
-		}
+	// Validar formato da URL (gerada pelo armazenamento, pela rota de mídia ou, no MinIO,
+	// com o nome do bucket)
+	if isAbsoluteURL(imageURL) && !ownMediaURL(imageURL) {
+		return "", fmt.Errorf("URL inválida: não pertence ao armazenamento de imagens")
+	}
 
-		// Validações de segurança
-		if job.ImageURL == "" {
-			result.Result.Error = "URL da imagem é obrigatória"
-			resultChan <- result
-			continue
-		}
+	key, err := ObjectKeyFormUrl(imageURL)
+	if err != nil {
+		return "", fmt.Errorf("falha ao extrair key: %v", err)
+	}
+	if key == "" {
+		return "", fmt.Errorf("key do objeto não pôde ser extraída da URL")
+	}
 
-		// Validar formato da URL
-		if !strings.Contains(job.ImageURL, config.BunkedName) && !strings.Contains(job.ImageURL, "minio") {
-			result.Result.Error = "URL inválida: não pertence ao domínio MinIO"
-			resultChan <- result
-			continue
-		}
+	// Verificar se pertence ao diretório de produtos
+	if !strings.Contains(key, "products/") {
+		return "", fmt.Errorf("key inválida: não pertence ao diretório de produtos")
+	}
+	return key, nil
+}
 
-		// Extrair key da URL
-		key, err := ObjectKeyFormUrl(job.ImageURL)
-		if err != nil {
-			result.Result.Error = fmt.Sprintf("falha ao extrair key: %v", err)
-			resultChan <- result
-			continue
+// galleryMatches devolve as imagens com a imagem principal ou alguma versão em refs
+// (keys ou URLs antigas).
+func galleryMatches(gallery []schemas.ProductImages, refs []string) []schemas.ProductImages {
+	wanted := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		wanted[ref] = true
+		if key, err := ObjectKeyFormUrl(ref); err == nil {
+			wanted[key] = true
 		}
-
-		// Verificar se a key não está vazia
-		if key == "" {
-			result.Result.Error = "key do objeto não pôde ser extraída da URL"
-			resultChan <- result
-			continue
+	}
+	var matches []schemas.ProductImages
+	for _, img := range gallery {
+		for _, ref := range catalog.ImageObjectRefs(img) {
+			key, _ := ObjectKeyFormUrl(ref)
+			if wanted[ref] || wanted[key] {
+				matches = append(matches, img)
+				break
+			}
 		}
+	}
+	return matches
+}
 
-		// Verificar se pertence ao diretório de produtos
-		if !strings.Contains(key, "products/") {
-			result.Result.Error = "key inválida: não pertence ao diretório de produtos"
-			resultChan <- result
-			continue
+// ownMediaURL indica se a URL aponta para o armazenamento de imagens.
+func ownMediaURL(u string) bool {
+	for _, s := range []storage.Storage{config.Storage, storage.Unwrap(config.Storage)} {
+		if _, ok := storage.KeyFromURL(s, u); ok {
+			return true
 		}
-
-		// Executar deleção com timeout
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-
-		err = config.MinioClient.RemoveObject(
-			ctx,
-			config.BunkedName,
-			key,
-			minio.RemoveObjectOptions{},
-		)
-		cancel() // Cancelar contexto após uso
-
-		if err != nil {
-			result.Result.Error = fmt.Sprintf("falha ao deletar: %v", err)
-			resultChan <- result
-			continue
+	}
+	if config.BunkedName != "" && strings.Contains(u, config.BunkedName) {
+		return true
+	}
+	for _, prefix := range mediaRoutePrefixes {
+		if strings.Contains(u, "/"+prefix) {
+			return true
 		}
+	}
+	return false
+}
 
-		// Sucesso
-		result.Result.Success = true
-		result.Result.Key = key
-		resultChan <- result
+// removeFromGalleries apaga as imagens das galerias (de um produto, ou de todos com
+// productID zero) e regrava Products.Images dos produtos afetados. Devolve as imagens
+// removidas; os arquivos ficam a cargo de quem chama (ReleaseObjects).
+func removeFromGalleries(refs []string, productID uint64) ([]schemas.ProductImages, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	var removed []schemas.ProductImages
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if productID == 0 {
+			if err := tx.Where("url IN ?", refs).Find(&removed).Error; err != nil {
+				return err
+			}
+		} else {
+			// No produto informado a imagem é encontrada por qualquer uma das versões
+			var gallery []schemas.ProductImages
+			if err := tx.Where("product_id = ?", productID).Find(&gallery).Error; err != nil {
+				return err
+			}
+			removed = galleryMatches(gallery, refs)
+		}
+		if len(removed) == 0 {
+			return nil
+		}
+		ids := make([]uint64, 0, len(removed))
+		for _, img := range removed {
+			ids = append(ids, img.ID)
+		}
+		if err := tx.Where("id IN ?", ids).Delete(&schemas.ProductImages{}).Error; err != nil {
+			return err
+		}
+		synced := map[uint64]bool{}
+		for _, img := range removed {
+			if synced[img.ProductID] {
+				continue
+			}
+			synced[img.ProductID] = true
+			if err := catalog.SyncProductImages(tx, img.ProductID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return removed, nil
 }
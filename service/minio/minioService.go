@@ -1,10 +1,14 @@
 package minio
 
 import (
-	"backend_camisaria_store/common"
 	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/catalog"
+	"backend_camisaria_store/service/storage"
+	"bytes"
 	"context"
 	"fmt"
+	"image/jpeg"
 	"mime"
 	"mime/multipart"
 	"net/url"
@@ -12,15 +16,12 @@ import (
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/gofiber/fiber/v2"
-	"github.com/minio/minio-go/v7"
 )
 
+// slugify gera o nome seguro do objeto ("Camisa Social Algodão" → "camisa-social-algodao"),
+// com a mesma regra dos slugs do catálogo.
 func slugify(s string) string {
-	s = strings.ToLower(strings.TrimSpace(s))
-	s = strings.ReplaceAll(s, " ", "-")
-	return s
+	return catalog.Slugify(s)
 }
 
 func UploadProductImage(file *multipart.FileHeader, productName, dir string, productID uint64) (string, string, error) {
@@ -51,94 +52,161 @@ func UploadProductImage(file *multipart.FileHeader, productName, dir string, pro
 		ct = mime.TypeByExtension(ext)
 	}
 
-	_, err = config.MinioClient.PutObject(
-		context.Background(),
-		config.BunkedName,
-		objectName,
-		src,
-		file.Size,
-		minio.PutObjectOptions{ContentType: ct},
-	)
+	err = config.Storage.Put(context.Background(), objectName, src, file.Size, ct)
 
 	if err != nil {
 		return "", "", err
 	}
 
-	publicURL := common.PublicObjectURL(objectName)
+	publicURL := config.Storage.PublicURL(objectName)
 	return publicURL, objectName, nil
 }
 
-func ValidateImageFile(file *multipart.FileHeader) error {
-	// Tipos MIME permitidos
-	allowedTypes := map[string]bool{
-		"image/jpeg": true,
-		"image/jpg":  true,
-		"image/png":  true,
-		"image/webp": true,
-		"image/gif":  true,
+// UploadReencodedImage grava a foto reencodada em JPEG, com a orientação do EXIF aplicada e
+// sem metadados (localização, câmera), limitada a maxSide pixels no lado maior.
+// Usada nas fotos enviadas por clientes, que não passam pela geração de versões dos produtos.
+// Devolve a key do objeto (a URL é montada na resposta com MediaURL).
+func UploadReencodedImage(file *multipart.FileHeader, name, dir string, id uint64, maxSide int) (string, error) {
+	data, err := readValidatedImage(file, false)
+	if err != nil {
+		return "", err
 	}
-
-	// Verificar tipo MIME
-	contentType := file.Header.Get("Content-Type")
-	if !allowedTypes[contentType] {
-		return fiber.NewError(fiber.StatusBadRequest, "Tipo de arquivo não permitido. Use apenas JPEG, PNG, WebP ou GIF")
+	release := acquireDecodeSlot()
+	img, err := decodeImage(data)
+	if err != nil {
+		release()
+		return "", err
 	}
-
-	// Verificar extensão do arquivo
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	allowedExts := map[string]bool{
-		".jpg":  true,
-		".jpeg": true,
-		".png":  true,
-		".webp": true,
-		".gif":  true,
+	var buf bytes.Buffer
+	err = jpeg.Encode(&buf, resizeToFit(img, maxSide), &jpeg.Options{Quality: 85})
+	release()
+	if err != nil {
+		return "", err
 	}
 
-	if !allowedExts[ext] {
-		return fiber.NewError(fiber.StatusBadRequest, "Extensão de arquivo não permitida")
+	if dir != "" && !strings.HasSuffix(dir, "/") {
+		dir += "/"
 	}
-
-	// Verificar tamanho máximo (5MB)
-	const maxSize = 5 * 1024 * 1024 // 5MB
-	if file.Size > maxSize {
-		return fiber.NewError(fiber.StatusBadRequest, "Arquivo muito grande. Tamanho máximo: 5MB")
+	objectName := dir + slugify(name) + "-" + strconv.FormatUint(id, 10) + "-" + strconv.FormatInt(time.Now().UnixNano(), 10) + ".jpg"
+	if err := putObject(objectName, buf.Bytes(), "image/jpeg"); err != nil {
+		return "", err
 	}
+	return objectName, nil
+}
 
-	// Verificar tamanho mínimo (1KB)
-	const minSize = 1024 // 1KB
-	if file.Size < minSize {
-		return fiber.NewError(fiber.StatusBadRequest, "Arquivo muito pequeno. Tamanho mínimo: 1KB")
-	}
+// Limites de tamanho das imagens enviadas (multipart ou URL pré-assinada)
+const (
+	maxImageSize = 5 * 1024 * 1024 // 5MB
+	minImageSize = 1024            // 1KB
+)
 
-	return nil
+// ValidateImageFile confere a imagem pelo conteúdo (assinatura e decodificação completa),
+// não pelo Content-Type ou extensão enviados. Recusas vêm como *ImageRejection.
+func ValidateImageFile(file *multipart.FileHeader) error {
+	_, err := readValidatedImage(file, true)
+	return err
 }
 
+// Rotas da API que servem o bucket (ServeMedia); URLs por elas, com outra base pública,
+// também apontam para objetos do armazenamento.
+var mediaRoutePrefixes = []string{"public/media/", "media/"}
+
+// ObjectKeyFormUrl devolve a key do objeto de uma referência gravada no banco: a própria key
+// ou uma URL completa (imagens gravadas antes das keys, pelo armazenamento ou pela rota de mídia).
 func ObjectKeyFormUrl(u string) (string, error) {
 	if u == "" {
 		return "", fmt.Errorf("campo vazio")
 	}
-
-	parsed, err := url.ParseRequestURI(u)
-	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+	if !isAbsoluteURL(u) {
 		return strings.TrimLeft(u, "/"), nil
 	}
 
-	path := strings.TrimLeft(parsed.Path, "/")
+	// URLs geradas pelo armazenamento configurado ou pelo backend sem MEDIA_PUBLIC_URL
+	for _, s := range []storage.Storage{config.Storage, storage.Unwrap(config.Storage)} {
+		if key, ok := storage.KeyFromURL(s, u); ok {
+			return key, nil
+		}
+	}
 
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Host == "" {
+		return "", fmt.Errorf("URL inválida")
+	}
+	path := strings.TrimLeft(parsed.Path, "/")
 	if b := strings.TrimSpace(config.BunkedName); b != "" {
 		path = strings.TrimPrefix(path, b+"/")
 	}
+	for _, prefix := range mediaRoutePrefixes {
+		path = strings.TrimPrefix(path, prefix)
+	}
+	if path == "" {
+		return "", fmt.Errorf("não foi possivel extrair a key do objeto")
+	}
+	return path, nil
+}
 
-	key, dacerr := url.PathUnescape(path)
+// MediaURL monta a URL pública de uma imagem gravada pela key; URLs completas (imagens
+// externas ou ainda não migradas) voltam como estão.
+func MediaURL(ref string) string {
+	if ref == "" || isAbsoluteURL(ref) {
+		return ref
+	}
+	return config.Storage.PublicURL(ref)
+}
 
-	if dacerr != nil {
-		key = path
+// MediaURLs aplica MediaURL a cada referência.
+func MediaURLs(refs []string) []string {
+	urls := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		urls = append(urls, MediaURL(ref))
 	}
+	return urls
+}
 
-	if key == "nil" {
-		return "", fmt.Errorf("não foi possivel extrair a key do objeto")
+// RenditionURLs devolve uma cópia das versões com as URLs públicas no lugar das keys.
+func RenditionURLs(renditions map[string]schemas.ImageRendition) map[string]schemas.ImageRendition {
+	return mapRenditions(renditions, MediaURL)
+}
+
+func mapRenditions(renditions map[string]schemas.ImageRendition, fn func(string) string) map[string]schemas.ImageRendition {
+	if renditions == nil {
+		return nil
+	}
+	mapped := make(map[string]schemas.ImageRendition, len(renditions))
+	for name, r := range renditions {
+		r.JPEG, r.WebP = fn(r.JPEG), fn(r.WebP)
+		mapped[name] = r
 	}
+	return mapped
+}
 
-	return key, nil
+func isAbsoluteURL(s string) bool {
+	return strings.Contains(s, "://") || strings.HasPrefix(s, "//")
+}
 
+// DeleteObjects remove do bucket os objetos das URLs informadas (ex.: imagens de um
+// produto excluído definitivamente). Cada URL tem seu resultado; falhas não interrompem as demais.
+func DeleteObjects(urls []string) []DeleteImageResult {
+	results := make([]DeleteImageResult, 0, len(urls))
+	for _, u := range urls {
+		result := DeleteImageResult{ImageURL: u}
+		key, err := ObjectKeyFormUrl(u)
+		if err != nil || key == "" {
+			result.Error = "key do objeto não pôde ser extraída da URL"
+			results = append(results, result)
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err = config.Storage.Delete(ctx, key)
+		cancel()
+		if err != nil {
+			result.Error = fmt.Sprintf("falha ao deletar: %v", err)
+		} else {
+			result.Success = true
+			result.Key = key
+		}
+		results = append(results, result)
+	}
+	return results
 }
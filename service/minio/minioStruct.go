@@ -1,8 +1,14 @@
 package minio
 
+import (
+	"errors"
+	"strings"
+)
+
 // Estrutura para request de deleção múltipla
 type DeleteImagesRequest struct {
 	ImageURLs []string `json:"image_urls"`
+	ProductID uint64   `json:"product_id,omitempty"` // remove só da galeria deste produto (imagens compartilhadas)
 }
 
 // Estrutura para resultado de deleção
@@ -11,16 +17,43 @@ type DeleteImageResult struct {
 	Success  bool   `json:"success"`
 	Error    string `json:"error,omitempty"`
 	Key      string `json:"key,omitempty"`
+	Retained bool   `json:"retained,omitempty"` // referência liberada; arquivo mantido por estar em uso
+}
+
+// PresignUploadRequest pede uma URL para enviar uma imagem direto ao bucket.
+type PresignUploadRequest struct {
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"` // tamanho declarado; conferido na confirmação
+	AltText     string `json:"alt_text,omitempty"`
+	Color       string `json:"color,omitempty"`
 }
 
-// Job para deleção de imagem
-type DeleteImageJob struct {
-	ImageURL string
-	Index    int
+// ConfirmUploadRequest confirma o envio feito pela URL pré-assinada.
+type ConfirmUploadRequest struct {
+	UploadID uint64 `json:"upload_id"`
 }
 
-// Resultado do job de deleção
-type DeleteImageJobResult struct {
-	Result DeleteImageResult
-	Index  int
+func (req *PresignUploadRequest) Validate() error {
+	var errs []string
+	req.ContentType = strings.ToLower(strings.TrimSpace(req.ContentType))
+	req.AltText = strings.TrimSpace(req.AltText)
+	req.Color = strings.TrimSpace(req.Color)
+
+	if _, ok := presignContentTypes[req.ContentType]; !ok {
+		errs = append(errs, "content_type deve ser image/jpeg, image/png, image/webp ou image/gif")
+	}
+	if req.Size < minImageSize || req.Size > maxImageSize {
+		errs = append(errs, "size deve estar entre 1KB e 5MB")
+	}
+	if len(req.AltText) > 255 {
+		errs = append(errs, "alt_text deve ter no máximo 255 caracteres")
+	}
+	if len(req.Color) > 50 {
+		errs = append(errs, "color deve ter no máximo 50 caracteres")
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
 }
@@ -0,0 +1,346 @@
+package minio
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/catalog"
+	"backend_camisaria_store/service/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	presignExpiry = 15 * time.Minute
+	// Prazo extra para confirmar depois que a URL venceu (envio iniciado no limite)
+	confirmGrace = time.Hour
+)
+
+// Tipos aceitos no envio direto e a extensão do objeto temporário.
+var presignContentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+	"image/gif":  ".gif",
+}
+
+// PresignProductImage emite um formulário POST pré-assinado para enviar uma imagem do produto
+// direto ao MinIO, sem passar os bytes pela API. A política assina o Content-Type declarado
+// e a faixa de tamanho aceita. Depois do envio o cliente chama ConfirmProductImage com o upload_id.
+func PresignProductImage(c *fiber.Ctx) error {
+	product, ferr := findUploadProduct(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+	userID, _ := c.Locals("user_id").(uint64)
+
+	var req PresignUploadRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Erro ao processar dados da requisição",
+			"details": err.Error(),
+		})
+	}
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Dados inválidos",
+			"details": err.Error(),
+		})
+	}
+
+	// Envios pendentes ainda válidos também ocupam vaga na galeria
+	now := time.Now()
+	var images, pending int64
+	if err := config.DB.Model(&schemas.ProductImages{}).Where("product_id = ?", product.ID).Count(&images).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao consultar imagens do produto"})
+	}
+	if err := config.DB.Model(&schemas.PendingUploads{}).Where("product_id = ? AND expires_at > ?", product.ID, now).
+		Count(&pending).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao consultar envios pendentes"})
+	}
+	if images+pending >= catalog.MaxProductImages {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":          "Limite de imagens excedido",
+			"message":        "O produto já tem o número máximo de imagens (incluindo envios pendentes)",
+			"current_images": images,
+			"pending":        pending,
+		})
+	}
+
+	suffix, err := randomHex(8)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao gerar nome do arquivo"})
+	}
+	objectKey := fmt.Sprintf("%sproducts/%d/%d-%s%s", pendingUploadsPrefix, product.ID, now.UnixNano(), suffix, presignContentTypes[req.ContentType])
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	form, err := config.Storage.PresignPost(ctx, objectKey, req.ContentType, minImageSize, maxImageSize, presignExpiry)
+	if errors.Is(err, storage.ErrPresignUnsupported) {
+		return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{
+			"error":   "Envio direto indisponível",
+			"message": "O armazenamento configurado não aceita envio direto; use o upload multipart",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao gerar URL de envio",
+			"details": err.Error(),
+		})
+	}
+
+	upload := schemas.PendingUploads{
+		ProductID:   product.ID,
+		UserID:      userID,
+		ObjectKey:   objectKey,
+		ContentType: req.ContentType,
+		Size:        req.Size,
+		AltText:     req.AltText,
+		Color:       req.Color,
+		ExpiresAt:   now.Add(presignExpiry),
+	}
+	if err := config.DB.Create(&upload).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao registrar envio",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"upload_id":  upload.ID,
+		"upload_url": form.URL,
+		"method":     fiber.MethodPost,
+		"fields":     form.Fields, // enviados antes do arquivo (campo "file")
+		"object_key": objectKey,
+		"expires_at": upload.ExpiresAt,
+		"max_size":   maxImageSize,
+	})
+}
+
+// ConfirmProductImage confere o objeto enviado (existência, tamanho e tipo), gera as
+// versões redimensionadas e anexa a imagem à galeria. O arquivo temporário é apagado.
+func ConfirmProductImage(c *fiber.Ctx) error {
+	product, ferr := findUploadProduct(c)
+	if ferr != nil {
+		return c.Status(ferr.Code).JSON(fiber.Map{"error": ferr.Message})
+	}
+	userID, _ := c.Locals("user_id").(uint64)
+
+	var req ConfirmUploadRequest
+	if err := c.BodyParser(&req); err != nil || req.UploadID == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "upload_id é obrigatório"})
+	}
+
+	var upload schemas.PendingUploads
+	err := config.DB.Where("id = ? AND product_id = ? AND user_id = ?", req.UploadID, product.ID, userID).First(&upload).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Envio não encontrado"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao buscar envio"})
+	}
+	if time.Now().After(upload.ExpiresAt.Add(confirmGrace)) {
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": "Envio expirado; solicite uma nova URL"})
+	}
+
+	// Só uma confirmação processa o envio; as concorrentes recebem 409
+	claim := config.DB.Model(&schemas.PendingUploads{}).
+		Where("id = ? AND confirmed_at IS NULL", upload.ID).
+		Update("confirmed_at", time.Now())
+	if claim.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao confirmar envio"})
+	}
+	if claim.RowsAffected == 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Envio já está sendo confirmado"})
+	}
+
+	var images int64
+	if err := config.DB.Model(&schemas.ProductImages{}).Where("product_id = ?", product.ID).Count(&images).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Erro ao consultar imagens do produto"})
+	}
+	if images >= catalog.MaxProductImages {
+		releaseUploadClaim(upload)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Limite de imagens excedido",
+			"message": "Este produto já possui o número máximo de imagens permitidas",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	info, err := config.Storage.Stat(ctx, upload.ObjectKey)
+	if err != nil {
+		releaseUploadClaim(upload)
+		if errors.Is(err, storage.ErrNotFound) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Arquivo não encontrado no armazenamento; envie pela URL antes de confirmar",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao consultar arquivo enviado",
+			"details": err.Error(),
+		})
+	}
+
+	processed, err := processUploadedObject(ctx, upload, info)
+	if err != nil {
+		if reason := RejectionReason(err); reason != "" {
+			// Envio recusado não volta a ser aceito: libera a vaga e o arquivo
+			discardPendingUpload(upload)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Arquivo enviado inválido",
+				"reason":  reason,
+				"details": err.Error(),
+			})
+		}
+		releaseUploadClaim(upload)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao processar arquivo enviado",
+			"details": err.Error(),
+		})
+	}
+
+	return attachConfirmedImage(c, upload, processed, int(images)+1)
+}
+
+// processUploadedObject baixa e valida o objeto temporário (o tipo detectado precisa ser o
+// declarado no pedido da URL) e gera as versões redimensionadas.
+func processUploadedObject(ctx context.Context, upload schemas.PendingUploads, info storage.ObjectInfo) (*ProcessedImage, error) {
+	data, err := readUploadedObject(ctx, upload, info)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := validateImageBytes(data, presignContentTypes[upload.ContentType], false); err != nil {
+		return nil, err
+	}
+	return uploadRenditions(data)
+}
+
+// attachConfirmedImage cria a imagem na galeria e descarta o envio pendente e o arquivo temporário.
+func attachConfirmedImage(c *fiber.Ctx, upload schemas.PendingUploads, processed *ProcessedImage, position int) error {
+	image := schemas.ProductImages{
+		ProductID:  upload.ProductID,
+		URL:        processed.ObjectName,
+		ObjectKey:  processed.ObjectName,
+		Position:   position,
+		AltText:    upload.AltText,
+		Color:      upload.Color,
+		Renditions: processed.Renditions,
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&image).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&upload).Error; err != nil {
+			return err
+		}
+		return catalog.SyncProductImages(tx, upload.ProductID)
+	})
+	if err != nil {
+		ReleaseObjects(catalog.ImageObjectRefs(image))
+		releaseUploadClaim(upload)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao anexar imagem ao produto",
+			"details": err.Error(),
+		})
+	}
+	removeObjects([]string{upload.ObjectKey})
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Imagem enviada com sucesso",
+		"image": fiber.Map{
+			"id":         image.ID,
+			"url":        MediaURL(image.URL),
+			"object_key": image.ObjectKey,
+			"renditions": RenditionURLs(image.Renditions),
+		},
+	})
+}
+
+// CleanupPendingUploads apaga os envios não confirmados no prazo e seus arquivos temporários.
+func CleanupPendingUploads(db *gorm.DB) (int, error) {
+	var expired []schemas.PendingUploads
+	if err := db.Where("expires_at < ?", time.Now().Add(-confirmGrace)).Limit(500).Find(&expired).Error; err != nil {
+		return 0, err
+	}
+	for _, upload := range expired {
+		discardPendingUpload(upload)
+	}
+	return len(expired), nil
+}
+
+// readUploadedObject baixa o objeto temporário conferindo tamanho declarado, limites e
+// o tipo real pelos primeiros bytes.
+func readUploadedObject(ctx context.Context, upload schemas.PendingUploads, info storage.ObjectInfo) ([]byte, error) {
+	if info.Size > maxImageSize {
+		return nil, reject(RejectFileTooLarge, "Arquivo muito grande. Tamanho máximo: 5MB")
+	}
+	if info.Size < minImageSize {
+		return nil, reject(RejectFileTooSmall, "Arquivo muito pequeno. Tamanho mínimo: 1KB")
+	}
+	if info.Size != upload.Size {
+		return nil, reject(RejectSizeMismatch,
+			fmt.Sprintf("Tamanho enviado (%d bytes) difere do declarado (%d bytes)", info.Size, upload.Size))
+	}
+
+	obj, err := config.Storage.Get(ctx, upload.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+	data, err := io.ReadAll(io.LimitReader(obj, maxImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// releaseUploadClaim libera o envio para nova tentativa de confirmação após uma falha.
+func releaseUploadClaim(upload schemas.PendingUploads) {
+	config.DB.Model(&schemas.PendingUploads{}).Where("id = ?", upload.ID).Update("confirmed_at", nil)
+}
+
+// discardPendingUpload remove o arquivo temporário e o registro do envio.
+func discardPendingUpload(upload schemas.PendingUploads) {
+	removeObjects([]string{upload.ObjectKey})
+	config.DB.Delete(&upload)
+}
+
+// findUploadProduct carrega o produto do path para envio de imagens (ativo e fora da lixeira).
+func findUploadProduct(c *fiber.Ctx) (*schemas.Products, *fiber.Error) {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil || productID == 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "ID do produto inválido")
+	}
+
+	var product schemas.Products
+	err = config.DB.First(&product, productID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Produto não encontrado")
+	}
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Erro ao buscar produto")
+	}
+	if !product.IsActive {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Não é possível enviar imagens para produtos inativos")
+	}
+	return &product, nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
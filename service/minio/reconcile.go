@@ -0,0 +1,261 @@
+package minio
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	productObjectsPrefix = "products/"
+	// Objetos recentes podem ser de um upload ainda em andamento; só viram órfãos depois disso
+	orphanGracePeriod = 24 * time.Hour
+	// Limite de objetos listados no relatório (as contagens são sempre completas)
+	maxReportedObjects = 500
+)
+
+// ReconcileOptions controla uma execução da reconciliação do bucket.
+type ReconcileOptions struct {
+	DryRun bool
+	Grace  time.Duration
+	Now    time.Time
+}
+
+// OrphanObject é um arquivo em products/ que nenhum produto referencia.
+type OrphanObject struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+	Deleted      bool      `json:"deleted"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// MissingObject é uma imagem referenciada pelo produto que não existe no bucket.
+type MissingObject struct {
+	ProductID uint64 `json:"product_id"`
+	Key       string `json:"key"`
+	URL       string `json:"url"`
+}
+
+// ReconcileReport resume a comparação entre o bucket e as imagens dos produtos.
+type ReconcileReport struct {
+	DryRun         bool            `json:"dry_run"`
+	GraceHours     float64         `json:"grace_hours"`
+	Scanned        int             `json:"scanned"`
+	Referenced     int             `json:"referenced"`
+	OrphanCount    int             `json:"orphan_count"`
+	RecentOrphans  int             `json:"recent_orphans"` // dentro da carência, mantidos
+	DeletedCount   int             `json:"deleted_count"`
+	FailedCount    int             `json:"failed_count"` // órfãos com erro na remoção
+	BytesReclaimed int64           `json:"bytes_reclaimed"`
+	MissingCount   int             `json:"missing_count"`
+	UnmappedCount  int             `json:"unmapped_count"` // referências sem key reconhecível
+	Aborted        bool            `json:"aborted"`        // remoção cancelada por UnmappedCount
+	Orphans        []OrphanObject  `json:"orphans"`
+	Missing        []MissingObject `json:"missing"`
+	Unmapped       []string        `json:"unmapped"`
+}
+
+// ReconcileProductObjects compara os objetos em products/ com as keys referenciadas em
+// Products.Images (inclusive produtos na lixeira) e na galeria com as versões geradas.
+// Órfãos mais antigos que a carência são apagados, exceto em DryRun. Objetos com referência
+// em stored_objects nunca são órfãos, e a remoção é cancelada (Aborted) quando alguma
+// referência não pôde ser convertida em key: o conjunto de referenciados estaria incompleto.
+func ReconcileProductObjects(db *gorm.DB, opts ReconcileOptions) (*ReconcileReport, error) {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.Grace <= 0 {
+		opts.Grace = orphanGracePeriod
+	}
+
+	referenced, unmapped, err := referencedProductObjects(db)
+	if err != nil {
+		return nil, err
+	}
+	var tracked []string
+	if err := db.Model(&schemas.StoredObjects{}).Where("ref_count > 0").
+		Pluck("object_key", &tracked).Error; err != nil {
+		return nil, err
+	}
+	retained := make(map[string]bool, len(tracked))
+	for _, key := range tracked {
+		retained[key] = true
+	}
+
+	report := &ReconcileReport{
+		DryRun:        opts.DryRun,
+		GraceHours:    opts.Grace.Hours(),
+		Referenced:    len(referenced),
+		UnmappedCount: len(unmapped),
+		Orphans:       []OrphanObject{},
+		Missing:       []MissingObject{},
+		Unmapped:      []string{},
+	}
+	if len(unmapped) > maxReportedObjects {
+		report.Unmapped = append(report.Unmapped, unmapped[:maxReportedObjects]...)
+	} else {
+		report.Unmapped = append(report.Unmapped, unmapped...)
+	}
+	if len(unmapped) > 0 && !opts.DryRun {
+		report.Aborted = true
+		opts.DryRun = true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	present := make(map[string]bool)
+	cutoff := opts.Now.Add(-opts.Grace)
+	err = config.Storage.List(ctx, productObjectsPrefix, func(obj storage.ObjectInfo) error {
+		report.Scanned++
+		present[obj.Key] = true
+		if _, ok := referenced[obj.Key]; ok || retained[obj.Key] {
+			return nil
+		}
+
+		report.OrphanCount++
+		if obj.LastModified.After(cutoff) {
+			report.RecentOrphans++
+			return nil
+		}
+
+		orphan := OrphanObject{Key: obj.Key, Size: obj.Size, LastModified: obj.LastModified}
+		if !opts.DryRun {
+			if err := config.Storage.Delete(ctx, obj.Key); err != nil {
+				orphan.Error = err.Error()
+				report.FailedCount++
+			} else if err := db.Where("object_key = ?", obj.Key).Delete(&schemas.StoredObjects{}).Error; err != nil {
+				// Sem o registro de deduplicação um novo envio do mesmo arquivo regrava o objeto;
+				// registro que ficou para trás aponta para um arquivo que não existe mais
+				orphan.Error = "arquivo removido, mas o registro de deduplicação não: " + err.Error()
+				report.FailedCount++
+			} else {
+				orphan.Deleted = true
+				report.DeletedCount++
+				report.BytesReclaimed += obj.Size
+			}
+		}
+		if len(report.Orphans) < maxReportedObjects {
+			report.Orphans = append(report.Orphans, orphan)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for key, ref := range referenced {
+		if present[key] {
+			continue
+		}
+		report.MissingCount++
+		if len(report.Missing) < maxReportedObjects {
+			report.Missing = append(report.Missing, MissingObject{ProductID: ref.productID, Key: key, URL: MediaURL(ref.url)})
+		}
+	}
+	return report, nil
+}
+
+type objectRef struct {
+	productID uint64
+	url       string
+}
+
+// referencedProductObjects monta o conjunto de keys em products/ usadas por algum produto.
+// unmapped lista as referências que não viraram key (erro ou caminho de products/ com
+// prefixo desconhecido); imagens externas são ignoradas.
+func referencedProductObjects(db *gorm.DB) (refs map[string]objectRef, unmapped []string, err error) {
+	refs = make(map[string]objectRef)
+	add := func(productID uint64, u string) {
+		if u == "" {
+			return
+		}
+		key, err := ObjectKeyFormUrl(u)
+		if err != nil || (!strings.HasPrefix(key, productObjectsPrefix) && strings.Contains(key, productObjectsPrefix)) {
+			unmapped = append(unmapped, u)
+			return
+		}
+		if !strings.HasPrefix(key, productObjectsPrefix) {
+			return
+		}
+		if _, ok := refs[key]; !ok {
+			refs[key] = objectRef{productID: productID, url: u}
+		}
+	}
+
+	var products []schemas.Products
+	err = db.Unscoped().Select("id", "images").Where("images IS NOT NULL").
+		FindInBatches(&products, 500, func(tx *gorm.DB, batch int) error {
+			for _, p := range products {
+				for _, u := range JsonToStringSlice(p.Images) {
+					add(p.ID, u)
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var images []schemas.ProductImages
+	err = db.FindInBatches(&images, 500, func(tx *gorm.DB, batch int) error {
+		for _, img := range images {
+			add(img.ProductID, img.URL)
+			for _, r := range img.Renditions {
+				add(img.ProductID, r.JPEG)
+				add(img.ProductID, r.WebP)
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	return refs, unmapped, nil
+}
+
+// StorageReconcileReport — relatório dos órfãos e arquivos ausentes sem apagar nada (dry run).
+// Aceita ?grace_hours= para simular outra carência.
+func StorageReconcileReport(c *fiber.Ctx) error {
+	return respondReconcile(c, true)
+}
+
+// CleanupStorageOrphans apaga os órfãos mais antigos que a carência e devolve o relatório;
+// 409 quando a remoção foi cancelada por referências sem key.
+func CleanupStorageOrphans(c *fiber.Ctx) error {
+	return respondReconcile(c, false)
+}
+
+func respondReconcile(c *fiber.Ctx, dryRun bool) error {
+	grace := orphanGracePeriod
+	if raw := c.Query("grace_hours"); raw != "" {
+		hours, err := strconv.Atoi(raw)
+		if err != nil || hours < 1 || hours > 24*90 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "grace_hours deve ser um número entre 1 e 2160",
+			})
+		}
+		grace = time.Duration(hours) * time.Hour
+	}
+
+	report, err := ReconcileProductObjects(config.DB, ReconcileOptions{DryRun: dryRun, Grace: grace})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Erro ao reconciliar o armazenamento",
+			"details": err.Error(),
+		})
+	}
+	if report.Aborted {
+		return c.Status(fiber.StatusConflict).JSON(report)
+	}
+	return c.Status(fiber.StatusOK).JSON(report)
+}
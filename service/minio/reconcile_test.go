@@ -0,0 +1,129 @@
+package minio
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"backend_camisaria_store/config"
+	"backend_camisaria_store/schemas"
+)
+
+func TestReconcileProductObjects(t *testing.T) {
+	const (
+		galleryKey  = "products/galeria-zoom.jpg"
+		legacyKey   = "products/antiga.jpg"
+		trackedKey  = "products/compartilhada-card.jpg"
+		orphanKey   = "products/orfa.jpg"
+		categoryKey = "categories/banner.jpg"
+	)
+
+	tests := []struct {
+		name           string
+		dryRun         bool
+		legacyRef      string // referência em Products.Images
+		wantOrphans    int
+		wantDeleted    int
+		wantUnmapped   int
+		wantAborted    bool
+		wantOrphanGone bool
+	}{
+		{"dry run só relata", true, testStorageURL + "/" + legacyKey, 1, 0, 0, false, false},
+		{"remoção apaga o órfão", false, testStorageURL + "/" + legacyKey, 1, 1, 0, false, true},
+		{"url da rota de mídia em outra base", false, "https://loja.example.com/public/media/" + legacyKey, 1, 1, 0, false, true},
+		{"referência sem key cancela a remoção", false, "https://loja.example.com/cdn/" + legacyKey, 2, 0, 1, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory := setupTestEnv(t)
+			ctx := context.Background()
+			for _, key := range []string{galleryKey, legacyKey, trackedKey, orphanKey, categoryKey} {
+				if err := memory.Put(ctx, key, bytes.NewReader([]byte("x")), 1, "image/jpeg"); err != nil {
+					t.Fatalf("gravar %s: %v", key, err)
+				}
+			}
+			if err := config.DB.Create(&schemas.ProductImages{ProductID: 1, URL: galleryKey, ObjectKey: galleryKey}).Error; err != nil {
+				t.Fatalf("criar imagem: %v", err)
+			}
+			if err := config.DB.Exec("INSERT INTO products (id, images) VALUES (?, ?)",
+				2, `["`+tt.legacyRef+`"]`).Error; err != nil {
+				t.Fatalf("criar produto: %v", err)
+			}
+			// Referência contada em stored_objects sem galeria (ex.: envio em andamento)
+			if err := config.DB.Create(&schemas.StoredObjects{
+				ObjectKey: trackedKey, Hash: "h", Variant: RenditionCard, Format: "jpeg", RefCount: 1,
+			}).Error; err != nil {
+				t.Fatalf("criar stored_object: %v", err)
+			}
+
+			report, err := ReconcileProductObjects(config.DB, ReconcileOptions{
+				DryRun: tt.dryRun,
+				Now:    time.Now().Add(48 * time.Hour), // todos os objetos fora da carência
+			})
+			if err != nil {
+				t.Fatalf("reconciliar: %v", err)
+			}
+			if report.OrphanCount != tt.wantOrphans || report.DeletedCount != tt.wantDeleted ||
+				report.UnmappedCount != tt.wantUnmapped || report.Aborted != tt.wantAborted {
+				t.Errorf("relatório = órfãos %d, apagados %d, sem key %d, cancelado %v; want %d, %d, %d, %v",
+					report.OrphanCount, report.DeletedCount, report.UnmappedCount, report.Aborted,
+					tt.wantOrphans, tt.wantDeleted, tt.wantUnmapped, tt.wantAborted)
+			}
+			if report.MissingCount != 0 {
+				t.Errorf("ausentes = %d, want 0", report.MissingCount)
+			}
+
+			for _, key := range []string{galleryKey, trackedKey, categoryKey} {
+				if _, err := memory.Stat(ctx, key); err != nil {
+					t.Errorf("objeto referenciado %s apagado", key)
+				}
+			}
+			if _, err := memory.Stat(ctx, orphanKey); (err != nil) != tt.wantOrphanGone {
+				t.Errorf("órfão apagado = %v, want %v", err != nil, tt.wantOrphanGone)
+			}
+		})
+	}
+}
+
+func TestObjectKeyFormUrl(t *testing.T) {
+	setupTestEnv(t)
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{"products/abc-zoom.jpg", "products/abc-zoom.jpg", false},
+		{"/products/abc-zoom.jpg", "products/abc-zoom.jpg", false},
+		{testStorageURL + "/products/abc%20azul.jpg", "products/abc azul.jpg", false},
+		{"https://api.example.com/public/media/products/abc.webp", "products/abc.webp", false},
+		{"https://api.example.com/media/products/abc.webp", "products/abc.webp", false},
+		{"https://api.example.com/", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ObjectKeyFormUrl(tt.ref)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ObjectKeyFormUrl(%q) = (%q, %v), want (%q, erro %v)", tt.ref, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestMediaURL(t *testing.T) {
+	setupTestEnv(t)
+
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"products/abc-zoom.jpg", testStorageURL + "/products/abc-zoom.jpg"},
+		{"https://cdn.example.com/externa.jpg", "https://cdn.example.com/externa.jpg"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := MediaURL(tt.ref); got != tt.want {
+			t.Errorf("MediaURL(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// emailConfigured indica se o SMTP tem o mínimo para enviar (SMTP_HOST e SMTP_FROM).
+func emailConfigured() bool {
+	return strings.TrimSpace(os.Getenv("SMTP_HOST")) != "" && strings.TrimSpace(os.Getenv("SMTP_FROM")) != ""
+}
+
+// SendEmail envia um e-mail de texto simples pelo servidor SMTP configurado
+// (SMTP_HOST, SMTP_PORT, SMTP_USER, SMTP_PASSWORD, SMTP_FROM).
+func SendEmail(to, subject, body string) error {
+	host := strings.TrimSpace(os.Getenv("SMTP_HOST"))
+	from := strings.TrimSpace(os.Getenv("SMTP_FROM"))
+	if host == "" || from == "" {
+		return ErrNotConfigured
+	}
+	port := strings.TrimSpace(os.Getenv("SMTP_PORT"))
+	if port == "" {
+		port = "587"
+	}
+	to = strings.TrimSpace(to)
+	if to == "" || strings.ContainsAny(to, "\r\n") {
+		return fmt.Errorf("destinatário inválido: %q", to)
+	}
+
+	var auth smtp.Auth
+	if user := os.Getenv("SMTP_USER"); user != "" {
+		auth = smtp.PlainAuth("", user, os.Getenv("SMTP_PASSWORD"), host)
+	}
+
+	msg := strings.Join([]string{
+		"From: " + from,
+		"To: " + to,
+		"Subject: " + strings.NewReplacer("\r", "", "\n", " ").Replace(subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+
+	return smtp.SendMail(net.JoinHostPort(host, port), auth, from, []string{to}, []byte(msg))
+}
@@ -0,0 +1,213 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"backend_camisaria_store/schemas"
+	"backend_camisaria_store/service/catalog"
+
+	"gorm.io/gorm"
+)
+
+// MaxAlertAttempts é o número de envios tentados antes de marcar a inscrição como falha.
+const MaxAlertAttempts = 5
+
+// errOptedOut indica que o usuário desativou o canal depois de se inscrever.
+var errOptedOut = errors.New("usuário desativou avisos neste canal")
+
+// alertBatchSize limita quantas inscrições são processadas por ciclo do job.
+const alertBatchSize = 200
+
+// StockAlertResult resume um ciclo de ProcessStockAlerts.
+type StockAlertResult struct {
+	Notified int
+	Failed   int
+	Retrying int
+}
+
+// ProcessStockAlerts avisa os inscritos de produtos que voltaram ao estoque.
+// Kits usam o estoque calculado pelos componentes. O opt-in do canal é conferido
+// no envio: quem desativou o aviso depois de se inscrever não recebe a mensagem.
+//
+// O lote só traz inscrições de produtos visíveis com estoque (ou kits) em canais
+// configurados, para que as pendentes sem estoque não ocupem o lote de todo ciclo.
+func ProcessStockAlerts(db *gorm.DB) (StockAlertResult, error) {
+	result := StockAlertResult{}
+
+	channels, err := configuredChannels(db)
+	if err != nil {
+		return result, err
+	}
+	if len(channels) == 0 {
+		return result, nil
+	}
+
+	available := db.Model(&schemas.Products{}).Select("id").
+		Scopes(catalog.VisibleAt(time.Now())).
+		Where("stock_quantity > 0 OR is_bundle = ?", true)
+
+	var subs []schemas.StockSubscriptions
+	if err := db.Where("status = ? AND channel IN ?", schemas.AlertPending, channels).
+		Where("product_id IN (?)", available).
+		Order("id ASC").Limit(alertBatchSize).Find(&subs).Error; err != nil {
+		return result, err
+	}
+	if len(subs) == 0 {
+		return result, nil
+	}
+
+	productIDs := make([]uint64, 0, len(subs))
+	userIDs := make([]uint64, 0, len(subs))
+	for _, s := range subs {
+		productIDs = append(productIDs, s.ProductID)
+		userIDs = append(userIDs, s.UserID)
+	}
+
+	var products []schemas.Products
+	if err := db.Scopes(catalog.VisibleAt(time.Now())).Where("id IN ?", productIDs).Find(&products).Error; err != nil {
+		return result, err
+	}
+	if err := catalog.ApplyBundleStock(db, products); err != nil {
+		return result, err
+	}
+	inStock := make(map[uint64]schemas.Products, len(products))
+	for _, p := range products {
+		if p.StockQuantity > 0 {
+			inStock[p.ID] = p
+		}
+	}
+	if len(inStock) == 0 {
+		return result, nil
+	}
+
+	var users []schemas.Users
+	if err := db.Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return result, err
+	}
+	usersByID := make(map[uint64]schemas.Users, len(users))
+	for _, u := range users {
+		usersByID[u.ID] = u
+	}
+
+	for _, sub := range subs {
+		product, ok := inStock[sub.ProductID]
+		if !ok {
+			continue
+		}
+		user, ok := usersByID[sub.UserID]
+		if !ok {
+			continue
+		}
+
+		sendErr := sendStockAlert(db, sub.Channel, user, product)
+		if sendErr == nil {
+			now := time.Now()
+			if err := db.Model(&sub).Updates(map[string]interface{}{
+				"status":      schemas.AlertNotified,
+				"attempts":    sub.Attempts + 1,
+				"last_error":  "",
+				"notified_at": now,
+			}).Error; err != nil {
+				return result, err
+			}
+			result.Notified++
+			continue
+		}
+		// Configuração removida no meio do ciclo: não consome tentativa; o aviso sai quando
+		// o canal voltar a ser configurado
+		if errors.Is(sendErr, ErrNotConfigured) {
+			continue
+		}
+
+		attempts := sub.Attempts + 1
+		updates := map[string]interface{}{
+			"attempts":   attempts,
+			"last_error": truncate(sendErr.Error(), 255),
+		}
+		if attempts >= MaxAlertAttempts || errors.Is(sendErr, errOptedOut) {
+			updates["status"] = schemas.AlertFailed
+			result.Failed++
+		} else {
+			result.Retrying++
+		}
+		if err := db.Model(&sub).Updates(updates).Error; err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// configuredChannels lista os canais em condições de enviar; inscrições dos demais
+// continuam pendentes até o canal ser configurado.
+func configuredChannels(db *gorm.DB) ([]schemas.AlertChannel, error) {
+	var channels []schemas.AlertChannel
+	ok, err := whatsAppConfigured(db)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		channels = append(channels, schemas.AlertWhatsApp)
+	}
+	if emailConfigured() {
+		channels = append(channels, schemas.AlertEmail)
+	}
+	return channels, nil
+}
+
+func sendStockAlert(db *gorm.DB, channel schemas.AlertChannel, user schemas.Users, product schemas.Products) error {
+	text := stockAlertText(user, product)
+	switch channel {
+	case schemas.AlertWhatsApp:
+		if !user.NotifyWhatsApp {
+			return errOptedOut
+		}
+		return SendWhatsApp(db, user.Contact, text)
+	case schemas.AlertEmail:
+		if !user.NotifyEmail {
+			return errOptedOut
+		}
+		return SendEmail(user.Email, product.Name+" voltou ao estoque", text)
+	}
+	return fmt.Errorf("canal desconhecido: %s", channel)
+}
+
+func stockAlertText(user schemas.Users, product schemas.Products) string {
+	name := product.Name
+	var variant []string
+	if product.Size != "" {
+		variant = append(variant, "tamanho "+product.Size)
+	}
+	if product.Color != "" {
+		variant = append(variant, product.Color)
+	}
+	if len(variant) > 0 {
+		name += " (" + strings.Join(variant, ", ") + ")"
+	}
+
+	greeting := "Olá!"
+	if first := strings.Fields(user.Name); len(first) > 0 {
+		greeting = "Olá, " + first[0] + "!"
+	}
+	text := fmt.Sprintf("%s %s voltou ao estoque.", greeting, name)
+	if product.Slug != nil {
+		if url := catalog.StoreURL(catalog.ProductPath, *product.Slug); url != "" {
+			text += " Confira: " + url
+		}
+	}
+	return text
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
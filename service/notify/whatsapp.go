@@ -0,0 +1,110 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"backend_camisaria_store/schemas"
+	whatsapp "backend_camisaria_store/service/whatsapp/config"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotConfigured indica que o canal não tem as variáveis de ambiente necessárias.
+var ErrNotConfigured = errors.New("canal de notificação não configurado")
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// SendWhatsApp envia uma mensagem de texto pela instância da Evolution API
+// (WHATSAPP_INSTANCE ou a instância ativa "default").
+func SendWhatsApp(db *gorm.DB, phone, text string) error {
+	baseURL, apiKey := whatsapp.GetBaseURLAndAPIKey()
+	if baseURL == "" || apiKey == "" {
+		return ErrNotConfigured
+	}
+	number := NormalizePhone(phone)
+	if number == "" {
+		return fmt.Errorf("telefone inválido: %q", phone)
+	}
+	instance, err := instanceName(db)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]string{"number": number, "text": text})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, baseURL+"/message/sendText/"+instance, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	whatsapp.SetAuthHeaders(req, apiKey)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("evolution api: status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// whatsAppConfigured indica se a Evolution API e a instância de envio estão configuradas.
+func whatsAppConfigured(db *gorm.DB) (bool, error) {
+	baseURL, apiKey := whatsapp.GetBaseURLAndAPIKey()
+	if baseURL == "" || apiKey == "" {
+		return false, nil
+	}
+	if _, err := instanceName(db); err != nil {
+		if errors.Is(err, ErrNotConfigured) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// NormalizePhone mantém só os dígitos e inclui o DDI 55 em números nacionais.
+func NormalizePhone(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	digits := strings.TrimLeft(b.String(), "0")
+	switch {
+	case len(digits) == 10 || len(digits) == 11:
+		return "55" + digits
+	case len(digits) == 12 || len(digits) == 13:
+		return digits
+	}
+	return ""
+}
+
+func instanceName(db *gorm.DB) (string, error) {
+	if name := strings.TrimSpace(os.Getenv("WHATSAPP_INSTANCE")); name != "" {
+		return name, nil
+	}
+	var instance schemas.Instance
+	if err := db.Where("name = ? AND status = ?", "default", "active").First(&instance).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrNotConfigured
+		}
+		return "", err
+	}
+	return instance.Name, nil
+}
@@ -0,0 +1,134 @@
+package scheduler
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"backend_camisaria_store/service/catalog"
+	"backend_camisaria_store/service/minio"
+	"backend_camisaria_store/service/notify"
+	"backend_camisaria_store/service/session"
+
+	"gorm.io/gorm"
+)
+
+// Job é uma tarefa periódica executada em background.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(db *gorm.DB) error
+}
+
+// Jobs registrados na inicialização da API.
+func defaultJobs() []Job {
+	return []Job{
+		{Name: "product-schedules", Interval: time.Minute, Run: applyProductSchedules},
+		{Name: "stock-alerts", Interval: 5 * time.Minute, Run: sendStockAlerts},
+		{Name: "bought-together", Interval: 6 * time.Hour, Run: recomputeBoughtTogether},
+		{Name: "pending-uploads", Interval: 15 * time.Minute, Run: cleanupPendingUploads},
+		{Name: "storage-gc", Interval: 24 * time.Hour, Run: collectOrphanObjects},
+		{Name: "refresh-tokens", Interval: 6 * time.Hour, Run: cleanupRefreshTokens},
+	}
+}
+
+// Start dispara cada job em sua própria goroutine; falhas são logadas e o job segue no próximo ciclo.
+func Start(db *gorm.DB) {
+	for _, job := range defaultJobs() {
+		go run(db, job)
+	}
+	log.Println("Scheduler initialized")
+}
+
+func run(db *gorm.DB, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		execute(db, job)
+		<-ticker.C
+	}
+}
+
+func execute(db *gorm.DB, job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("job %s: panic: %v", job.Name, r)
+		}
+	}()
+	if err := job.Run(db); err != nil {
+		log.Printf("job %s: %v", job.Name, err)
+	}
+}
+
+func applyProductSchedules(db *gorm.DB) error {
+	result, err := catalog.ApplySchedules(db, time.Now())
+	if err != nil {
+		return err
+	}
+	if result != (catalog.ScheduleResult{}) {
+		log.Printf("agendamentos aplicados: %d publicado(s), %d despublicado(s), %d promoção(ões) iniciada(s), %d encerrada(s)",
+			result.Published, result.Unpublished, result.PromotionStarted, result.PromotionEnded)
+	}
+	return nil
+}
+
+func sendStockAlerts(db *gorm.DB) error {
+	result, err := notify.ProcessStockAlerts(db)
+	if err != nil {
+		return err
+	}
+	if result != (notify.StockAlertResult{}) {
+		log.Printf("avisos de estoque: %d enviado(s), %d falha(s) definitiva(s), %d para nova tentativa",
+			result.Notified, result.Failed, result.Retrying)
+	}
+	return nil
+}
+
+func recomputeBoughtTogether(db *gorm.DB) error {
+	count, err := catalog.RecomputeBoughtTogether(db, time.Now())
+	if err != nil {
+		return err
+	}
+	log.Printf("comprados juntos recalculados: %d relação(ões)", count)
+	return nil
+}
+
+func cleanupPendingUploads(db *gorm.DB) error {
+	removed, err := minio.CleanupPendingUploads(db)
+	if err != nil {
+		return err
+	}
+	if removed > 0 {
+		log.Printf("envios diretos expirados: %d removido(s)", removed)
+	}
+	return nil
+}
+
+// collectOrphanObjects só relata os órfãos; apaga com STORAGE_GC_DELETE=true.
+func collectOrphanObjects(db *gorm.DB) error {
+	dryRun := os.Getenv("STORAGE_GC_DELETE") != "true"
+	report, err := minio.ReconcileProductObjects(db, minio.ReconcileOptions{DryRun: dryRun})
+	if err != nil {
+		return err
+	}
+	if report.Aborted {
+		log.Printf("armazenamento: remoção cancelada, %d referência(s) sem key reconhecível", report.UnmappedCount)
+	}
+	if report.OrphanCount > 0 || report.MissingCount > 0 {
+		log.Printf("armazenamento: %d órfão(s), %d apagado(s) (%d bytes), %d imagem(ns) referenciada(s) ausente(s)",
+			report.OrphanCount, report.DeletedCount, report.BytesReclaimed, report.MissingCount)
+	}
+	return nil
+}
+
+func cleanupRefreshTokens(db *gorm.DB) error {
+	removed, err := session.CleanupExpired(db, time.Now())
+	if err != nil {
+		return err
+	}
+	if removed > 0 {
+		log.Printf("refresh tokens expirados: %d removido(s)", removed)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	"backend_camisaria_store/schemas"
+
+	"gorm.io/gorm"
+)
+
+// ProductDocument monta o texto indexado (coluna search_text) de um produto.
+// Cada termo entra na forma original sem acento e na forma reduzida pelo Stem;
+// o nome é repetido para pesar mais na relevância do MATCH ... AGAINST.
+func ProductDocument(p schemas.Products) string {
+	fields := []string{
+		p.Name,
+		p.Name,
+		p.SKU,
+		string(p.Categorys),
+		p.Color,
+		p.Material,
+		p.Tags,
+		p.SEOKeywords,
+		p.Description,
+	}
+
+	var b strings.Builder
+	for _, field := range fields {
+		for _, tok := range Tokenize(field) {
+			b.WriteString(tok)
+			b.WriteByte(' ')
+			if stem := Stem(tok); stem != tok {
+				b.WriteString(stem)
+				b.WriteByte(' ')
+			}
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// BooleanQuery converte termos em uma expressão do MySQL em BOOLEAN MODE.
+// Com requireAll todos os termos são obrigatórios (+termo*); sem ele qualquer
+// termo pontua e a ordenação fica por conta da relevância. As exceções do Stem
+// entram como termo exato: "pai*" casaria com "paisagem" e "paixao".
+func BooleanQuery(terms []string, requireAll bool) string {
+	parts := make([]string, 0, len(terms))
+	for _, t := range terms {
+		expr := Stem(t)
+		if _, exact := stemExceptions[t]; !exact {
+			expr += "*"
+		}
+		if requireAll {
+			expr = "+" + expr
+		}
+		parts = append(parts, expr)
+	}
+	return strings.Join(parts, " ")
+}
+
+// ReindexProducts recalcula search_text dos produtos que ainda não foram indexados.
+func ReindexProducts(db *gorm.DB) error {
+	var products []schemas.Products
+	err := db.Where("search_text IS NULL OR search_text = ''").
+		FindInBatches(&products, 200, func(_ *gorm.DB, _ int) error {
+			for _, p := range products {
+				if err := db.Model(&schemas.Products{}).Where("id = ?", p.ID).
+					UpdateColumn("search_text", ProductDocument(p)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return fmt.Errorf("erro ao reindexar produtos: %w", err)
+	}
+	return nil
+}
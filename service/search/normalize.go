@@ -0,0 +1,98 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// minTokenLen acompanha o innodb_ft_min_token_size padrão do MySQL (3).
+const minTokenLen = 3
+
+// stopwords do português + stopwords padrão do InnoDB que colidem com termos comuns.
+var stopwords = map[string]bool{
+	"de": true, "da": true, "do": true, "das": true, "dos": true,
+	"e": true, "o": true, "a": true, "os": true, "as": true,
+	"um": true, "uma": true, "uns": true, "umas": true,
+	"com": true, "sem": true, "para": true, "pra": true, "por": true,
+	"em": true, "no": true, "na": true, "nos": true, "nas": true,
+	"que": true, "se": true, "ao": true, "aos": true, "mais": true,
+	"the": true, "and": true, "for": true, "with": true, "www": true,
+}
+
+// Fold remove acentos e converte para minúsculas ("Calção" → "calcao").
+func Fold(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+	return strings.ToLower(folded)
+}
+
+// Tokenize normaliza o texto e quebra em palavras alfanuméricas.
+func Tokenize(s string) []string {
+	return strings.FieldsFunc(Fold(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// stemExceptions são palavras que as regras de plural de Stem estragariam
+// ("pais" viraria "pal" e casaria com "palmeiras"; "mais" viraria "mal").
+var stemExceptions = map[string]string{
+	"pais": "pai", "mais": "mais", "demais": "demais", "jamais": "jamais", "cais": "cais",
+	"maes": "mae", "reis": "rei", "seis": "seis", "leis": "lei",
+	"bois": "boi", "dois": "dois", "herois": "heroi", "depois": "depois",
+	"tres": "tres", "simples": "simples", "lapis": "lapis", "tenis": "tenis",
+}
+
+// Stem aplica um stemmer leve de português focado em plurais
+// ("camisas" → "camisa", "sociais" → "social", "botões" → "botao").
+// Recebe o token já normalizado por Fold.
+func Stem(token string) string {
+	if stem, ok := stemExceptions[token]; ok {
+		return stem
+	}
+	if len(token) <= 3 {
+		return token
+	}
+
+	rules := []struct{ suffix, replacement string }{
+		{"oes", "ao"},
+		{"aes", "ao"},
+		{"ais", "al"},
+		{"eis", "el"},
+		{"ois", "ol"},
+		{"res", "r"},
+		{"zes", "z"},
+		{"ns", "m"},
+	}
+	for _, r := range rules {
+		if strings.HasSuffix(token, r.suffix) {
+			return strings.TrimSuffix(token, r.suffix) + r.replacement
+		}
+	}
+
+	if strings.HasSuffix(token, "s") &&
+		!strings.HasSuffix(token, "ss") &&
+		!strings.HasSuffix(token, "us") &&
+		!strings.HasSuffix(token, "is") {
+		return strings.TrimSuffix(token, "s")
+	}
+	return token
+}
+
+// Terms devolve os termos pesquisáveis (sem stopwords e tokens curtos demais para o índice).
+func Terms(s string) []string {
+	var terms []string
+	for _, tok := range Tokenize(s) {
+		if len(tok) < minTokenLen || stopwords[tok] {
+			continue
+		}
+		terms = append(terms, tok)
+	}
+	return terms
+}
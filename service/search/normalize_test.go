@@ -0,0 +1,107 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Calção", "calcao"},
+		{"CAMISA SOCIAL", "camisa social"},
+		{"Botões de Pressão", "botoes de pressao"},
+		{"Açaí", "acai"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Fold(tt.in); got != tt.want {
+			t.Errorf("Fold(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Camisa Social - Algodão", []string{"camisa", "social", "algodao"}},
+		{"Polo/Piquet 100%", []string{"polo", "piquet", "100"}},
+		{"  ", []string{}},
+	}
+	for _, tt := range tests {
+		if got := Tokenize(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"camisas", "camisa"},
+		{"sociais", "social"},
+		{"botoes", "botao"},
+		{"paes", "pao"},
+		{"papeis", "papel"},
+		{"lencois", "lencol"},
+		{"flores", "flor"},
+		{"cruzes", "cruz"},
+		{"bordados", "bordado"},
+		{"jardins", "jardim"},
+		{"classes", "classe"},
+		{"xadrez", "xadrez"},
+		{"linho", "linho"},
+		{"gris", "gris"},
+		{"onus", "onus"},
+		{"gola", "gola"},
+		// exceções: "Dia dos Pais" não pode virar "pal" (casaria com "palmeiras")
+		{"pais", "pai"},
+		{"mais", "mais"},
+		{"maes", "mae"},
+		{"dois", "dois"},
+		{"reis", "rei"},
+		{"tres", "tres"},
+		{"tenis", "tenis"},
+	}
+	for _, tt := range tests {
+		if got := Stem(tt.in); got != tt.want {
+			t.Errorf("Stem(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Camisa de Linho para o Dia dos Pais", []string{"camisa", "linho", "dia", "pais"}},
+		{"P GG", nil},
+	}
+	for _, tt := range tests {
+		if got := Terms(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Terms(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBooleanQuery(t *testing.T) {
+	tests := []struct {
+		terms      []string
+		requireAll bool
+		want       string
+	}{
+		{[]string{"camisas", "pais"}, true, "+camisa* +pai"},
+		{[]string{"camisas", "pais"}, false, "camisa* pai"},
+		{[]string{"tenis", "sociais"}, true, "+tenis +social*"},
+	}
+	for _, tt := range tests {
+		if got := BooleanQuery(tt.terms, tt.requireAll); got != tt.want {
+			t.Errorf("BooleanQuery(%v, %v) = %q, want %q", tt.terms, tt.requireAll, got, tt.want)
+		}
+	}
+}
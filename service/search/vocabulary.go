@@ -0,0 +1,168 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"backend_camisaria_store/schemas"
+
+	"gorm.io/gorm"
+)
+
+// vocabularyTTL define por quanto tempo o vocabulário em memória é reaproveitado.
+const vocabularyTTL = 10 * time.Minute
+
+// vocabulary guarda as palavras do catálogo publicado (sem acento) com sua frequência,
+// usado para correção de digitação e sugestões de autocomplete.
+type vocabulary struct {
+	mu       sync.RWMutex
+	words    map[string]int
+	loadedAt time.Time
+}
+
+var vocab = &vocabulary{}
+
+func (v *vocabulary) get(db *gorm.DB) (map[string]int, error) {
+	v.mu.RLock()
+	if v.words != nil && time.Since(v.loadedAt) < vocabularyTTL {
+		words := v.words
+		v.mu.RUnlock()
+		return words, nil
+	}
+	v.mu.RUnlock()
+
+	var products []schemas.Products
+	if err := db.Select("name", "tags", "seo_keywords", "color", "material").
+		Where("status = ? AND is_active = ?", schemas.ProductStatusPublished, true).
+		Find(&products).Error; err != nil {
+		return nil, err
+	}
+
+	words := make(map[string]int)
+	for _, p := range products {
+		for _, field := range []string{p.Name, p.Tags, p.SEOKeywords, p.Color, p.Material} {
+			for _, t := range Terms(field) {
+				words[t]++
+			}
+		}
+	}
+
+	v.mu.Lock()
+	v.words = words
+	v.loadedAt = time.Now()
+	v.mu.Unlock()
+	return words, nil
+}
+
+// InvalidateVocabulary força a recarga do vocabulário na próxima busca.
+func InvalidateVocabulary() {
+	vocab.mu.Lock()
+	vocab.words = nil
+	vocab.mu.Unlock()
+}
+
+// Correct substitui termos desconhecidos pela palavra mais próxima do vocabulário
+// (distância de Levenshtein até 1 para palavras curtas e até 2 para as demais).
+// Retorna os termos corrigidos e se houve alguma troca.
+func Correct(db *gorm.DB, terms []string) ([]string, bool, error) {
+	words, err := vocab.get(db)
+	if err != nil {
+		return terms, false, err
+	}
+
+	corrected := make([]string, len(terms))
+	changed := false
+	for i, term := range terms {
+		corrected[i] = term
+		if _, ok := words[term]; ok {
+			continue
+		}
+
+		maxDist := 1
+		if len(term) > 5 {
+			maxDist = 2
+		}
+
+		best, bestDist, bestFreq := "", maxDist+1, 0
+		for w, freq := range words {
+			if abs(len(w)-len(term)) > maxDist {
+				continue
+			}
+			d := levenshtein(term, w)
+			if d < bestDist || (d == bestDist && freq > bestFreq) {
+				best, bestDist, bestFreq = w, d, freq
+			}
+		}
+		if best != "" {
+			corrected[i] = best
+			changed = true
+		}
+	}
+	return corrected, changed, nil
+}
+
+// CompleteTerm lista palavras do vocabulário que começam com o prefixo informado.
+func CompleteTerm(db *gorm.DB, prefix string, limit int) ([]string, error) {
+	prefix = Fold(strings.TrimSpace(prefix))
+	if prefix == "" {
+		return []string{}, nil
+	}
+
+	words, err := vocab.get(db)
+	if err != nil {
+		return nil, err
+	}
+
+	type candidate struct {
+		word string
+		freq int
+	}
+	var candidates []candidate
+	for w, freq := range words {
+		if strings.HasPrefix(w, prefix) {
+			candidates = append(candidates, candidate{w, freq})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].freq != candidates[j].freq {
+			return candidates[i].freq > candidates[j].freq
+		}
+		return candidates[i].word < candidates[j].word
+	})
+
+	result := make([]string, 0, limit)
+	for i := 0; i < len(candidates) && i < limit; i++ {
+		result = append(result, candidates[i].word)
+	}
+	return result, nil
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
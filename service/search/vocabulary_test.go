@@ -0,0 +1,23 @@
+package search
+
+import "testing"
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"camisa", "camisa", 0},
+		{"camsia", "camisa", 2},
+		{"camiza", "camisa", 1},
+		{"linh", "linho", 1},
+		{"", "polo", 4},
+		{"polo", "", 4},
+		{"algodão", "algodao", 1}, // compara runas, não bytes
+	}
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
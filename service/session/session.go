@@ -0,0 +1,263 @@
+// Package session emite os tokens de acesso (JWT HS256 de curta duração) e os refresh
+// tokens rotativos guardados em schemas.RefreshTokens.
+package session
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"time"
+
+	"backend_camisaria_store/common"
+	"backend_camisaria_store/schemas"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRefresh = errors.New("refresh token inválido ou expirado")
+	ErrRefreshReused  = errors.New("refresh token já utilizado; sessões deste login foram encerradas")
+)
+
+const (
+	defaultAccessTTL  = 15 * time.Minute
+	defaultRefreshTTL = 30 * 24 * time.Hour
+)
+
+// Tokens é o par entregue no login e a cada renovação.
+type Tokens struct {
+	AccessToken      string    `json:"access_token"`
+	RefreshToken     string    `json:"refresh_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresIn        int64     `json:"expires_in"` // segundos de validade do access token
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// ClientInfo identifica o dispositivo do login (exibição e auditoria).
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// AccessTTL é a validade do access token (ACCESS_TOKEN_TTL, ex.: 15m).
+func AccessTTL() time.Duration {
+	return envDuration("ACCESS_TOKEN_TTL", defaultAccessTTL)
+}
+
+// RefreshTTL é a validade de cada refresh token (REFRESH_TOKEN_TTL, ex.: 720h).
+func RefreshTTL() time.Duration {
+	return envDuration("REFRESH_TOKEN_TTL", defaultRefreshTTL)
+}
+
+// Start abre uma sessão (nova família de refresh tokens) para o usuário autenticado.
+func Start(db *gorm.DB, user *schemas.Users, info ClientInfo) (*Tokens, error) {
+	familyID, err := randomToken(16, hex.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+	var tokens *Tokens
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		tokens, _, err = issue(tx, user, familyID, info, time.Now())
+		return err
+	})
+	return tokens, err
+}
+
+// Rotate troca um refresh token válido por um novo par. Um token já trocado que volta a
+// aparecer indica vazamento: a família inteira é revogada e ErrRefreshReused é devolvido.
+func Rotate(db *gorm.DB, refreshToken string, info ClientInfo) (*Tokens, *schemas.Users, error) {
+	var current schemas.RefreshTokens
+	err := db.Where("token_hash = ?", hashToken(refreshToken)).First(&current).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrInvalidRefresh
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if current.RevokedAt != nil || !now.Before(current.ExpiresAt) {
+		return nil, nil, ErrInvalidRefresh
+	}
+	if current.UsedAt != nil {
+		return nil, nil, revokeReused(db, current.FamilyID, now)
+	}
+
+	var user schemas.Users
+	if err := db.First(&user, current.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidRefresh
+		}
+		return nil, nil, err
+	}
+
+	var tokens *Tokens
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Condicional: duas renovações simultâneas com o mesmo token contam como reuso
+		result := tx.Model(&schemas.RefreshTokens{}).
+			Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", current.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshReused
+		}
+		var next *schemas.RefreshTokens
+		var err error
+		tokens, next, err = issue(tx, &user, current.FamilyID, info, now)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&schemas.RefreshTokens{}).Where("id = ?", current.ID).
+			Update("replaced_by_id", next.ID).Error
+	})
+	if errors.Is(err, ErrRefreshReused) {
+		return nil, nil, revokeReused(db, current.FamilyID, now)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokens, &user, nil
+}
+
+// RevokeRefreshToken encerra o login (família) do refresh token, desde que seja do usuário.
+func RevokeRefreshToken(db *gorm.DB, userID uint64, refreshToken string) error {
+	var token schemas.RefreshTokens
+	err := db.Where("token_hash = ? AND user_id = ?", hashToken(refreshToken), userID).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidRefresh
+	}
+	if err != nil {
+		return err
+	}
+	return RevokeFamily(db, token.FamilyID, time.Now())
+}
+
+// RevokeFamily revoga todos os refresh tokens ainda ativos de um login.
+func RevokeFamily(db *gorm.DB, familyID string, now time.Time) error {
+	return db.Model(&schemas.RefreshTokens{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", now).Error
+}
+
+// RevokeUser encerra todas as sessões do usuário: revoga os refresh tokens e invalida os
+// access tokens já emitidos (Users.TokensRevokedAt, conferido no AuthMiddleware).
+func RevokeUser(db *gorm.DB, userID uint64, now time.Time) error {
+	// O iat do JWT tem resolução de segundos: arredonda para cima para cobrir tokens
+	// emitidos no mesmo segundo da revogação
+	revokedAt := now.Truncate(time.Second).Add(time.Second)
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&schemas.Users{}).Where("id = ?", userID).
+			Update("tokens_revoked_at", revokedAt).Error; err != nil {
+			return err
+		}
+		return tx.Model(&schemas.RefreshTokens{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", now).Error
+	})
+}
+
+// IssuedBeforeRevocation indica se um access token emitido em issuedAt (claim iat, Unix)
+// foi invalidado por RevokeUser.
+func IssuedBeforeRevocation(user *schemas.Users, issuedAt int64) bool {
+	return user.TokensRevokedAt != nil && issuedAt < user.TokensRevokedAt.Unix()
+}
+
+// FamilyRevoked indica se o login (claim sid do access token) foi encerrado: logout,
+// reuso detectado ou revogação da conta marcam revoked_at nos tokens da família.
+func FamilyRevoked(db *gorm.DB, familyID string) (bool, error) {
+	var count int64
+	err := db.Model(&schemas.RefreshTokens{}).
+		Where("family_id = ? AND revoked_at IS NOT NULL", familyID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CleanupExpired apaga refresh tokens vencidos há mais de um dia (o dia extra mantém o
+// histórico da família para detectar reuso logo após a expiração).
+func CleanupExpired(db *gorm.DB, now time.Time) (int64, error) {
+	result := db.Where("expires_at < ?", now.Add(-24*time.Hour)).Delete(&schemas.RefreshTokens{})
+	return result.RowsAffected, result.Error
+}
+
+func issue(tx *gorm.DB, user *schemas.Users, familyID string, info ClientInfo, now time.Time) (*Tokens, *schemas.RefreshTokens, error) {
+	accessTTL := AccessTTL()
+	claims := jwt.MapClaims{
+		"user_id":   user.ID,
+		"user_type": string(user.Role), // "admin", "user", or "client"
+		"name":      user.Name,
+		"email":     user.Email,
+		"role":      string(user.Role),
+		"sid":       familyID,
+		"iat":       now.Unix(),
+		"exp":       now.Add(accessTTL).Unix(),
+	}
+	access, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(common.GetJWTSecret())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refresh, err := randomToken(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, nil, err
+	}
+	record := schemas.RefreshTokens{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refresh),
+		ExpiresAt: now.Add(RefreshTTL()),
+		UserAgent: truncate(info.UserAgent, 255),
+		IP:        truncate(info.IP, 45),
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return nil, nil, err
+	}
+
+	return &Tokens{
+		AccessToken:      access,
+		RefreshToken:     refresh,
+		TokenType:        "Bearer",
+		ExpiresIn:        int64(accessTTL / time.Second),
+		RefreshExpiresAt: record.ExpiresAt,
+	}, &record, nil
+}
+
+func revokeReused(db *gorm.DB, familyID string, now time.Time) error {
+	if err := RevokeFamily(db, familyID, now); err != nil {
+		return err
+	}
+	return ErrRefreshReused
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomToken(n int, encode func([]byte) string) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encode(b), nil
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStorage mantém os objetos em memória: substitui o MinIO em testes e execuções
+// locais descartáveis. Seguro para uso concorrente.
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+	baseURL string
+}
+
+type memoryObject struct {
+	data        []byte
+	contentType string
+	modified    time.Time
+}
+
+// NewMemory cria um armazenamento vazio; baseURL vazio usa http://storage.local.
+func NewMemory(baseURL string) *MemoryStorage {
+	if baseURL == "" {
+		baseURL = "http://storage.local"
+	}
+	return &MemoryStorage{objects: make(map[string]memoryObject), baseURL: baseURL}
+}
+
+func (s *MemoryStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.objects[key] = memoryObject{data: data, contentType: contentType, modified: time.Now()}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	s.mu.RLock()
+	obj, ok := s.objects[key]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(obj.data)), nil
+}
+
+func (s *MemoryStorage) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	s.mu.RLock()
+	obj, ok := s.objects[key]
+	s.mu.RUnlock()
+	if !ok {
+		return ObjectInfo{}, ErrNotFound
+	}
+	return obj.info(key), nil
+}
+
+func (s *MemoryStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.objects, key)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStorage) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	s.mu.RLock()
+	infos := make([]ObjectInfo, 0, len(s.objects))
+	for key, obj := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			infos = append(infos, obj.info(key))
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+	for _, info := range infos {
+		if err := fn(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PresignPost devolve um formulário fictício; o teste grava o conteúdo com Put, como faria o cliente.
+func (s *MemoryStorage) PresignPost(ctx context.Context, key, contentType string, minSize, maxSize int64, expiry time.Duration) (PresignedPost, error) {
+	return PresignedPost{
+		URL: s.PublicURL(""),
+		Fields: map[string]string{
+			"key":          key,
+			"Content-Type": contentType,
+			"expires":      strconv.FormatInt(time.Now().Add(expiry).Unix(), 10),
+		},
+	}, nil
+}
+
+func (s *MemoryStorage) PublicURL(key string) string {
+	return joinURL(s.baseURL, key)
+}
+
+func (o memoryObject) info(key string) ObjectInfo {
+	return ObjectInfo{Key: key, Size: int64(len(o.data)), ContentType: o.contentType, LastModified: o.modified}
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// MinioStorage guarda os objetos em um bucket do MinIO (ou S3 compatível).
+type MinioStorage struct {
+	client     *minio.Client
+	bucket     string
+	publicBase string // ex.: http://minio:9000/bucket
+}
+
+func NewMinio(client *minio.Client, bucket, publicBase string) *MinioStorage {
+	return &MinioStorage{client: client, bucket: bucket, publicBase: publicBase}
+}
+
+func (s *MinioStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+func (s *MinioStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, mapMinioError(err)
+	}
+	// GetObject é preguiçoso: o Stat revela objeto ausente antes da leitura
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		return nil, mapMinioError(err)
+	}
+	return obj, nil
+}
+
+func (s *MinioStorage) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	info, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return ObjectInfo{}, mapMinioError(err)
+	}
+	return ObjectInfo{Key: info.Key, Size: info.Size, ContentType: info.ContentType, LastModified: info.LastModified}, nil
+}
+
+func (s *MinioStorage) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *MinioStorage) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return obj.Err
+		}
+		info := ObjectInfo{Key: obj.Key, Size: obj.Size, ContentType: obj.ContentType, LastModified: obj.LastModified}
+		if err := fn(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MinioStorage) PresignPost(ctx context.Context, key, contentType string, minSize, maxSize int64, expiry time.Duration) (PresignedPost, error) {
+	policy := minio.NewPostPolicy()
+	for _, err := range []error{
+		policy.SetBucket(s.bucket),
+		policy.SetKey(key),
+		policy.SetExpires(time.Now().UTC().Add(expiry)),
+		policy.SetContentType(contentType),
+		policy.SetContentLengthRange(minSize, maxSize),
+	} {
+		if err != nil {
+			return PresignedPost{}, err
+		}
+	}
+	u, fields, err := s.client.PresignedPostPolicy(ctx, policy)
+	if err != nil {
+		return PresignedPost{}, err
+	}
+	return PresignedPost{URL: u.String(), Fields: fields}, nil
+}
+
+func (s *MinioStorage) PublicURL(key string) string {
+	return joinURL(s.publicBase, key)
+}
+
+func mapMinioError(err error) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// WithPublicBase devolve o armazenamento com as URLs públicas sob base (CDN ou a rota
+// /public/media da API), sem expor o host e o bucket do backend.
+func WithPublicBase(s Storage, base string) Storage {
+	return &publicBaseStorage{Storage: s, base: base}
+}
+
+type publicBaseStorage struct {
+	Storage
+	base string
+}
+
+func (s *publicBaseStorage) PublicURL(key string) string {
+	return joinURL(s.base, key)
+}
+
+func (s *publicBaseStorage) Unwrap() Storage {
+	return s.Storage
+}
+
+// Unwrap devolve o backend por baixo dos decoradores (ex.: para servir o disco local).
+func Unwrap(s Storage) Storage {
+	for {
+		w, ok := s.(interface{ Unwrap() Storage })
+		if !ok {
+			return s
+		}
+		s = w.Unwrap()
+	}
+}
+
+// SignKey assina key e expiração (Unix) com HMAC-SHA256, para links temporários de
+// arquivos privados.
+func SignKey(secret []byte, key string, expires int64) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(key + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature confere a assinatura de SignKey e se o link ainda vale em now.
+func VerifySignature(secret []byte, key string, expires int64, signature string, now time.Time) bool {
+	if expires < now.Unix() {
+		return false
+	}
+	expected := SignKey(secret, key, expires)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -0,0 +1,69 @@
+// Package storage abstrai o armazenamento de objetos (imagens, artes) usado por service/minio.
+// O backend é escolhido por STORAGE_DRIVER em config.InitStorage: minio (padrão), local ou memory.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound           = errors.New("objeto não encontrado")
+	ErrPresignUnsupported = errors.New("o armazenamento configurado não emite URLs pré-assinadas")
+)
+
+// ObjectInfo descreve um objeto guardado.
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// PresignedPost é o formulário de envio direto: POST multipart para URL com os campos de
+// Fields seguidos do arquivo no campo "file".
+type PresignedPost struct {
+	URL    string            `json:"url"`
+	Fields map[string]string `json:"fields"`
+}
+
+// Storage é o contrato comum dos backends. Keys usam "/" como separador (ex.: products/camisa-1-123.jpg).
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+	// Delete não falha quando o objeto já não existe.
+	Delete(ctx context.Context, key string) error
+	// List percorre os objetos com o prefixo; um erro devolvido por fn interrompe a listagem.
+	List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error
+	// PresignPost devolve o formulário de envio direto do objeto, com o tipo e a faixa de
+	// tamanho assinados na política (o bucket recusa o que fugir deles), ou ErrPresignUnsupported.
+	PresignPost(ctx context.Context, key, contentType string, minSize, maxSize int64, expiry time.Duration) (PresignedPost, error)
+	PublicURL(key string) string
+}
+
+// KeyFromURL extrai a key de uma URL pública gerada pelo próprio backend; ok é false
+// quando a URL não pertence a ele.
+func KeyFromURL(s Storage, u string) (string, bool) {
+	base := s.PublicURL("")
+	if base == "" || !strings.HasPrefix(u, base) {
+		return "", false
+	}
+	key := strings.TrimPrefix(u, base)
+	if i := strings.IndexAny(key, "?#"); i >= 0 {
+		key = key[:i]
+	}
+	if unescaped, err := url.PathUnescape(key); err == nil {
+		key = unescaped
+	}
+	key = strings.TrimLeft(key, "/")
+	return key, key != ""
+}
+
+func joinURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(key, "/")
+}
@@ -0,0 +1,44 @@
+package storage
+
+import "testing"
+
+func TestKeyFromURL(t *testing.T) {
+	memory := NewMemory("http://storage.local/bucket")
+	cdn := WithPublicBase(memory, "https://cdn.example.com/public/media")
+
+	tests := []struct {
+		name    string
+		storage Storage
+		url     string
+		wantKey string
+		wantOK  bool
+	}{
+		{"url do backend", memory, "http://storage.local/bucket/products/abc-zoom.jpg", "products/abc-zoom.jpg", true},
+		{"query string e fragmento", memory, "http://storage.local/bucket/products/abc.jpg?v=2#x", "products/abc.jpg", true},
+		{"caracteres escapados", memory, "http://storage.local/bucket/products/camisa%20azul.jpg", "products/camisa azul.jpg", true},
+		{"base pública", cdn, "https://cdn.example.com/public/media/products/abc.webp", "products/abc.webp", true},
+		{"backend atrás da base pública", cdn, "http://storage.local/bucket/products/abc.webp", "", false},
+		{"outro host", memory, "https://outro.example.com/bucket/products/abc.jpg", "", false},
+		{"só a base", memory, "http://storage.local/bucket/", "", false},
+		{"key sem URL", memory, "products/abc.jpg", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, ok := KeyFromURL(tt.storage, tt.url)
+			if key != tt.wantKey || ok != tt.wantOK {
+				t.Errorf("KeyFromURL(%q) = (%q, %v), want (%q, %v)", tt.url, key, ok, tt.wantKey, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestUnwrap(t *testing.T) {
+	memory := NewMemory("")
+	wrapped := WithPublicBase(WithPublicBase(memory, "https://cdn.example.com"), "https://outro.example.com")
+	if got := Unwrap(wrapped); got != Storage(memory) {
+		t.Errorf("Unwrap devolveu %T, want o MemoryStorage original", got)
+	}
+	if got := Unwrap(memory); got != Storage(memory) {
+		t.Errorf("Unwrap(memory) devolveu %T, want o próprio memory", got)
+	}
+}